@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // schedules use IANA time zones even where the system has no zoneinfo
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/mdp/qrterminal/v3"
@@ -59,25 +60,101 @@ func main() {
 	defer botDB.Close()
 
 	// Initialize handlers using the bot SQLite DB.
-	bannedStickerUserStore := services.NewBannedStickerUserStore(botDB)
-	bannedImageUserStore := services.NewBannedImageUserStore(botDB)
-	bannedChatUserStore := services.NewBannedChatUserStore(botDB)
+	banStore := services.NewBanStore(botDB)
+	antiLinkStore := services.NewAntiLinkStore(botDB)
+	warningStore := services.NewWarningStore(botDB)
+	wordFilterStore := services.NewWordFilterStore(botDB)
+	antiDeleteStore := services.NewAntiDeleteStore(botDB)
+	mediaBlocklist := services.NewMediaBlocklist(botDB)
+	blacklist := services.NewBlacklist(botDB)
+	mentionStore := services.NewMentionStore(botDB, services.MentionSettings{
+		MaxMentions: config.AntiMentionMax,
+		MaxPercent:  config.AntiMentionPercent,
+	})
+	forwardStore := services.NewForwardStore(botDB, config.AntiForwardScore)
+	slowModeStore := services.NewSlowModeStore(botDB)
+	imageFilterStore := services.NewImageFilterStore(botDB, config.ImageBlockDistance)
+	scheduleStore := services.NewScheduleStore(botDB)
+	activityStore := services.NewActivityStore(botDB)
+	captchaStore := services.NewCaptchaStore(botDB, time.Duration(config.CaptchaTimeoutMin)*time.Minute)
+	messageCache := services.NewMessageCache(
+		config.AntiDeleteCacheSize,
+		int64(config.AntiDeleteCacheMB)*1024*1024,
+		time.Duration(config.AntiDeleteTTLMin)*time.Minute,
+	)
+	floodStore := services.NewFloodStore(botDB, services.FloodSettings{
+		MaxMessages:  config.FloodMaxMessages,
+		Window:       time.Duration(config.FloodWindowSec) * time.Second,
+		MuteDuration: time.Duration(config.FloodMuteSec) * time.Second,
+	})
+	quotaStore := services.NewQuotaStore(botDB, map[services.QuotaMetric]services.QuotaLimits{
+		services.QuotaDownloads: {
+			PerUser:  int64(config.QuotaUserDownloads),
+			PerGroup: int64(config.QuotaGroupDownloads),
+		},
+		services.QuotaDownloadBytes: {
+			PerUser:  int64(config.QuotaUserDownloadMB) * 1024 * 1024,
+			PerGroup: int64(config.QuotaGroupDownloadMB) * 1024 * 1024,
+		},
+		services.QuotaMedia: {
+			PerUser:  int64(config.QuotaUserMedia),
+			PerGroup: int64(config.QuotaGroupMedia),
+		},
+	})
+
+	// Key bans and warnings by phone number, using the LID mappings whatsmeow has learned.
+	identity := services.NewIdentity(deviceStore.LIDs)
+	banStore.MigrateLIDKeys(identity)
+	warningStore.MigrateLIDKeys(identity)
 
 	pool := services.NewWorkerPool(config.MaxConcurrentMediaTasks)
 
-	mediaHandler := handlers.NewMediaHandler(pool)
-	dlHandler := handlers.NewDownloaderHandler(pool)
-	groupHandler := handlers.NewGroupHandler()
+	mediaHandler := handlers.NewMediaHandler(pool, quotaStore)
+	dlHandler := handlers.NewDownloaderHandler(pool, quotaStore)
+	groupHandler := handlers.NewGroupHandler(services.NewGroupCache(time.Duration(config.GroupCacheTTLSec)*time.Second), identity)
+	quotaHandler := handlers.NewQuotaHandler(quotaStore, groupHandler)
 	menuHandler := handlers.NewMenuHandler()
-	antiStickerHandler := handlers.NewAntiStickerHandler(bannedStickerUserStore, groupHandler)
-	antiImageHandler := handlers.NewAntiImageHandler(bannedImageUserStore, groupHandler)
-	antiChatHandler := handlers.NewAntiChatHandler(bannedChatUserStore, groupHandler)
+	banHandler := handlers.NewBanHandler(banStore, groupHandler)
+	warnHandler := handlers.NewWarnHandler(warningStore, banStore, groupHandler, handlers.WarnPolicy{
+		MuteAt:       config.WarnMuteAt,
+		MuteDuration: time.Duration(config.WarnMuteSec) * time.Second,
+		KickAt:       config.WarnKickAt,
+	})
+	floodHandler := handlers.NewFloodHandler(floodStore, banStore, groupHandler, warnHandler)
+	wordFilterHandler := handlers.NewWordFilterHandler(wordFilterStore, groupHandler, warnHandler)
+	antiMentionHandler := handlers.NewAntiMentionHandler(mentionStore, groupHandler, warnHandler)
+	antiForwardHandler := handlers.NewAntiForwardHandler(forwardStore, groupHandler, warnHandler)
+	slowModeHandler := handlers.NewSlowModeHandler(slowModeStore, groupHandler)
+	stickerBlockHandler := handlers.NewStickerBlockHandler(mediaBlocklist, groupHandler)
+	if _, err := time.LoadLocation(config.ScheduleTimeZone); err != nil {
+		slog.Warn("Invalid SCHEDULE_TIMEZONE, falling back to UTC", "timezone", config.ScheduleTimeZone, "error", err)
+		config.ScheduleTimeZone = "UTC"
+	}
+	groupModeHandler := handlers.NewGroupModeHandler(scheduleStore, groupHandler, config.ScheduleTimeZone)
+	activityHandler := handlers.NewActivityHandler(activityStore, groupHandler)
+	captchaHandler := handlers.NewCaptchaHandler(captchaStore, groupHandler)
+	blacklistHandler := handlers.NewBlacklistHandler(blacklist, groupHandler)
+	groupHandler.OnJoin(blacklistHandler.OnJoin)
+	groupHandler.OnJoin(activityHandler.OnJoin)
+	groupHandler.OnJoin(captchaHandler.OnJoin)
+	imageBlockHandler := handlers.NewImageBlockHandler(imageFilterStore, mediaBlocklist, groupHandler, pool)
+	antiDeleteHandler := handlers.NewAntiDeleteHandler(antiDeleteStore, messageCache, groupHandler, uint64(config.AntiDeleteMaxMediaKB)*1024)
 
 	limiter := ratelimit.New(
+		config.RateLimitUserBurst,
 		time.Duration(config.RateLimitUserCooldownSec)*time.Second,
 		config.RateLimitChatMax,
 		time.Duration(config.RateLimitChatWindowSec)*time.Second,
 	)
+	limiter.SetMultiplier(ratelimit.RoleOwner, config.RateLimitOwnerMultiplier)
+	limiter.SetMultiplier(ratelimit.RoleException, config.RateLimitExceptionMultiplier)
+	limiter.SetMultiplier(ratelimit.RoleGroupAdmin, config.RateLimitAdminMultiplier)
+	limiter.SetPenalty(
+		config.RateLimitPenaltyThreshold,
+		time.Duration(config.RateLimitPenaltyWindowSec)*time.Second,
+		time.Duration(config.RateLimitPenaltySec)*time.Second,
+	)
+	penaltyHandler := handlers.NewPenaltyHandler(limiter, groupHandler)
 
 	// Helper to wrap handlers that don't take args
 	wrap := func(h func(*whatsmeow.Client, *events.Message)) handlers.CommandHandler {
@@ -88,27 +165,83 @@ func main() {
 
 	// Initialize Registry
 	registry := handlers.NewRegistry()
-	registry.Register("s", wrap(mediaHandler.HandleSticker))
-	registry.Register("sticker", wrap(mediaHandler.HandleSticker))
-	registry.Register("toimg", wrap(mediaHandler.HandleImage))
-	registry.Register("brat", mediaHandler.HandleBrat)
+	registry.RegisterWithCost("s", 2, wrap(mediaHandler.HandleSticker))
+	registry.RegisterWithCost("sticker", 2, wrap(mediaHandler.HandleSticker))
+	registry.RegisterWithCost("toimg", 2, wrap(mediaHandler.HandleImage))
+	registry.RegisterWithCost("brat", 2, mediaHandler.HandleBrat)
 
-	registry.Register("dl", dlHandler.HandleVideo)
-	registry.Register("mp3", dlHandler.HandleAudio)
+	registry.RegisterWithCost("dl", 5, dlHandler.HandleVideo)
+	registry.RegisterWithCost("mp3", 4, dlHandler.HandleAudio)
+	registry.Register("quota", quotaHandler.HandleQuota)
 
 	registry.Register("tagall", wrap(groupHandler.HandleTagAll))
 	registry.Register("kick", groupHandler.HandleKick)
+	registry.Register("promote", groupHandler.HandlePromote)
+	registry.Register("demote", groupHandler.HandleDemote)
+	registry.Register("add", groupHandler.HandleAdd)
+	registry.Register("penalty", penaltyHandler.HandlePenalty)
+	registry.Register("close", groupModeHandler.HandleClose)
+	registry.Register("open", groupModeHandler.HandleOpen)
+	registry.Register("schedule", groupModeHandler.HandleSchedule)
+	registry.Register("active", activityHandler.HandleActive)
+	registry.Register("inactive", activityHandler.HandleInactive)
+	registry.Register("kickinactive", activityHandler.HandleKickInactive)
+	registry.Register("blacklist", blacklistHandler.HandleBlacklist)
+	registry.Register("sweep", blacklistHandler.HandleSweep)
+	registry.Register("warn", warnHandler.HandleWarn)
+	registry.Register("warnings", warnHandler.HandleWarnings)
+	registry.Register("resetwarn", warnHandler.HandleResetWarn)
+
+	// Ban types are checked in this order; each registers .ban<cmd> and .unban<cmd>.
+	banHandler.Register(registry, handlers.ChatBanType)
+	banHandler.Register(registry, handlers.StickerBanType)
+	banHandler.Register(registry, handlers.ImageBanType)
+	banHandler.Register(registry, handlers.AudioBanType)
+	banHandler.Register(registry, handlers.DocumentBanType(config.BanDocumentTypes))
+	banHandler.Register(registry, handlers.ContactBanType)
+	banHandler.Register(registry, handlers.LocationBanType)
+	banHandler.Register(registry, handlers.PollBanType)
+	registry.Register("banlist", banHandler.HandleBanList)
+
+	registry.Register("blocksticker", stickerBlockHandler.HandleBlockSticker)
+	registry.Register("unblocksticker", stickerBlockHandler.HandleUnblockSticker)
+	registry.Register("blockedstickers", stickerBlockHandler.HandleBlockedStickers)
+	registry.Register("imgfilter", imageBlockHandler.HandleImageFilter)
+	registry.Register("blockimg", imageBlockHandler.HandleBlockImage)
+	registry.Register("unblockimg", imageBlockHandler.HandleUnblockImage)
+	registry.Register("blockedimgs", imageBlockHandler.HandleBlockedImages)
+
+	antiLinkHandler := handlers.NewAntiLinkHandler(antiLinkStore, groupHandler, warnHandler, []string{"dl", "mp3"})
+	registry.Register("antilink", antiLinkHandler.HandleAntiLink)
+	registry.Register("flood", floodHandler.HandleFlood)
+	registry.Register("filter", wordFilterHandler.HandleFilter)
+	registry.Register("antimention", antiMentionHandler.HandleAntiMention)
+	registry.Register("antiforward", antiForwardHandler.HandleAntiForward)
+	registry.Register("slowmode", slowModeHandler.HandleSlowMode)
+	registry.Register("antidelete", antiDeleteHandler.HandleAntiDelete)
+	registry.Register("captcha", captchaHandler.HandleCaptcha)
 
-	registry.Register("bansticker", antiStickerHandler.HandleBanStickerUser)
-	registry.Register("unbansticker", antiStickerHandler.HandleUnbanStickerUser)
-
-	registry.Register("banimg", antiImageHandler.HandleBanImageUser)
-	registry.Register("unbanimg", antiImageHandler.HandleUnbanImageUser)
+	registry.Register("menu", wrap(menuHandler.HandleMenu))
 
-	registry.Register("banchat", antiChatHandler.HandleBanChatUser)
-	registry.Register("unbanchat", antiChatHandler.HandleUnbanChatUser)
+	// Moderation chain: checked in order for every message, the first one to revoke stops processing.
+	moderators := []moderator{
+		captchaHandler.CheckAndRevoke,
+		banHandler.CheckAndRevoke,
+		floodHandler.CheckAndRevoke,
+		stickerBlockHandler.CheckAndRevoke,
+		imageBlockHandler.CheckAndRevoke,
+		antiLinkHandler.CheckAndRevoke,
+		wordFilterHandler.CheckAndRevoke,
+		antiMentionHandler.CheckAndRevoke,
+		antiForwardHandler.CheckAndRevoke,
+		slowModeHandler.CheckAndRevoke,
+	}
 
-	registry.Register("menu", wrap(menuHandler.HandleMenu))
+	// Observers see every message that passed moderation, including protocol messages.
+	observers := []observer{
+		antiDeleteHandler.Observe,
+		activityHandler.Observe,
+	}
 
 	// Register the main event handler.
 	client.AddEventHandler(func(rawEvt interface{}) {
@@ -122,11 +255,12 @@ func main() {
 						slog.Error("PANIC RECOVERED", "panic", r)
 					}
 				}()
-				handleMessage(client, evt, registry, antiStickerHandler, antiImageHandler, antiChatHandler, limiter)
+				handleMessage(client, evt, registry, groupHandler, moderators, observers, limiter)
 			}()
 
 		case *events.GroupInfo:
-			// Handle group join/leave events in a goroutine.
+			// Patch the group cache in event order, then handle join/leave events in a goroutine.
+			groupHandler.UpdateGroupCache(client, evt)
 			go func() {
 				defer func() {
 					if r := recover(); r != nil {
@@ -136,6 +270,9 @@ func main() {
 				groupHandler.HandleGroupParticipants(client, evt)
 			}()
 
+		case *events.JoinedGroup:
+			groupHandler.HandleJoinedGroup(evt)
+
 		case *events.Connected:
 			slog.Info("Bot connected successfully!")
 
@@ -154,6 +291,28 @@ func main() {
 	// Start temporary files auto-cleaner (hourly scan, delete files older than 1 hour)
 	services.StartTempCleaner(ctx, 1*time.Hour, 1*time.Hour)
 
+	// Start temporary ban sweeper (every minute, optionally announcing lifted bans)
+	var onBanExpire func(services.Ban)
+	if config.BanExpiryNotify {
+		onBanExpire = func(ban services.Ban) {
+			banHandler.NotifyExpired(client, ban)
+		}
+	}
+	banStore.StartExpirySweeper(ctx, time.Minute, onBanExpire)
+
+	// Write buffered member activity to the database every 30 seconds
+	activityStore.StartFlusher(ctx, 30*time.Second)
+
+	// Open and close groups on their night mode schedules
+	scheduleStore.StartScheduler(ctx, time.Minute, func(sched services.GroupSchedule, action services.ScheduleAction) {
+		groupModeHandler.Apply(client, sched, action)
+	})
+
+	// Kick new members who did not answer their captcha in time
+	captchaStore.StartExpirySweeper(ctx, 15*time.Second, func(challenge services.CaptchaChallenge) {
+		captchaHandler.Expire(client, challenge)
+	})
+
 	// Connect to WhatsApp.
 	if client.Store.ID == nil {
 		// No session found, generate QR code for login.
@@ -194,31 +353,35 @@ func main() {
 
 	slog.Info("🛑 Shutting down gracefully...")
 	client.Disconnect()
+	activityStore.Flush()
 	slog.Info("👋 Bot stopped. Goodbye!")
 }
 
+// moderator inspects a message and returns true if it was revoked.
+type moderator func(client *whatsmeow.Client, evt *events.Message) bool
+
+// observer inspects a message without affecting its processing.
+type observer func(client *whatsmeow.Client, evt *events.Message)
+
 // handleMessage parses and routes incoming messages to the appropriate handler.
 func handleMessage(
 	client *whatsmeow.Client,
 	evt *events.Message,
 	registry *handlers.Registry,
-	antiStickerHandler *handlers.AntiStickerHandler,
-	antiImageHandler *handlers.AntiImageHandler,
-	antiChatHandler *handlers.AntiChatHandler,
+	groupHandler *handlers.GroupHandler,
+	moderators []moderator,
+	observers []observer,
 	limiter *ratelimit.Limiter,
 ) {
-	// Anti-chat check: revoke ANY message from banned users BEFORE anything else.
-	if antiChatHandler.CheckAndRevoke(client, evt) {
-		return // Message was revoked, no further processing needed.
-	}
-	// Anti-sticker check: revoke stickers from banned users BEFORE anything else.
-	if antiStickerHandler.CheckAndRevoke(client, evt) {
-		return // Message was revoked, no further processing needed.
+	// Moderation: revoke messages from banned users, link spam, ... BEFORE anything else.
+	for _, check := range moderators {
+		if check(client, evt) {
+			return // Message was revoked, no further processing needed.
+		}
 	}
 
-	// Anti-image check: revoke images from banned users BEFORE anything else.
-	if antiImageHandler.CheckAndRevoke(client, evt) {
-		return // Image was revoked, no further processing needed.
+	for _, observe := range observers {
+		observe(client, evt)
 	}
 
 	// Extract text from various message types.
@@ -232,12 +395,28 @@ func handleMessage(
 	if parsed != nil {
 		// Rate limit check — only for commands, not regular messages.
 		if !evt.Info.IsFromMe {
-			switch limiter.Check(evt.Info.Sender.String(), evt.Info.Chat.String()) {
+			user, chat, cost := evt.Info.Sender.ToNonAD().String(), evt.Info.Chat.String(), registry.Cost(parsed.Command)
+			role := rateLimitRole(evt, groupHandler)
+			// Group admin status may need a network call, so it is only resolved for commands
+			// that would be rejected at the member rate.
+			if role == ratelimit.RoleMember && evt.Info.IsGroup && !limiter.Allows(user, chat, cost, role) &&
+				groupHandler.IsGroupAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+				role = ratelimit.RoleGroupAdmin
+			}
+			result, retryAfter := limiter.Check(user, chat, cost, role)
+			switch result {
 			case ratelimit.UserCooldown:
-				utils.ReplyTextDirect(client, evt, config.MsgRateLimitUser)
+				utils.ReplyTextDirect(client, evt, fmt.Sprintf(config.MsgRateLimitUser, retrySeconds(retryAfter)))
 				return
 			case ratelimit.ChatRateLimit:
-				utils.ReplyTextDirect(client, evt, config.MsgRateLimitChat)
+				utils.ReplyTextDirect(client, evt, fmt.Sprintf(config.MsgRateLimitChat, retrySeconds(retryAfter)))
+				return
+			case ratelimit.PenaltyStart:
+				slog.Info("User entered penalty box", "user", evt.Info.Sender.User, "chat", evt.Info.Chat.String())
+				utils.ReplyTextDirect(client, evt, fmt.Sprintf(config.MsgRateLimitPenalty, (retrySeconds(retryAfter)+59)/60))
+				return
+			case ratelimit.Ignored, ratelimit.Penalized:
+				// Repeat offenders are dropped silently so the bot does not spam back.
 				return
 			}
 		}
@@ -252,3 +431,24 @@ func handleMessage(
 		return
 	}
 }
+
+// rateLimitRole resolves the sender's privilege level for rate limit multipliers from the
+// configured owner and exception lists. Group admins are resolved separately, only when needed.
+func rateLimitRole(evt *events.Message, groupHandler *handlers.GroupHandler) ratelimit.Role {
+	switch {
+	case groupHandler.IsOwner(evt.Info.Sender):
+		return ratelimit.RoleOwner
+	case groupHandler.IsException(evt.Info.Sender):
+		return ratelimit.RoleException
+	}
+	return ratelimit.RoleMember
+}
+
+// retrySeconds rounds a retry-after duration up to whole seconds for user-facing replies.
+func retrySeconds(d time.Duration) int {
+	secs := int((d + time.Second - 1) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
+}
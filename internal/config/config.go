@@ -12,16 +12,49 @@ import (
 
 // Config variables
 var (
-	Prefixes                 = []string{".", "!", "/"}
-	BotDatabaseFile          = "bot.db"
-	RateLimitUserCooldownSec = 3
-	RateLimitChatMax         = 10
-	RateLimitChatWindowSec   = 60
-	MaxFileSizeMB            = 100
-	MaxAudioSizeMB           = 50
-	MaxConcurrentMediaTasks  = 4
-	OwnerJIDs                []string // JIDs of the bot owners
-	AdminExceptions          []string // JIDs of users with admin privileges
+	Prefixes                     = []string{".", "!", "/"}
+	BotDatabaseFile              = "bot.db"
+	RateLimitUserCooldownSec     = 3 // seconds needed to regain one rate limit token
+	RateLimitUserBurst           = 5 // max tokens a user can hold
+	RateLimitChatMax             = 10
+	RateLimitChatWindowSec       = 60
+	RateLimitOwnerMultiplier     = 0.0 // 0 exempts owners from rate limiting
+	RateLimitExceptionMultiplier = 0.5
+	RateLimitAdminMultiplier     = 0.5
+	RateLimitPenaltyThreshold    = 5 // rejected commands within the window before the penalty box, 0 = off
+	RateLimitPenaltyWindowSec    = 60
+	RateLimitPenaltySec          = 600 // how long the penalty box lasts
+	MaxFileSizeMB                = 100
+	MaxAudioSizeMB               = 50
+	MaxConcurrentMediaTasks      = 4
+	QuotaUserDownloads           = 10 // daily .dl/.mp3 downloads per user, 0 = unlimited
+	QuotaGroupDownloads          = 0  // daily .dl/.mp3 downloads per group, 0 = unlimited
+	QuotaUserDownloadMB          = 0  // daily downloaded MB per user, 0 = unlimited
+	QuotaGroupDownloadMB         = 200
+	QuotaUserMedia               = 50 // daily sticker/image conversions per user, 0 = unlimited
+	QuotaGroupMedia              = 0
+	BanExpiryNotify              = true // announce in the group when a temporary ban expires
+	FloodMaxMessages             = 8    // messages per user within the flood window, 0 = off
+	FloodWindowSec               = 10
+	FloodMuteSec                 = 600    // how long flooders are banned from chatting
+	WarnMuteAt                   = 3      // warnings before a temporary chat ban, 0 = off
+	WarnMuteSec                  = 3600   // length of that chat ban
+	WarnKickAt                   = 5      // warnings before the member is kicked, 0 = off
+	AntiDeleteCacheSize          = 1000   // messages kept for anti-delete
+	AntiDeleteCacheMB            = 64     // total text and media kept for anti-delete
+	AntiDeleteTTLMin             = 60     // how long deleted messages can still be restored
+	AntiDeleteMaxMediaKB         = 2048   // larger media is reposted as a text notice
+	ImageBlockDistance           = 10     // default Hamming distance (of 64 bits) for the image filter
+	CaptchaTimeoutMin            = 5      // minutes new members have to answer the captcha
+	GroupCacheTTLSec             = 300    // how long group metadata is cached between refetches
+	AntiMentionMax               = 10     // default mention count limit for .antimention, 0 = none
+	AntiMentionPercent           = 50     // default limit as a percentage of the group's members, 0 = none
+	AntiForwardScore             = 5      // default forwarding score from which .antiforward treats a message as forwarded many times
+	BanDocumentTypes             []string // extensions/mimetypes revoked by .bandoc, empty = all documents
+	OwnerJIDs                    []string // JIDs of the bot owners
+	AdminExceptions              []string // JIDs of users with admin privileges
+
+	ScheduleTimeZone = "Asia/Jakarta" // default IANA time zone for .schedule
 )
 
 // Bot metadata for sticker packs.
@@ -32,8 +65,9 @@ const (
 
 // Rate limit and system messages.
 const (
-	MsgRateLimitUser = "Terlalu cepat, tunggu beberapa detik."
-	MsgRateLimitChat = "Terlalu banyak perintah di chat ini, coba lagi nanti."
+	MsgRateLimitUser    = "Terlalu cepat, tunggu %d detik lagi."
+	MsgRateLimitChat    = "Terlalu banyak perintah di chat ini, coba lagi dalam %d detik."
+	MsgRateLimitPenalty = "Kamu terlalu sering spam perintah. Semua perintahmu diabaikan selama %d menit."
 )
 
 // Load reads configuration from .env and environment variables.
@@ -63,6 +97,15 @@ func Load() {
 			}
 		}
 	}
+	if v := os.Getenv("BAN_DOC_TYPES"); v != "" {
+		parts := strings.Split(v, ",")
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part != "" {
+				BanDocumentTypes = append(BanDocumentTypes, part)
+			}
+		}
+	}
 	if v := os.Getenv("BOT_DATABASE_FILE"); v != "" {
 		BotDatabaseFile = v
 	}
@@ -71,6 +114,11 @@ func Load() {
 			RateLimitUserCooldownSec = val
 		}
 	}
+	if v := os.Getenv("RATE_LIMIT_USER_BURST"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			RateLimitUserBurst = val
+		}
+	}
 	if v := os.Getenv("RATE_LIMIT_CHAT_MAX"); v != "" {
 		if val, err := strconv.Atoi(v); err == nil {
 			RateLimitChatMax = val
@@ -81,6 +129,36 @@ func Load() {
 			RateLimitChatWindowSec = val
 		}
 	}
+	if v := os.Getenv("RATE_LIMIT_OWNER_MULTIPLIER"); v != "" {
+		if val, err := strconv.ParseFloat(v, 64); err == nil {
+			RateLimitOwnerMultiplier = val
+		}
+	}
+	if v := os.Getenv("RATE_LIMIT_EXCEPTION_MULTIPLIER"); v != "" {
+		if val, err := strconv.ParseFloat(v, 64); err == nil {
+			RateLimitExceptionMultiplier = val
+		}
+	}
+	if v := os.Getenv("RATE_LIMIT_ADMIN_MULTIPLIER"); v != "" {
+		if val, err := strconv.ParseFloat(v, 64); err == nil {
+			RateLimitAdminMultiplier = val
+		}
+	}
+	if v := os.Getenv("RATE_LIMIT_PENALTY_THRESHOLD"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			RateLimitPenaltyThreshold = val
+		}
+	}
+	if v := os.Getenv("RATE_LIMIT_PENALTY_WINDOW_SEC"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			RateLimitPenaltyWindowSec = val
+		}
+	}
+	if v := os.Getenv("RATE_LIMIT_PENALTY_SEC"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			RateLimitPenaltySec = val
+		}
+	}
 	if v := os.Getenv("MAX_FILE_SIZE_MB"); v != "" {
 		if val, err := strconv.Atoi(v); err == nil {
 			MaxFileSizeMB = val
@@ -97,6 +175,130 @@ func Load() {
 			MaxConcurrentMediaTasks = val
 		}
 	}
+
+	if v := os.Getenv("BAN_EXPIRY_NOTIFY"); v != "" {
+		if val, err := strconv.ParseBool(v); err == nil {
+			BanExpiryNotify = val
+		}
+	}
+
+	if v := os.Getenv("FLOOD_MAX_MESSAGES"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			FloodMaxMessages = val
+		}
+	}
+	if v := os.Getenv("FLOOD_WINDOW_SEC"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			FloodWindowSec = val
+		}
+	}
+	if v := os.Getenv("FLOOD_MUTE_SEC"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			FloodMuteSec = val
+		}
+	}
+
+	if v := os.Getenv("WARN_MUTE_AT"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			WarnMuteAt = val
+		}
+	}
+	if v := os.Getenv("WARN_MUTE_SEC"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			WarnMuteSec = val
+		}
+	}
+	if v := os.Getenv("WARN_KICK_AT"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			WarnKickAt = val
+		}
+	}
+
+	if v := os.Getenv("ANTI_DELETE_CACHE_SIZE"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			AntiDeleteCacheSize = val
+		}
+	}
+	if v := os.Getenv("ANTI_DELETE_CACHE_MB"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			AntiDeleteCacheMB = val
+		}
+	}
+	if v := os.Getenv("ANTI_DELETE_TTL_MIN"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			AntiDeleteTTLMin = val
+		}
+	}
+	if v := os.Getenv("ANTI_DELETE_MAX_MEDIA_KB"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			AntiDeleteMaxMediaKB = val
+		}
+	}
+
+	if v := os.Getenv("IMAGE_BLOCK_DISTANCE"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			ImageBlockDistance = val
+		}
+	}
+	if v := os.Getenv("CAPTCHA_TIMEOUT_MIN"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			CaptchaTimeoutMin = val
+		}
+	}
+	if v := os.Getenv("ANTI_MENTION_MAX"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			AntiMentionMax = val
+		}
+	}
+	if v := os.Getenv("ANTI_MENTION_PERCENT"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			AntiMentionPercent = val
+		}
+	}
+	if v := os.Getenv("ANTI_FORWARD_SCORE"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			AntiForwardScore = val
+		}
+	}
+	if v := os.Getenv("GROUP_CACHE_TTL_SEC"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			GroupCacheTTLSec = val
+		}
+	}
+	if v := os.Getenv("SCHEDULE_TIMEZONE"); v != "" {
+		ScheduleTimeZone = v
+	}
+
+	if v := os.Getenv("QUOTA_USER_DOWNLOADS"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			QuotaUserDownloads = val
+		}
+	}
+	if v := os.Getenv("QUOTA_GROUP_DOWNLOADS"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			QuotaGroupDownloads = val
+		}
+	}
+	if v := os.Getenv("QUOTA_USER_DOWNLOAD_MB"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			QuotaUserDownloadMB = val
+		}
+	}
+	if v := os.Getenv("QUOTA_GROUP_DOWNLOAD_MB"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			QuotaGroupDownloadMB = val
+		}
+	}
+	if v := os.Getenv("QUOTA_USER_MEDIA"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			QuotaUserMedia = val
+		}
+	}
+	if v := os.Getenv("QUOTA_GROUP_MEDIA"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			QuotaGroupMedia = val
+		}
+	}
 }
 
 // ValidateURL checks that a URL is safe to pass to external tools.
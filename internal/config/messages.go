@@ -2,17 +2,44 @@ package config
 
 // Remote messages
 const (
-	MsgOnlyGroup = "Perintah ini hanya bisa digunakan di dalam grup."
-	MsgOnlyAdmin = "Perintah ini hanya untuk admin grup."
-	MsgMenu      = `
+	MsgOnlyGroup     = "Perintah ini hanya bisa digunakan di dalam grup."
+	MsgOnlyAdmin     = "Perintah ini hanya untuk admin grup."
+	MsgOnlyOwner     = "Perintah ini hanya untuk owner bot."
+	MsgQuotaExceeded = "Kuota harian untuk fitur ini sudah habis. Cek sisa kuota dengan .quota"
+	MsgBotNotAdmin   = "Bot belum menjadi admin grup ini. Jadikan bot admin terlebih dahulu."
+	MsgMenu          = `
 • .s / .sticker 
 • .brat <text> 
 • .toimg 
 • .dl <link> 
 • .mp3 <link> 
+• .quota
 
 • .tagall
 • .kick 
+• .promote
+• .demote
+• .add
+• .active
+• .inactive
+• .kickinactive
+• .blacklist
+• .sweep
+• .penalty
+• .close
+• .open
+• .schedule
+• .warn
+• .warnings
+• .resetwarn
+• .antilink
+• .flood
+• .filter
+• .antimention
+• .antiforward
+• .slowmode
+• .antidelete
+• .captcha
 
 • .banchat
 • .unbanchat 
@@ -23,5 +50,31 @@ const (
 • .banimg
 • .unbanimg 
 
+• .banaudio
+• .unbanaudio
+
+• .bandoc
+• .unbandoc
+
+• .bancontact
+• .unbancontact
+
+• .banlocation
+• .unbanlocation
+
+• .banpoll
+• .unbanpoll
+
+• .banlist
+
+• .blocksticker
+• .unblocksticker
+• .blockedstickers
+
+• .imgfilter
+• .blockimg
+• .unblockimg
+• .blockedimgs
+
 • .menu `
 )
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+const (
+	defaultInactiveDays   = 30
+	minKickInactiveDays   = 7
+	kickConfirmTimeout    = 2 * time.Minute
+	kickInactiveBatchSize = 20
+	inactiveListLimit     = 30
+	activeLeaderboardSize = 10
+)
+
+// kickRequest is a .kickinactive waiting for the issuer's confirmation.
+type kickRequest struct {
+	targets []types.JID
+	days    int
+	expires time.Time
+}
+
+// ActivityHandler records member activity and handles the activity report commands.
+type ActivityHandler struct {
+	store        *services.ActivityStore
+	groupHandler *GroupHandler
+
+	mu      sync.Mutex
+	pending map[string]kickRequest // keyed by group and issuer
+}
+
+// NewActivityHandler creates a new ActivityHandler.
+func NewActivityHandler(store *services.ActivityStore, groupHandler *GroupHandler) *ActivityHandler {
+	return &ActivityHandler{store: store, groupHandler: groupHandler, pending: make(map[string]kickRequest)}
+}
+
+// Observe counts a group message towards its sender's activity, keyed by the sender's canonical identity.
+func (h *ActivityHandler) Observe(client *whatsmeow.Client, evt *events.Message) {
+	if !evt.Info.IsGroup || evt.Info.IsFromMe {
+		return
+	}
+	if evt.Message.GetProtocolMessage() != nil || evt.Message.GetReactionMessage() != nil {
+		return
+	}
+	h.store.RecordMessage(evt.Info.Chat.String(), h.groupHandler.senderJID(evt).String(), evt.Info.Timestamp)
+}
+
+// OnJoin records a new member's join as activity so they are not reported as inactive right away.
+func (h *ActivityHandler) OnJoin(client *whatsmeow.Client, evt *events.GroupInfo, member types.JID) bool {
+	h.store.RecordJoin(evt.JID.String(), h.groupHandler.userKey(member), time.Now())
+	return true
+}
+
+// HandleActive shows the members with the most messages in the group.
+// Usage: .active
+func (h *ActivityHandler) HandleActive(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	top := h.store.Top(groupJID, activeLeaderboardSize)
+	if len(top) == 0 {
+		utils.ReplyTextDirect(client, evt, "Belum ada data aktivitas di grup ini.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🏆 *Member Paling Aktif*\n")
+	if since, ok := h.store.TrackingSince(groupJID); ok {
+		sb.WriteString(fmt.Sprintf("Sejak %s\n", since.Format("02/01/2006")))
+	}
+	var mentions []string
+	for i, a := range top {
+		user := strings.SplitN(a.JID, "@", 2)[0]
+		sb.WriteString(fmt.Sprintf("\n%d. @%s — %d pesan", i+1, user, a.Messages))
+		mentions = append(mentions, a.JID)
+	}
+	utils.ReplyTextDirectWithMentions(client, evt, sb.String(), mentions)
+}
+
+// HandleInactive lists members without activity in the last days (admin only).
+// Usage: .inactive [hari]
+func (h *ActivityHandler) HandleInactive(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	days := defaultInactiveDays
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			utils.ReplyTextDirect(client, evt, "Contoh: .inactive 30")
+			return
+		}
+		days = n
+	}
+
+	inactive, ok := h.inactive(client, evt, days)
+	if !ok {
+		return
+	}
+	if len(inactive) == 0 {
+		utils.ReplyTextDirect(client, evt, fmt.Sprintf("Semua member aktif dalam %d hari terakhir.", days))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("💤 *%d member tidak aktif dalam %d hari*\n", len(inactive), days))
+	sb.WriteString(h.trackingNote(evt.Info.Chat.String(), days))
+	var mentions []string
+	for i, m := range inactive {
+		if i == inactiveListLimit {
+			sb.WriteString(fmt.Sprintf("\n... dan %d lainnya", len(inactive)-inactiveListLimit))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("\n%d. @%s — %s", i+1, m.jid.User, m.describe()))
+		mentions = append(mentions, m.jid.String())
+	}
+	utils.ReplyTextDirectWithMentions(client, evt, sb.String(), mentions)
+}
+
+// HandleKickInactive kicks members without activity in the last days after the issuer confirms (admin only).
+// Usage: .kickinactive <hari> | .kickinactive confirm
+func (h *ActivityHandler) HandleKickInactive(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	key := evt.Info.Chat.String() + "|" + evt.Info.Sender.ToNonAD().String()
+	if len(args) > 0 && strings.ToLower(args[0]) == "confirm" {
+		h.mu.Lock()
+		req, ok := h.pending[key]
+		delete(h.pending, key)
+		h.mu.Unlock()
+
+		if !ok || time.Now().After(req.expires) {
+			utils.ReplyTextDirect(client, evt, "Tidak ada permintaan yang menunggu konfirmasi. Mulai dengan .kickinactive <hari>")
+			return
+		}
+		h.kick(client, evt, req)
+		return
+	}
+
+	days := 0
+	if len(args) > 0 {
+		days, _ = strconv.Atoi(args[0])
+	}
+	if days < minKickInactiveDays {
+		utils.ReplyTextDirect(client, evt, fmt.Sprintf("Contoh: .kickinactive 30 (minimal %d hari)", minKickInactiveDays))
+		return
+	}
+
+	if since, ok := h.store.TrackingSince(evt.Info.Chat.String()); !ok || time.Since(since) < time.Duration(days)*24*time.Hour {
+		utils.ReplyTextDirect(client, evt, fmt.Sprintf("Data aktivitas grup ini belum mencakup %d hari, jadi belum bisa dipakai untuk mengeluarkan member.", days))
+		return
+	}
+
+	if !h.groupHandler.botIsAdmin(client, evt.Info.Chat) {
+		utils.ReplyTextDirect(client, evt, config.MsgBotNotAdmin)
+		return
+	}
+
+	inactive, ok := h.inactive(client, evt, days)
+	if !ok {
+		return
+	}
+	if len(inactive) == 0 {
+		utils.ReplyTextDirect(client, evt, fmt.Sprintf("Semua member aktif dalam %d hari terakhir.", days))
+		return
+	}
+
+	req := kickRequest{days: days, expires: time.Now().Add(kickConfirmTimeout)}
+	for _, m := range inactive {
+		req.targets = append(req.targets, m.jid)
+	}
+	h.mu.Lock()
+	h.pending[key] = req
+	h.mu.Unlock()
+
+	utils.ReplyTextDirect(client, evt, fmt.Sprintf("⚠️ %d member tidak aktif dalam %d hari akan dikeluarkan (lihat .inactive %d).\nKetik *.kickinactive confirm* dalam %s untuk melanjutkan.",
+		len(req.targets), days, days, utils.FormatDuration(kickConfirmTimeout)))
+}
+
+// kick removes the members of a confirmed request in batches.
+func (h *ActivityHandler) kick(client *whatsmeow.Client, evt *events.Message, req kickRequest) {
+	kicked, failed := 0, 0
+	for start := 0; start < len(req.targets); start += kickInactiveBatchSize {
+		batch := req.targets[start:min(start+kickInactiveBatchSize, len(req.targets))]
+		results, err := client.UpdateGroupParticipants(context.Background(), evt.Info.Chat, batch, whatsmeow.ParticipantChangeRemove)
+		if err != nil {
+			slog.Error("failed to kick inactive members", "error", err)
+			failed += len(batch)
+			continue
+		}
+		for _, result := range results {
+			if result.Error == 0 {
+				kicked++
+			} else {
+				failed++
+			}
+		}
+	}
+
+	slog.Info("Inactive members kicked", "chat", evt.Info.Chat.String(), "days", req.days, "kicked", kicked, "failed", failed, "issuer", evt.Info.Sender.User)
+	text := fmt.Sprintf("👢 %d member tidak aktif dikeluarkan.", kicked)
+	if failed > 0 {
+		text += fmt.Sprintf("\n%d member gagal dikeluarkan.", failed)
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
+
+// inactiveMember is a group member without recent activity.
+type inactiveMember struct {
+	jid        types.JID
+	lastActive time.Time // zero if never seen
+}
+
+func (m inactiveMember) describe() string {
+	if m.lastActive.IsZero() {
+		return "belum pernah terlihat"
+	}
+	return "terakhir " + m.lastActive.Format("02/01/2006")
+}
+
+// inactive lists the group's members without activity in the last days, longest inactive first.
+// Admins, owners, exceptions and the bot are never listed. Replies and returns false on error.
+func (h *ActivityHandler) inactive(client *whatsmeow.Client, evt *events.Message, days int) ([]inactiveMember, bool) {
+	groupInfo, err := h.groupHandler.GroupInfo(client, evt.Info.Chat)
+	if err != nil {
+		slog.Error("failed to get group info", "error", err)
+		utils.ReplyTextDirect(client, evt, "Gagal mengambil info grup.")
+		return nil, false
+	}
+
+	var candidates []types.GroupParticipant
+	for _, p := range groupInfo.Participants {
+		if p.IsAdmin || p.IsSuperAdmin || isBotJID(client, p.JID) || h.groupHandler.IsOwner(p.JID) || h.groupHandler.IsException(p.JID) {
+			continue
+		}
+		candidates = append(candidates, p)
+	}
+
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	return findInactive(candidates, h.store.Members(evt.Info.Chat.String()), cutoff), true
+}
+
+// findInactive returns the participants whose last activity is before cutoff, longest inactive first.
+// A participant's activity may be recorded under their phone number or their LID.
+func findInactive(participants []types.GroupParticipant, activity map[string]services.MemberActivity, cutoff time.Time) []inactiveMember {
+	var inactive []inactiveMember
+	for _, p := range participants {
+		var last time.Time
+		for _, jid := range []types.JID{p.JID, p.PhoneNumber, p.LID} {
+			if a, ok := activity[jid.ToNonAD().String()]; ok && !jid.IsEmpty() && a.LastActive.After(last) {
+				last = a.LastActive
+			}
+		}
+		if last.Before(cutoff) {
+			inactive = append(inactive, inactiveMember{jid: p.JID, lastActive: last})
+		}
+	}
+
+	sort.SliceStable(inactive, func(i, j int) bool {
+		return inactive[i].lastActive.Before(inactive[j].lastActive)
+	})
+	return inactive
+}
+
+// trackingNote warns when activity has been recorded for less than the requested period.
+func (h *ActivityHandler) trackingNote(groupJID string, days int) string {
+	since, ok := h.store.TrackingSince(groupJID)
+	if ok && time.Since(since) >= time.Duration(days)*24*time.Hour {
+		return ""
+	}
+	if !ok {
+		return "_Catatan: bot belum punya data aktivitas grup ini._\n"
+	}
+	return fmt.Sprintf("_Catatan: data aktivitas baru tersedia sejak %s._\n", since.Format("02/01/2006"))
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+
+	"chisa_bot/internal/services"
+)
+
+func TestFindInactive(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-30 * 24 * time.Hour)
+
+	pn := func(user string) types.JID { return types.NewJID(user, types.DefaultUserServer) }
+	lid := func(user string) types.JID { return types.NewJID(user, types.HiddenUserServer) }
+
+	participants := []types.GroupParticipant{
+		{JID: pn("111")}, // active
+		{JID: pn("222")}, // inactive for 40 days
+		{JID: lid("333"), PhoneNumber: pn("628333")}, // active under their phone number
+		{JID: pn("444")}, // never seen
+		{JID: lid("555"), PhoneNumber: pn("628555")}, // inactive for 35 days under their LID
+	}
+	activity := map[string]services.MemberActivity{
+		"111@s.whatsapp.net":    {LastActive: now.Add(-time.Hour)},
+		"222@s.whatsapp.net":    {LastActive: now.Add(-40 * 24 * time.Hour)},
+		"628333@s.whatsapp.net": {LastActive: now.Add(-24 * time.Hour)},
+		"555@lid":               {LastActive: now.Add(-35 * 24 * time.Hour)},
+	}
+
+	got := findInactive(participants, activity, cutoff)
+	want := []string{"444", "222", "555"} // never seen first, then longest inactive
+	if len(got) != len(want) {
+		t.Fatalf("findInactive() = %+v, want users %v", got, want)
+	}
+	for i := range want {
+		if got[i].jid.User != want[i] {
+			t.Errorf("inactive[%d] = %s, want %s", i, got[i].jid.User, want[i])
+		}
+	}
+	if !got[0].lastActive.IsZero() {
+		t.Error("never seen member should have no last activity")
+	}
+}
+
+func TestActivityHandlerKeysByCanonicalIdentity(t *testing.T) {
+	groupHandler := NewGroupHandler(services.NewGroupCache(time.Minute), services.NewIdentity(lidMap{lid: "111", pn: "628111"}))
+	store := services.NewActivityStore(newTestDB(t))
+	h := NewActivityHandler(store, groupHandler)
+
+	group := types.NewJID("123", types.GroupServer)
+	for _, sender := range []types.JID{
+		types.NewJID("111", types.HiddenUserServer),
+		types.NewADJID("628111", 0, 2),
+	} {
+		evt := &events.Message{
+			Info:    types.MessageInfo{MessageSource: types.MessageSource{Chat: group, Sender: sender, IsGroup: true}, Timestamp: time.Now()},
+			Message: &waE2E.Message{Conversation: proto.String("halo")},
+		}
+		h.Observe(nil, evt)
+	}
+	h.OnJoin(nil, &events.GroupInfo{JID: group}, types.NewJID("111", types.HiddenUserServer))
+
+	members := store.Members(group.String())
+	if len(members) != 1 {
+		t.Fatalf("Members() = %+v, want one row for both forms", members)
+	}
+	if a := members["628111@s.whatsapp.net"]; a.Messages != 2 {
+		t.Errorf("member 628111 = %+v, want 2 messages", a)
+	}
+}
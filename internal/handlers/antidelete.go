@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+// AntiDeleteHandler reposts messages that members delete in groups that opted in.
+type AntiDeleteHandler struct {
+	store        *services.AntiDeleteStore
+	cache        *services.MessageCache
+	groupHandler *GroupHandler
+	maxMediaSize uint64 // larger media is reposted as a text notice only
+}
+
+// NewAntiDeleteHandler creates a new AntiDeleteHandler.
+func NewAntiDeleteHandler(store *services.AntiDeleteStore, cache *services.MessageCache, groupHandler *GroupHandler, maxMediaSize uint64) *AntiDeleteHandler {
+	return &AntiDeleteHandler{store: store, cache: cache, groupHandler: groupHandler, maxMediaSize: maxMediaSize}
+}
+
+// Observe caches messages in anti-delete groups and reposts them when a member revokes them.
+// Revokes sent by the bot itself, including moderation revokes, are never reposted.
+func (h *AntiDeleteHandler) Observe(client *whatsmeow.Client, evt *events.Message) {
+	if !evt.Info.IsGroup || evt.Info.IsFromMe {
+		return
+	}
+	if !h.store.Enabled(evt.Info.Chat.String()) {
+		return
+	}
+
+	if protocol := evt.Message.GetProtocolMessage(); protocol != nil {
+		if protocol.GetType() == waProto.ProtocolMessage_REVOKE {
+			h.handleRevoke(client, evt, protocol.GetKey().GetID())
+		}
+		return
+	}
+
+	if msg, ok := h.snapshot(client, evt); ok {
+		h.cache.Put(msg)
+	}
+}
+
+// handleRevoke reposts a cached message unless it was deleted by an admin.
+func (h *AntiDeleteHandler) handleRevoke(client *whatsmeow.Client, evt *events.Message, revokedID string) {
+	cached, ok := h.cache.Take(evt.Info.Chat.String(), revokedID)
+	if !ok {
+		return
+	}
+
+	if h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		return
+	}
+
+	senderJID, err := types.ParseJID(cached.Sender)
+	if err != nil {
+		return
+	}
+
+	slog.Info("Revoked message — reposting", "user", senderJID.User, "chat", cached.Chat, "type", cached.MediaType)
+
+	header := fmt.Sprintf("🗑️ @%s deleted:", senderJID.User)
+	if err := h.repost(client, evt.Info.Chat, cached, header); err != nil {
+		slog.Error("failed to repost deleted message", "error", err)
+	}
+}
+
+// snapshot copies the content of a message, downloading media up to maxMediaSize.
+// Returns false for messages without restorable content.
+func (h *AntiDeleteHandler) snapshot(client *whatsmeow.Client, evt *events.Message) (services.CachedMessage, bool) {
+	msg := services.CachedMessage{
+		Chat:   evt.Info.Chat.String(),
+		ID:     evt.Info.ID,
+		Sender: evt.Info.Sender.ToNonAD().String(),
+		Text:   moderationText(evt),
+		At:     evt.Info.Timestamp,
+	}
+
+	inner := utils.UnwrapViewOnce(evt.Message)
+	var size uint64
+	switch {
+	case inner.GetImageMessage() != nil:
+		img := inner.GetImageMessage()
+		msg.MediaType, msg.Mimetype, size = "image", img.GetMimetype(), img.GetFileLength()
+	case inner.GetVideoMessage() != nil:
+		vid := inner.GetVideoMessage()
+		msg.MediaType, msg.Mimetype, size = "video", vid.GetMimetype(), vid.GetFileLength()
+	case inner.GetAudioMessage() != nil:
+		aud := inner.GetAudioMessage()
+		msg.MediaType, msg.Mimetype, size = "audio", aud.GetMimetype(), aud.GetFileLength()
+		msg.PTT = aud.GetPTT()
+	case inner.GetStickerMessage() != nil:
+		stk := inner.GetStickerMessage()
+		msg.MediaType, msg.Mimetype, size = "sticker", stk.GetMimetype(), stk.GetFileLength()
+		msg.Animated = stk.GetIsAnimated()
+	case inner.GetDocumentMessage() != nil:
+		doc := inner.GetDocumentMessage()
+		msg.MediaType, msg.Mimetype, size = "document", doc.GetMimetype(), doc.GetFileLength()
+		msg.FileName = doc.GetFileName()
+	}
+
+	if msg.MediaType == "" {
+		return msg, msg.Text != ""
+	}
+
+	if size > 0 && size <= h.maxMediaSize {
+		data, err := utils.DownloadMediaFromMessage(client, evt.Message)
+		if err != nil {
+			slog.Warn("failed to cache media for anti-delete", "error", err)
+		} else {
+			msg.Media = data
+		}
+	}
+	return msg, true
+}
+
+// repost sends a cached message back to the chat, announcing who deleted it.
+// Media that was not downloaded is described in text instead.
+func (h *AntiDeleteHandler) repost(client *whatsmeow.Client, chat types.JID, cached services.CachedMessage, header string) error {
+	mentions := []string{cached.Sender}
+
+	if cached.MediaType == "" || cached.Media == nil {
+		text := header
+		if cached.MediaType != "" {
+			text += fmt.Sprintf("\n[%s]", cached.MediaType)
+		}
+		if cached.Text != "" {
+			text += "\n\n" + cached.Text
+		}
+		h.groupHandler.sendGroupMention(client, chat, text, mentions)
+		return nil
+	}
+
+	caption := header
+	if cached.Text != "" {
+		caption += "\n\n" + cached.Text
+	}
+	contextInfo := &waProto.ContextInfo{MentionedJID: mentions}
+
+	var msg *waProto.Message
+	switch cached.MediaType {
+	case "image":
+		uploaded, err := client.Upload(context.Background(), cached.Media, whatsmeow.MediaImage)
+		if err != nil {
+			return fmt.Errorf("failed to upload image: %w", err)
+		}
+		msg = &waProto.Message{ImageMessage: &waProto.ImageMessage{
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uint64(len(cached.Media))),
+			Mimetype:      proto.String(cached.Mimetype),
+			Caption:       proto.String(caption),
+			ContextInfo:   contextInfo,
+		}}
+
+	case "video":
+		uploaded, err := client.Upload(context.Background(), cached.Media, whatsmeow.MediaVideo)
+		if err != nil {
+			return fmt.Errorf("failed to upload video: %w", err)
+		}
+		msg = &waProto.Message{VideoMessage: &waProto.VideoMessage{
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uint64(len(cached.Media))),
+			Mimetype:      proto.String(cached.Mimetype),
+			Caption:       proto.String(caption),
+			ContextInfo:   contextInfo,
+		}}
+
+	case "document":
+		uploaded, err := client.Upload(context.Background(), cached.Media, whatsmeow.MediaDocument)
+		if err != nil {
+			return fmt.Errorf("failed to upload document: %w", err)
+		}
+		msg = &waProto.Message{DocumentMessage: &waProto.DocumentMessage{
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uint64(len(cached.Media))),
+			Mimetype:      proto.String(cached.Mimetype),
+			FileName:      proto.String(cached.FileName),
+			Caption:       proto.String(caption),
+			ContextInfo:   contextInfo,
+		}}
+
+	case "audio":
+		// Audio and stickers cannot carry a caption, so the header is sent first.
+		h.groupHandler.sendGroupMention(client, chat, caption, mentions)
+		uploaded, err := client.Upload(context.Background(), cached.Media, whatsmeow.MediaAudio)
+		if err != nil {
+			return fmt.Errorf("failed to upload audio: %w", err)
+		}
+		msg = &waProto.Message{AudioMessage: &waProto.AudioMessage{
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uint64(len(cached.Media))),
+			Mimetype:      proto.String(cached.Mimetype),
+			PTT:           proto.Bool(cached.PTT),
+		}}
+
+	case "sticker":
+		h.groupHandler.sendGroupMention(client, chat, caption, mentions)
+		uploaded, err := client.Upload(context.Background(), cached.Media, whatsmeow.MediaImage)
+		if err != nil {
+			return fmt.Errorf("failed to upload sticker: %w", err)
+		}
+		msg = &waProto.Message{StickerMessage: &waProto.StickerMessage{
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uint64(len(cached.Media))),
+			Mimetype:      proto.String("image/webp"),
+			IsAnimated:    proto.Bool(cached.Animated),
+		}}
+
+	default:
+		return fmt.Errorf("unknown media type %q", cached.MediaType)
+	}
+
+	_, err := client.SendMessage(context.Background(), chat, msg)
+	return err
+}
+
+// HandleAntiDelete turns anti-delete on or off in the group (admin only).
+// Usage: .antidelete [on|off]
+func (h *AntiDeleteHandler) HandleAntiDelete(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	if len(args) == 0 {
+		status := "nonaktif"
+		if h.store.Enabled(groupJID) {
+			status = "aktif"
+		}
+		utils.ReplyTextDirect(client, evt, fmt.Sprintf("🗑️ Anti-delete %s di grup ini.\nGunakan .antidelete on|off", status))
+		return
+	}
+
+	var text string
+	switch strings.ToLower(args[0]) {
+	case "on":
+		text = "Anti-delete aktif: pesan yang dihapus member akan dikirim ulang."
+		if !h.store.SetEnabled(groupJID, true) {
+			text = "Gagal menyimpan pengaturan anti-delete."
+		}
+	case "off":
+		text = "Anti-delete dinonaktifkan."
+		if !h.store.SetEnabled(groupJID, false) {
+			text = "Gagal menyimpan pengaturan anti-delete."
+		}
+	default:
+		text = "Contoh: .antidelete on|off"
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+// AntiForwardHandler applies the group's policy to forwarded messages and handles .antiforward.
+type AntiForwardHandler struct {
+	store        *services.ForwardStore
+	groupHandler *GroupHandler
+	warnHandler  *WarnHandler
+}
+
+// NewAntiForwardHandler creates a new AntiForwardHandler.
+func NewAntiForwardHandler(store *services.ForwardStore, groupHandler *GroupHandler, warnHandler *WarnHandler) *AntiForwardHandler {
+	return &AntiForwardHandler{store: store, groupHandler: groupHandler, warnHandler: warnHandler}
+}
+
+// CheckAndRevoke applies the group's forwarded-message policy to a group message.
+// Returns true if the message was revoked, false otherwise.
+func (h *AntiForwardHandler) CheckAndRevoke(client *whatsmeow.Client, evt *events.Message) bool {
+	if !evt.Info.IsGroup || evt.Info.IsFromMe {
+		return false
+	}
+
+	ctxInfo := utils.GetContextInfo(evt.Message)
+	score := ctxInfo.GetForwardingScore()
+	if !ctxInfo.GetIsForwarded() && score == 0 {
+		return false
+	}
+
+	chat := evt.Info.Chat.String()
+	action, frequent := h.store.Settings(chat).Action(ctxInfo.GetIsForwarded(), score)
+	if action == "" {
+		return false
+	}
+
+	if h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		return false
+	}
+
+	slog.Info("Forwarded message — revoking", "action", action, "score", score, "frequent", frequent, "user", evt.Info.Sender.User, "chat", chat)
+
+	revokeMsg := client.BuildRevoke(evt.Info.Chat, evt.Info.Sender, evt.Info.ID)
+	if _, err := client.SendMessage(context.Background(), evt.Info.Chat, revokeMsg); err != nil {
+		slog.Error("failed to revoke forwarded message", "error", err)
+		return false
+	}
+
+	if action == services.ActionWarn {
+		reason := "meneruskan pesan"
+		if frequent {
+			reason = "meneruskan pesan yang sudah diteruskan berkali-kali"
+		}
+		h.warnHandler.Warn(client, evt.Info.Chat, evt.Info.Sender.ToNonAD(), "", reason)
+	}
+	return true
+}
+
+// HandleAntiForward shows or changes the group's forwarded-message policy (admin only).
+// Usage: .antiforward [revoke|warn|off] | .antiforward many <revoke|warn|off> | .antiforward score <n>
+func (h *AntiForwardHandler) HandleAntiForward(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	settings := h.store.Settings(groupJID)
+	usage := "Contoh:\n.antiforward revoke|warn|off (semua pesan terusan)\n.antiforward many revoke|warn|off (pesan yang diteruskan berkali-kali)\n.antiforward score 5"
+
+	if len(args) == 0 {
+		utils.ReplyTextDirect(client, evt, describeForwardSettings(settings)+"\n\n"+usage)
+		return
+	}
+
+	var text string
+	switch sub := strings.ToLower(args[0]); sub {
+	case "off":
+		settings.Forwarded, settings.Frequent = "", ""
+		text = "Filter pesan terusan dinonaktifkan."
+	case "many":
+		if len(args) < 2 {
+			utils.ReplyTextDirect(client, evt, usage)
+			return
+		}
+		action, ok := parseForwardAction(args[1])
+		if !ok {
+			utils.ReplyTextDirect(client, evt, usage)
+			return
+		}
+		settings.Frequent = action
+		text = describeForwardSettings(settings)
+	case "score":
+		n := 0
+		if len(args) > 1 {
+			n, _ = strconv.Atoi(args[1])
+		}
+		if n < 2 {
+			utils.ReplyTextDirect(client, evt, "Contoh: .antiforward score 5 (minimal 2)")
+			return
+		}
+		settings.FrequentScore = n
+		text = describeForwardSettings(settings)
+	default:
+		action, ok := parseForwardAction(sub)
+		if !ok {
+			utils.ReplyTextDirect(client, evt, usage)
+			return
+		}
+		settings.Forwarded = action
+		text = describeForwardSettings(settings)
+	}
+
+	if !h.store.SetSettings(groupJID, settings) {
+		text = "Gagal menyimpan pengaturan filter pesan terusan."
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
+
+// parseForwardAction reads revoke, warn or off (the empty action).
+func parseForwardAction(s string) (services.ModerationAction, bool) {
+	if strings.ToLower(s) == "off" {
+		return "", true
+	}
+	action, ok := services.ParseModerationAction(s)
+	if !ok || action == services.ActionKick {
+		return "", false
+	}
+	return action, true
+}
+
+// describeForwardSettings summarizes a group's forwarded-message policy.
+func describeForwardSettings(s services.ForwardSettings) string {
+	if !s.Enabled() {
+		return "↪️ Filter pesan terusan nonaktif di grup ini."
+	}
+	return fmt.Sprintf("↪️ *Filter Pesan Terusan*\n\nPesan terusan: %s\nDiteruskan %d kali atau lebih: %s",
+		describeForwardAction(s.Forwarded), s.FrequentScore, describeForwardAction(s.Frequent))
+}
+
+// describeForwardAction describes what happens to a kind of forwarded message.
+func describeForwardAction(action services.ModerationAction) string {
+	switch action {
+	case services.ActionRevoke:
+		return "dihapus"
+	case services.ActionWarn:
+		return "dihapus + peringatan"
+	}
+	return "dibiarkan"
+}
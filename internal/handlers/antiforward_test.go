@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"testing"
+
+	"chisa_bot/internal/services"
+)
+
+func TestParseForwardAction(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   services.ModerationAction
+		wantOK bool
+	}{
+		{"revoke", services.ActionRevoke, true},
+		{"WARN", services.ActionWarn, true},
+		{"off", "", true},
+		{"kick", "", false},
+		{"hapus", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseForwardAction(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseForwardAction(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
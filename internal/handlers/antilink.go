@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/router"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+// AntiLinkHandler revokes links posted by members and handles the .antilink command.
+type AntiLinkHandler struct {
+	store        *services.AntiLinkStore
+	groupHandler *GroupHandler
+	warnHandler  *WarnHandler
+	urlCommands  map[string]bool
+}
+
+// NewAntiLinkHandler creates a new AntiLinkHandler.
+// urlCommands are the commands that take a link as their first argument (e.g. .dl <link>);
+// that argument is not treated as link spam, but any other link in the message is.
+func NewAntiLinkHandler(store *services.AntiLinkStore, groupHandler *GroupHandler, warnHandler *WarnHandler, urlCommands []string) *AntiLinkHandler {
+	commands := make(map[string]bool, len(urlCommands))
+	for _, cmd := range urlCommands {
+		commands[strings.ToLower(cmd)] = true
+	}
+	return &AntiLinkHandler{store: store, groupHandler: groupHandler, warnHandler: warnHandler, urlCommands: commands}
+}
+
+// CheckAndRevoke checks a group message for forbidden links and applies the group's action.
+// Returns true if the message was revoked, false otherwise.
+func (h *AntiLinkHandler) CheckAndRevoke(client *whatsmeow.Client, evt *events.Message) bool {
+	if !evt.Info.IsGroup || evt.Info.IsFromMe {
+		return false
+	}
+
+	text := linkScanText(moderationText(evt), h.urlCommands)
+	if text == "" {
+		return false
+	}
+
+	hosts := utils.ExtractLinkHosts(text)
+	if len(hosts) == 0 {
+		return false
+	}
+
+	settings := h.store.Settings(evt.Info.Chat.String())
+	host, blocked := blockedLinkHost(hosts, settings)
+	if !blocked {
+		return false
+	}
+
+	// Only resolve admin status once a forbidden link was found, since it needs a network call.
+	if h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		return false
+	}
+
+	slog.Info("Forbidden link — revoking", "host", host, "action", settings.Action, "user", evt.Info.Sender.User, "chat", evt.Info.Chat.String())
+
+	revokeMsg := client.BuildRevoke(evt.Info.Chat, evt.Info.Sender, evt.Info.ID)
+	if _, err := client.SendMessage(context.Background(), evt.Info.Chat, revokeMsg); err != nil {
+		slog.Error("failed to revoke link message", "error", err)
+		return false
+	}
+
+	sender := evt.Info.Sender.ToNonAD()
+	switch settings.Action {
+	case services.ActionWarn:
+		reason := "mengirim link " + host
+		if utils.IsWhatsAppInviteHost(host) {
+			reason = "membagikan link grup WhatsApp"
+		}
+		h.warnHandler.Warn(client, evt.Info.Chat, sender, "", reason)
+	case services.ActionKick:
+		_, err := client.UpdateGroupParticipants(context.Background(), evt.Info.Chat, []types.JID{sender}, whatsmeow.ParticipantChangeRemove)
+		if err != nil {
+			slog.Error("failed to kick link spammer", "error", err)
+			return true
+		}
+		h.groupHandler.sendGroupMention(client, evt.Info.Chat,
+			fmt.Sprintf("@%s dikeluarkan karena mengirim link.", sender.User), []string{sender.String()})
+	}
+	return true
+}
+
+// HandleAntiLink shows or changes the group's anti-link settings (admin only).
+// Usage: .antilink [on|invite|off] | .antilink action <revoke|warn|kick> | .antilink <allow|deny|remove> <domain>
+func (h *AntiLinkHandler) HandleAntiLink(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	if len(args) == 0 {
+		h.sendStatus(client, evt, h.store.Settings(groupJID))
+		return
+	}
+
+	switch sub := strings.ToLower(args[0]); sub {
+	case "on", "all":
+		h.reply(client, evt, h.store.SetMode(groupJID, services.AntiLinkAll), "Anti-link aktif: semua link dari member akan dihapus.")
+	case "invite":
+		h.reply(client, evt, h.store.SetMode(groupJID, services.AntiLinkInvite), "Anti-link aktif: hanya link grup WhatsApp dan domain yang diblokir yang akan dihapus.")
+	case "off":
+		h.reply(client, evt, h.store.SetMode(groupJID, services.AntiLinkOff), "Anti-link dinonaktifkan.")
+
+	case "action":
+		if len(args) < 2 {
+			utils.ReplyTextDirect(client, evt, "Contoh: .antilink action <revoke|warn|kick>")
+			return
+		}
+		action, ok := services.ParseModerationAction(args[1])
+		if !ok {
+			utils.ReplyTextDirect(client, evt, "Aksi tidak dikenal. Pilihan: revoke, warn, kick")
+			return
+		}
+		h.reply(client, evt, h.store.SetAction(groupJID, action), fmt.Sprintf("Aksi anti-link diubah menjadi *%s*.", action))
+
+	case "allow", "deny", "remove":
+		if len(args) < 2 {
+			utils.ReplyTextDirect(client, evt, fmt.Sprintf("Contoh: .antilink %s youtube.com", sub))
+			return
+		}
+		domain, ok := normalizeDomain(args[1])
+		if !ok {
+			utils.ReplyTextDirect(client, evt, "Domain tidak valid. Contoh: youtube.com")
+			return
+		}
+		switch sub {
+		case "allow":
+			h.reply(client, evt, h.store.SetDomain(groupJID, domain, true), fmt.Sprintf("Link dari %s sekarang diizinkan.", domain))
+		case "deny":
+			h.reply(client, evt, h.store.SetDomain(groupJID, domain, false), fmt.Sprintf("Link dari %s sekarang selalu dihapus.", domain))
+		default:
+			if !h.store.RemoveDomain(groupJID, domain) {
+				utils.ReplyTextDirect(client, evt, fmt.Sprintf("%s tidak ada di daftar domain.", domain))
+				return
+			}
+			utils.ReplyTextDirect(client, evt, fmt.Sprintf("%s dihapus dari daftar domain.", domain))
+		}
+
+	default:
+		utils.ReplyTextDirect(client, evt, "Contoh:\n.antilink on|invite|off\n.antilink action <revoke|warn|kick>\n.antilink allow|deny|remove <domain>")
+	}
+}
+
+func (h *AntiLinkHandler) reply(client *whatsmeow.Client, evt *events.Message, ok bool, text string) {
+	if !ok {
+		utils.ReplyTextDirect(client, evt, "Gagal menyimpan pengaturan anti-link.")
+		return
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
+
+func (h *AntiLinkHandler) sendStatus(client *whatsmeow.Client, evt *events.Message, settings services.AntiLinkSettings) {
+	var sb strings.Builder
+	sb.WriteString("🔗 *Anti-Link*\n")
+	sb.WriteString(fmt.Sprintf("\nMode: %s", settings.Mode))
+	sb.WriteString(fmt.Sprintf("\nAksi: %s", settings.Action))
+	if len(settings.Allow) > 0 {
+		sb.WriteString("\nDiizinkan: " + strings.Join(settings.Allow, ", "))
+	}
+	if len(settings.Deny) > 0 {
+		sb.WriteString("\nDiblokir: " + strings.Join(settings.Deny, ", "))
+	}
+	utils.ReplyTextDirect(client, evt, sb.String())
+}
+
+// linkScanText returns the part of text that is checked for links. When text invokes one of
+// urlCommands, its first argument is the link to work on and is left out, unless it is a
+// WhatsApp group invite, which no such command needs.
+func linkScanText(text string, urlCommands map[string]bool) string {
+	parsed := router.Parse(text)
+	if parsed == nil || !urlCommands[parsed.Command] || len(parsed.Args) == 0 {
+		return text
+	}
+	for _, host := range utils.ExtractLinkHosts(parsed.Args[0]) {
+		if utils.IsWhatsAppInviteHost(host) {
+			return text
+		}
+	}
+	return strings.Join(parsed.Args[1:], " ")
+}
+
+// blockedLinkHost returns the first host that the settings forbid.
+// Denied domains always match and allowed domains never do. Otherwise WhatsApp invite links
+// match in every active mode, and other links only in AntiLinkAll.
+func blockedLinkHost(hosts []string, settings services.AntiLinkSettings) (string, bool) {
+	if settings.Mode == services.AntiLinkOff {
+		return "", false
+	}
+
+	for _, host := range hosts {
+		if matchesAnyDomain(host, settings.Deny) {
+			return host, true
+		}
+		if matchesAnyDomain(host, settings.Allow) {
+			continue
+		}
+		if utils.IsWhatsAppInviteHost(host) || settings.Mode == services.AntiLinkAll {
+			return host, true
+		}
+	}
+	return "", false
+}
+
+func matchesAnyDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if utils.HostMatchesDomain(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDomain turns user input such as "https://www.YouTube.com/watch" into "youtube.com".
+func normalizeDomain(input string) (string, bool) {
+	domain := strings.ToLower(strings.TrimSpace(input))
+	if i := strings.Index(domain, "://"); i >= 0 {
+		domain = domain[i+3:]
+	}
+	if i := strings.IndexAny(domain, "/:?#"); i >= 0 {
+		domain = domain[:i]
+	}
+	domain = strings.TrimPrefix(domain, "www.")
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", false
+	}
+	return domain, true
+}
+
+// moderationText returns the text or caption of a message, including captions of view-once media.
+// Content filters scan this text.
+func moderationText(evt *events.Message) string {
+	if text := utils.GetTextFromMessage(evt); text != "" {
+		return text
+	}
+	inner := utils.UnwrapViewOnce(evt.Message)
+	if img := inner.GetImageMessage(); img != nil {
+		return img.GetCaption()
+	}
+	if vid := inner.GetVideoMessage(); vid != nil {
+		return vid.GetCaption()
+	}
+	return ""
+}
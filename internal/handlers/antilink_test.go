@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"testing"
+
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+func TestBlockedLinkHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		hosts    []string
+		settings services.AntiLinkSettings
+		want     bool
+	}{
+		{"off ignores invites", []string{"chat.whatsapp.com"}, services.AntiLinkSettings{Mode: services.AntiLinkOff}, false},
+		{"invite mode blocks invites", []string{"chat.whatsapp.com"}, services.AntiLinkSettings{Mode: services.AntiLinkInvite}, true},
+		{"invite mode allows other links", []string{"youtube.com"}, services.AntiLinkSettings{Mode: services.AntiLinkInvite}, false},
+		{"invite mode honors deny list", []string{"m.slot.xyz"}, services.AntiLinkSettings{Mode: services.AntiLinkInvite, Deny: []string{"slot.xyz"}}, true},
+		{"all mode blocks links", []string{"youtube.com"}, services.AntiLinkSettings{Mode: services.AntiLinkAll}, true},
+		{"all mode honors allow list", []string{"www.youtube.com"}, services.AntiLinkSettings{Mode: services.AntiLinkAll, Allow: []string{"youtube.com"}}, false},
+		{"allowed whatsapp.com does not allow invites", []string{"chat.whatsapp.com"}, services.AntiLinkSettings{Mode: services.AntiLinkAll, Allow: []string{"wa.me"}}, true},
+		{"explicitly allowed invites", []string{"chat.whatsapp.com"}, services.AntiLinkSettings{Mode: services.AntiLinkAll, Allow: []string{"chat.whatsapp.com"}}, false},
+		{"one bad link among allowed", []string{"youtube.com", "bit.ly"}, services.AntiLinkSettings{Mode: services.AntiLinkAll, Allow: []string{"youtube.com"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := blockedLinkHost(tt.hosts, tt.settings); got != tt.want {
+				t.Errorf("blockedLinkHost(%v) = %v, want %v", tt.hosts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkScanText(t *testing.T) {
+	urlCommands := map[string]bool{"dl": true, "mp3": true}
+	settings := services.AntiLinkSettings{Mode: services.AntiLinkInvite}
+	tests := []struct {
+		text        string
+		wantBlocked bool
+	}{
+		{".dl https://youtube.com/watch?v=1", false},
+		{".menu https://chat.whatsapp.com/AbCdEf", true},
+		{".s chat.whatsapp.com/AbCdEf", true},
+		{".dl https://youtube.com/x https://chat.whatsapp.com/AbCdEf", true},
+		{".dl https://chat.whatsapp.com/AbCdEf", true},
+		{"join chat.whatsapp.com/AbCdEf", true},
+	}
+	for _, tt := range tests {
+		hosts := utils.ExtractLinkHosts(linkScanText(tt.text, urlCommands))
+		if _, got := blockedLinkHost(hosts, settings); got != tt.wantBlocked {
+			t.Errorf("%q blocked = %v, want %v", tt.text, got, tt.wantBlocked)
+		}
+	}
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{"youtube.com", "youtube.com", true},
+		{"https://www.YouTube.com/watch?v=1", "youtube.com", true},
+		{"chat.whatsapp.com/AbC", "chat.whatsapp.com", true},
+		{"localhost", "", false},
+		{".com", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := normalizeDomain(tt.input)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("normalizeDomain(%q) = %q, %v, want %q, %v", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
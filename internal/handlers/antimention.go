@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+// AntiMentionHandler revokes messages from members that mention too much of the group and handles .antimention.
+type AntiMentionHandler struct {
+	store        *services.MentionStore
+	groupHandler *GroupHandler
+	warnHandler  *WarnHandler
+}
+
+// NewAntiMentionHandler creates a new AntiMentionHandler.
+func NewAntiMentionHandler(store *services.MentionStore, groupHandler *GroupHandler, warnHandler *WarnHandler) *AntiMentionHandler {
+	return &AntiMentionHandler{store: store, groupHandler: groupHandler, warnHandler: warnHandler}
+}
+
+// CheckAndRevoke revokes group messages whose mentions break the group's anti-mass-mention rule.
+// Returns true if the message was revoked, false otherwise.
+func (h *AntiMentionHandler) CheckAndRevoke(client *whatsmeow.Client, evt *events.Message) bool {
+	if !evt.Info.IsGroup || evt.Info.IsFromMe {
+		return false
+	}
+
+	ctxInfo := utils.GetContextInfo(evt.Message)
+	if len(ctxInfo.GetMentionedJID()) == 0 && len(ctxInfo.GetGroupMentions()) == 0 {
+		return false
+	}
+
+	chat := evt.Info.Chat.String()
+	settings := h.store.Settings(chat)
+	if !settings.Enabled {
+		return false
+	}
+
+	members := 0
+	if groupInfo, err := h.groupHandler.GroupInfo(client, evt.Info.Chat); err == nil {
+		members = len(groupInfo.Participants)
+	} else {
+		slog.Error("failed to get group info", "error", err)
+	}
+	mentions := countMentions(ctxInfo, members)
+	if !settings.Exceeded(mentions, members) {
+		return false
+	}
+
+	if h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		return false
+	}
+
+	slog.Info("Mass mention — revoking", "user", evt.Info.Sender.User, "chat", chat, "mentions", mentions, "members", members)
+
+	revokeMsg := client.BuildRevoke(evt.Info.Chat, evt.Info.Sender, evt.Info.ID)
+	if _, err := client.SendMessage(context.Background(), evt.Info.Chat, revokeMsg); err != nil {
+		slog.Error("failed to revoke mass mention", "error", err)
+		return false
+	}
+
+	if settings.Warn {
+		h.warnHandler.Warn(client, evt.Info.Chat, evt.Info.Sender, "", fmt.Sprintf("mention massal (%d member)", mentions))
+	}
+	return true
+}
+
+// countMentions returns how many members a message mentions. Mentioning the group itself
+// notifies every member, so it counts as mentioning all of them.
+func countMentions(ctxInfo *waProto.ContextInfo, members int) int {
+	if len(ctxInfo.GetGroupMentions()) > 0 && members > 0 {
+		return members
+	}
+	seen := make(map[string]bool)
+	for _, jid := range ctxInfo.GetMentionedJID() {
+		seen[jid] = true
+	}
+	return len(seen)
+}
+
+// HandleAntiMention shows or changes the group's anti-mass-mention rule (admin only).
+// Usage: .antimention [on|off] | .antimention max <n> | .antimention percent <n> | .antimention warn on|off
+func (h *AntiMentionHandler) HandleAntiMention(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	settings := h.store.Settings(groupJID)
+	usage := "Contoh:\n.antimention on|off\n.antimention max 10 (0 = tanpa batas jumlah)\n.antimention percent 50 (0 = tanpa batas persentase)\n.antimention warn on|off"
+
+	if len(args) == 0 {
+		utils.ReplyTextDirect(client, evt, describeMentionSettings(settings)+"\n\n"+usage)
+		return
+	}
+
+	var text string
+	sub := strings.ToLower(args[0])
+	switch sub {
+	case "on":
+		settings.Enabled = true
+		text = "Anti-mention massal aktif.\n" + describeMentionLimits(settings)
+	case "off":
+		settings.Enabled = false
+		text = "Anti-mention massal dinonaktifkan."
+	case "max", "percent":
+		n := -1
+		if len(args) > 1 {
+			if val, err := strconv.Atoi(args[1]); err == nil {
+				n = val
+			}
+		}
+		if n < 0 || (sub == "percent" && n > 100) {
+			utils.ReplyTextDirect(client, evt, usage)
+			return
+		}
+		if sub == "max" {
+			settings.MaxMentions = n
+		} else {
+			settings.MaxPercent = n
+		}
+		text = describeMentionLimits(settings)
+	case "warn":
+		if len(args) < 2 || (args[1] != "on" && args[1] != "off") {
+			utils.ReplyTextDirect(client, evt, "Contoh: .antimention warn on|off")
+			return
+		}
+		settings.Warn = args[1] == "on"
+		text = "Pelaku mention massal tidak diberi peringatan."
+		if settings.Warn {
+			text = "Pelaku mention massal juga akan diberi peringatan."
+		}
+	default:
+		utils.ReplyTextDirect(client, evt, usage)
+		return
+	}
+
+	if !h.store.SetSettings(groupJID, settings) {
+		text = "Gagal menyimpan pengaturan anti-mention."
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
+
+// describeMentionSettings summarizes a group's anti-mass-mention rule.
+func describeMentionSettings(s services.MentionSettings) string {
+	if !s.Enabled {
+		return "📣 Anti-mention massal nonaktif di grup ini."
+	}
+	text := "📣 *Anti-Mention Massal*\n\n" + describeMentionLimits(s)
+	if s.Warn {
+		text += "\nPelaku juga diberi peringatan."
+	}
+	return text
+}
+
+// describeMentionLimits describes which mentions are revoked.
+func describeMentionLimits(s services.MentionSettings) string {
+	var limits []string
+	if s.MaxMentions > 0 {
+		limits = append(limits, fmt.Sprintf("lebih dari %d member", s.MaxMentions))
+	}
+	if s.MaxPercent > 0 {
+		limits = append(limits, fmt.Sprintf("lebih dari %d%% member grup", s.MaxPercent))
+	}
+	if len(limits) == 0 {
+		return "Belum ada batas: atur dengan .antimention max <n> atau .antimention percent <n>."
+	}
+	return "Pesan member yang me-mention " + strings.Join(limits, " atau ") + " akan dihapus."
+}
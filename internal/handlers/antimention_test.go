@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"testing"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+)
+
+func TestCountMentions(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctxInfo *waProto.ContextInfo
+		members int
+		want    int
+	}{
+		{"no context", nil, 50, 0},
+		{"duplicates counted once", &waProto.ContextInfo{MentionedJID: []string{"1@s.whatsapp.net", "2@lid", "1@s.whatsapp.net"}}, 50, 2},
+		{"group mention is everyone", &waProto.ContextInfo{GroupMentions: []*waProto.GroupMention{{}}}, 50, 50},
+		{"group mention with unknown size", &waProto.ContextInfo{GroupMentions: []*waProto.GroupMention{{}}}, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := countMentions(tt.ctxInfo, tt.members); got != tt.want {
+			t.Errorf("%s: countMentions() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+// banListPageSize is the number of bans shown per .banlist page.
+const banListPageSize = 10
+
+// BanType describes a ban category: its commands, its wording and which messages it revokes.
+type BanType struct {
+	Category services.BanCategory
+	// Command is the suffix of the ban/unban commands, e.g. "chat" for .banchat and .unbanchat.
+	Command string
+	// Label describes the forbidden action in replies, e.g. "mengirim sticker".
+	Label string
+	// Matches reports whether a message falls under this ban. Nil matches every message.
+	Matches func(msg *waProto.Message) bool
+}
+
+// BanHandler handles auto-deletion of messages from banned users and the ban/unban commands for every ban type.
+type BanHandler struct {
+	store        *services.BanStore
+	groupHandler *GroupHandler
+	types        []BanType
+}
+
+// NewBanHandler creates a new BanHandler with no ban types registered.
+func NewBanHandler(store *services.BanStore, groupHandler *GroupHandler) *BanHandler {
+	return &BanHandler{store: store, groupHandler: groupHandler}
+}
+
+// Register adds a ban type to the moderation chain and registers its .ban<cmd> and .unban<cmd> commands.
+// Types are checked in registration order.
+func (h *BanHandler) Register(registry *Registry, t BanType) {
+	h.types = append(h.types, t)
+	registry.Register("ban"+t.Command, h.banCommand(t))
+	registry.Register("unban"+t.Command, h.unbanCommand(t))
+}
+
+// CheckAndRevoke checks if a message falls under any ban of its sender and revokes it.
+// Returns true if the message was revoked, false otherwise.
+// This should be called for every group message BEFORE command routing.
+func (h *BanHandler) CheckAndRevoke(client *whatsmeow.Client, evt *events.Message) bool {
+	// Only check group messages.
+	if !evt.Info.IsGroup {
+		return false
+	}
+
+	// Skip messages from the bot itself.
+	if evt.Info.IsFromMe {
+		return false
+	}
+
+	banned := h.categories(evt.Info.Chat.String(), h.groupHandler.senderJID(evt), evt.Info.Sender)
+	if len(banned) == 0 {
+		return false
+	}
+
+	for _, t := range h.types {
+		if !banned[t.Category] {
+			continue
+		}
+		if t.Matches != nil && !t.Matches(evt.Message) {
+			continue
+		}
+
+		slog.Info("Banned user sent message — revoking", "category", t.Category, "user", evt.Info.Sender.User, "chat", evt.Info.Chat.String())
+
+		// Revoke immediately
+		revokeMsg := client.BuildRevoke(evt.Info.Chat, evt.Info.Sender, evt.Info.ID)
+		if _, err := client.SendMessage(context.Background(), evt.Info.Chat, revokeMsg); err != nil {
+			slog.Error("failed to revoke banned user's message", "category", t.Category, "error", err)
+			return false
+		}
+		return true
+	}
+
+	return false
+}
+
+// categories returns every category a user is banned in, either globally or in groupJID.
+// Bans are looked up under every known form of each of the given JIDs of the user.
+func (h *BanHandler) categories(groupJID string, users ...types.JID) map[services.BanCategory]bool {
+	banned := make(map[services.BanCategory]bool)
+	seen := make(map[string]bool)
+	for _, user := range users {
+		for _, key := range h.groupHandler.userKeys(user) {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			for category := range h.store.Categories(key, groupJID) {
+				banned[category] = true
+			}
+		}
+	}
+	return banned
+}
+
+// remove lifts a user's ban in a category within scope, under whichever form of their JID it was stored.
+func (h *BanHandler) remove(user types.JID, category services.BanCategory, scope string) bool {
+	removed := false
+	for _, key := range h.groupHandler.userKeys(user) {
+		if h.store.Remove(key, category, scope) {
+			removed = true
+		}
+	}
+	return removed
+}
+
+// NotifyExpired announces in the ban's origin chat that a temporary ban was lifted.
+func (h *BanHandler) NotifyExpired(client *whatsmeow.Client, ban services.Ban) {
+	if ban.OriginChat == "" {
+		return
+	}
+	chatJID, err := types.ParseJID(ban.OriginChat)
+	if err != nil {
+		return
+	}
+	userJID, err := types.ParseJID(ban.JID)
+	if err != nil {
+		return
+	}
+
+	label := h.typeLabel(ban.Category)
+
+	msg := &waProto.Message{
+		ExtendedTextMessage: &waProto.ExtendedTextMessage{
+			Text: proto.String(fmt.Sprintf("Masa larangan %s untuk @%s sudah berakhir.", label, userJID.User)),
+			ContextInfo: &waProto.ContextInfo{
+				MentionedJID: []string{ban.JID},
+			},
+		},
+	}
+	if _, err := client.SendMessage(context.Background(), chatJID, msg); err != nil {
+		slog.Error("failed to announce expired ban", "error", err)
+	}
+}
+
+// banCommand returns the handler that bans a user for the given type (admin only).
+// Bans apply to the current group; owners may add "global" to ban in every group.
+// An optional duration (30m, 2h, 1d, 1w) makes the ban temporary; remaining words are the reason.
+// Usage: reply or tag user with .ban<cmd> @user [durasi] [global] [alasan]
+func (h *BanHandler) banCommand(t BanType) CommandHandler {
+	return func(client *whatsmeow.Client, evt *events.Message, args []string) {
+		if !evt.Info.IsGroup {
+			utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+			return
+		}
+
+		if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+			utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+			return
+		}
+
+		targetJID, found := utils.GetTargetJID(evt)
+		if !found {
+			utils.ReplyTextDirect(client, evt, fmt.Sprintf("Reply pesan atau tag member yang ingin dilarang %s.\nContoh: .ban%s @member [30m|2h|1d] [global] [alasan]", t.Label, t.Command))
+			return
+		}
+
+		// Prevent banning the bot itself.
+		if isBotJID(client, targetJID) {
+			utils.ReplyTextDirect(client, evt, "Tidak bisa ban bot sendiri.")
+			return
+		}
+
+		scope, ok := commandScope(client, evt, args, h.groupHandler)
+		if !ok {
+			return
+		}
+
+		duration, reason := parseBanArgs(args)
+
+		targetStr := targetJID.ToNonAD().String()
+		ban := services.Ban{
+			JID:        h.groupHandler.userKey(targetJID),
+			Category:   t.Category,
+			Scope:      scope,
+			OriginChat: evt.Info.Chat.String(),
+			Issuer:     h.groupHandler.senderJID(evt).String(),
+			Reason:     reason,
+		}
+
+		durationText := ""
+		if duration > 0 {
+			ban.ExpiresAt = time.Now().Add(duration)
+			durationText = " selama " + utils.FormatDuration(duration)
+		}
+
+		where, list := scopeText(scope)
+		mentionText := fmt.Sprintf("@%s sekarang dilarang %s %s%s.", targetJID.ToNonAD().User, t.Label, where, durationText)
+		if reason != "" {
+			mentionText += "\nAlasan: " + reason
+		}
+		if !h.store.Add(ban) {
+			mentionText = fmt.Sprintf("@%s sudah ada di daftar larangan %s %s.", targetJID.ToNonAD().User, t.Label, list)
+			if durationText != "" {
+				mentionText = fmt.Sprintf("@%s sudah ada di daftar larangan %s %s. Durasi diperbarui menjadi%s.", targetJID.ToNonAD().User, t.Label, list, durationText)
+			}
+		}
+		utils.ReplyTextDirectWithMentions(client, evt, mentionText, []string{targetStr})
+	}
+}
+
+// unbanCommand returns the handler that lifts a user's ban for the given type (admin only).
+// Lifts the ban in the current group; owners may add "global" to lift a global ban.
+// Usage: reply or tag user with .unban<cmd> @user [global]
+func (h *BanHandler) unbanCommand(t BanType) CommandHandler {
+	return func(client *whatsmeow.Client, evt *events.Message, args []string) {
+		if !evt.Info.IsGroup {
+			utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+			return
+		}
+
+		if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+			utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+			return
+		}
+
+		targetJID, found := utils.GetTargetJID(evt)
+		if !found {
+			utils.ReplyTextDirect(client, evt, fmt.Sprintf("Reply pesan atau tag member yang ingin diizinkan %s lagi.\nContoh: .unban%s @member [global]", t.Label, t.Command))
+			return
+		}
+
+		scope, ok := commandScope(client, evt, args, h.groupHandler)
+		if !ok {
+			return
+		}
+
+		where, list := scopeText(scope)
+		targetStr := targetJID.ToNonAD().String()
+		mentionText := fmt.Sprintf("@%s sekarang diizinkan %s kembali %s.", targetJID.ToNonAD().User, t.Label, where)
+		if !h.remove(targetJID, t.Category, scope) {
+			mentionText = fmt.Sprintf("@%s tidak ada di daftar larangan %s %s.", targetJID.ToNonAD().User, t.Label, list)
+			if scope != services.BanScopeGlobal && h.categories("", targetJID)[t.Category] {
+				mentionText += " Larangan global hanya bisa dicabut oleh owner dengan .unban" + t.Command + " @member global."
+			}
+		}
+		utils.ReplyTextDirectWithMentions(client, evt, mentionText, []string{targetStr})
+	}
+}
+
+// HandleBanList lists the bans that apply in the current group, optionally filtered by type (admin only).
+// Usage: .banlist [chat|img|sticker|...] [halaman]
+func (h *BanHandler) HandleBanList(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	var category services.BanCategory
+	page := 1
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil {
+			page = n
+			continue
+		}
+		t, ok := h.lookupType(arg)
+		if !ok {
+			utils.ReplyTextDirect(client, evt, "Jenis larangan tidak dikenal. Pilih: "+h.typeNames())
+			return
+		}
+		category = t.Category
+	}
+
+	bans := h.store.List(evt.Info.Chat.String(), category)
+	if len(bans) == 0 {
+		utils.ReplyTextDirect(client, evt, "Tidak ada larangan aktif di grup ini.")
+		return
+	}
+
+	totalPages := (len(bans) + banListPageSize - 1) / banListPageSize
+	if page < 1 {
+		page = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+	start := (page - 1) * banListPageSize
+	end := start + banListPageSize
+	if end > len(bans) {
+		end = len(bans)
+	}
+
+	var sb strings.Builder
+	var mentions []string
+	sb.WriteString(fmt.Sprintf("📋 *Daftar Larangan* (halaman %d/%d)\n", page, totalPages))
+	for i, ban := range bans[start:end] {
+		userJID, err := types.ParseJID(ban.JID)
+		if err != nil {
+			continue
+		}
+		_, list := scopeText(ban.Scope)
+		sb.WriteString(fmt.Sprintf("\n%d. @%s — %s (%s)", start+i+1, userJID.User, h.typeLabel(ban.Category), list))
+		mentions = append(mentions, ban.JID)
+
+		if ban.Issuer != "" {
+			issuer := ban.Issuer
+			if issuerJID, err := types.ParseJID(ban.Issuer); err == nil {
+				issuer = issuerJID.User
+			}
+			sb.WriteString("\n   Oleh: " + issuer)
+		}
+		if !ban.CreatedAt.IsZero() {
+			sb.WriteString("\n   Sejak: " + ban.CreatedAt.Format("02/01/2006 15:04"))
+		}
+		if ban.ExpiresAt.IsZero() {
+			sb.WriteString("\n   Sampai: permanen")
+		} else {
+			sb.WriteString("\n   Sampai: " + ban.ExpiresAt.Format("02/01/2006 15:04"))
+		}
+		if ban.Reason != "" {
+			sb.WriteString("\n   Alasan: " + ban.Reason)
+		}
+	}
+	if page < totalPages {
+		sb.WriteString(fmt.Sprintf("\n\nHalaman berikutnya: .banlist %d", page+1))
+	}
+
+	utils.ReplyTextDirectWithMentions(client, evt, sb.String(), mentions)
+}
+
+// lookupType finds a registered ban type by its command suffix or category name.
+func (h *BanHandler) lookupType(name string) (BanType, bool) {
+	name = strings.ToLower(name)
+	for _, t := range h.types {
+		if t.Command == name || string(t.Category) == name {
+			return t, true
+		}
+	}
+	return BanType{}, false
+}
+
+// typeLabel returns the user-facing label of a category, falling back to its name.
+func (h *BanHandler) typeLabel(category services.BanCategory) string {
+	for _, t := range h.types {
+		if t.Category == category {
+			return t.Label
+		}
+	}
+	return string(category)
+}
+
+// typeNames lists the command suffixes of all registered ban types.
+func (h *BanHandler) typeNames() string {
+	names := make([]string, 0, len(h.types))
+	for _, t := range h.types {
+		names = append(names, t.Command)
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseBanArgs extracts the optional duration and the free-text reason from ban command arguments.
+// The duration is only read from the first argument after the mentions, so durations
+// inside the reason stay part of it. Mentions and the "global" keyword are not part of the reason.
+func parseBanArgs(args []string) (time.Duration, string) {
+	var duration time.Duration
+	var reason []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "@") || strings.ToLower(arg) == services.BanScopeGlobal {
+			continue
+		}
+		if len(reason) == 0 && duration == 0 {
+			if d, ok := utils.ParseDuration(arg); ok {
+				duration = d
+				continue
+			}
+		}
+		reason = append(reason, arg)
+	}
+	return duration, strings.Join(reason, " ")
+}
+
+// commandScope resolves the scope of a ban or blocklist command: the current group, or global if
+// requested by an owner. Replies and returns false if a non-owner asks for a global scope.
+func commandScope(client *whatsmeow.Client, evt *events.Message, args []string, groupHandler *GroupHandler) (string, bool) {
+	for _, arg := range args {
+		if strings.ToLower(arg) != services.BanScopeGlobal {
+			continue
+		}
+		if !groupHandler.IsOwner(evt.Info.Sender) {
+			utils.ReplyTextDirect(client, evt, "Hanya owner bot yang bisa mengatur larangan global.")
+			return "", false
+		}
+		return services.BanScopeGlobal, true
+	}
+	return evt.Info.Chat.String(), true
+}
+
+// scopeText returns how a scope is described in replies: where the ban applies, and which list it is on.
+func scopeText(scope string) (string, string) {
+	if scope == services.BanScopeGlobal {
+		return "di semua grup", "global"
+	}
+	return "di grup ini", "grup ini"
+}
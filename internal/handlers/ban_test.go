@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBanArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantDuration time.Duration
+		wantReason   string
+	}{
+		{"no args", nil, 0, ""},
+		{"mention only", []string{"@628123"}, 0, ""},
+		{"duration", []string{"@628123", "2h"}, 2 * time.Hour, ""},
+		{"reason", []string{"@628123", "spamming", "promo"}, 0, "spamming promo"},
+		{"duration and reason", []string{"@628123", "1d", "spamming", "promo"}, 24 * time.Hour, "spamming promo"},
+		{"global keyword skipped", []string{"@628123", "global", "scam"}, 0, "scam"},
+		{"second duration kept in reason", []string{"30m", "repeat", "2h"}, 30 * time.Minute, "repeat 2h"},
+		{"duration inside reason", []string{"@628123", "spam", "10m", "later"}, 0, "spam 10m later"},
+		{"duration after global", []string{"@628123", "global", "1w", "scam"}, 7 * 24 * time.Hour, "scam"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duration, reason := parseBanArgs(tt.args)
+			if duration != tt.wantDuration {
+				t.Errorf("duration = %v, want %v", duration, tt.wantDuration)
+			}
+			if reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
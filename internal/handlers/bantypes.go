@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"strings"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+// Built-in ban types. They are checked by the moderation chain in the order they are registered.
+var (
+	ChatBanType = BanType{
+		Category: services.BanChat,
+		Command:  "chat",
+		Label:    "mengirim chat",
+	}
+	StickerBanType = BanType{
+		Category: services.BanSticker,
+		Command:  "sticker",
+		Label:    "mengirim sticker",
+		Matches:  isStickerBanMedia,
+	}
+	ImageBanType = BanType{
+		Category: services.BanImage,
+		Command:  "img",
+		Label:    "mengirim gambar/video/GIF",
+		Matches:  isImageBanMedia,
+	}
+	AudioBanType = BanType{
+		Category: services.BanAudio,
+		Command:  "audio",
+		Label:    "mengirim voice note/audio",
+		Matches:  isAudioBanMedia,
+	}
+	ContactBanType = BanType{
+		Category: services.BanContact,
+		Command:  "contact",
+		Label:    "mengirim kontak",
+		Matches:  isContactBanMedia,
+	}
+	LocationBanType = BanType{
+		Category: services.BanLocation,
+		Command:  "location",
+		Label:    "mengirim lokasi",
+		Matches:  isLocationBanMedia,
+	}
+	PollBanType = BanType{
+		Category: services.BanPoll,
+		Command:  "poll",
+		Label:    "membuat polling",
+		Matches:  isPollBanMedia,
+	}
+)
+
+// DocumentBanType bans documents. If filters is non-empty, only documents matching one of them are revoked:
+// entries starting with "." match file extensions, entries containing "/" match mimetypes
+// (an entry ending in "/" or "*" matches a mimetype prefix, e.g. "application/vnd.android*").
+func DocumentBanType(filters []string) BanType {
+	return BanType{
+		Category: services.BanDocument,
+		Command:  "doc",
+		Label:    "mengirim dokumen",
+		Matches:  documentBanMatcher(filters),
+	}
+}
+
+func isAudioBanMedia(msg *waProto.Message) bool {
+	return utils.UnwrapViewOnce(msg).GetAudioMessage() != nil
+}
+
+func isContactBanMedia(msg *waProto.Message) bool {
+	return msg.GetContactMessage() != nil || msg.GetContactsArrayMessage() != nil
+}
+
+func isLocationBanMedia(msg *waProto.Message) bool {
+	return msg.GetLocationMessage() != nil || msg.GetLiveLocationMessage() != nil
+}
+
+func isPollBanMedia(msg *waProto.Message) bool {
+	return msg.GetPollCreationMessage() != nil ||
+		msg.GetPollCreationMessageV2() != nil ||
+		msg.GetPollCreationMessageV3() != nil ||
+		msg.GetPollCreationMessageV4() != nil ||
+		msg.GetPollCreationMessageV5() != nil ||
+		msg.GetPollCreationMessageV6() != nil
+}
+
+// documentBanMatcher matches documents, including captioned ones, against the extension/mimetype filters.
+func documentBanMatcher(filters []string) func(msg *waProto.Message) bool {
+	var extensions, mimetypes, prefixes []string
+	for _, f := range filters {
+		f = strings.ToLower(strings.TrimSpace(f))
+		switch {
+		case strings.HasPrefix(f, "."):
+			extensions = append(extensions, f)
+		case strings.HasSuffix(f, "*") || strings.HasSuffix(f, "/"):
+			prefixes = append(prefixes, strings.TrimSuffix(f, "*"))
+		case strings.Contains(f, "/"):
+			mimetypes = append(mimetypes, f)
+		}
+	}
+	matchAll := len(extensions)+len(mimetypes)+len(prefixes) == 0
+
+	return func(msg *waProto.Message) bool {
+		doc := msg.GetDocumentMessage()
+		if doc == nil {
+			doc = msg.GetDocumentWithCaptionMessage().GetMessage().GetDocumentMessage()
+		}
+		if doc == nil {
+			return false
+		}
+		if matchAll {
+			return true
+		}
+
+		mimetype := strings.ToLower(strings.TrimSpace(doc.GetMimetype()))
+		filename := strings.ToLower(strings.TrimSpace(doc.GetFileName()))
+		for _, ext := range extensions {
+			if strings.HasSuffix(filename, ext) {
+				return true
+			}
+		}
+		for _, m := range mimetypes {
+			if mimetype == m {
+				return true
+			}
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(mimetype, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func isStickerBanMedia(msg *waProto.Message) bool {
+	return msg.GetStickerMessage() != nil
+}
+
+func isImageBanMedia(msg *waProto.Message) bool {
+	if msg == nil {
+		return false
+	}
+
+	msg = utils.UnwrapViewOnce(msg)
+	if msg.GetImageMessage() != nil || msg.GetVideoMessage() != nil {
+		return true
+	}
+
+	doc := msg.GetDocumentMessage()
+	if doc == nil {
+		return false
+	}
+
+	mimetype := strings.ToLower(strings.TrimSpace(doc.GetMimetype()))
+	filename := strings.ToLower(strings.TrimSpace(doc.GetFileName()))
+
+	if strings.HasPrefix(mimetype, "video/") || mimetype == "image/gif" || strings.Contains(mimetype, "gif") {
+		return true
+	}
+
+	videoExts := []string{".gif", ".mp4", ".mov", ".mkv", ".webm", ".avi", ".3gp", ".m4v"}
+	for _, ext := range videoExts {
+		if strings.HasSuffix(filename, ext) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"testing"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestIsImageBanMedia(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *waProto.Message
+		want bool
+	}{
+		{
+			name: "image message",
+			msg:  &waProto.Message{ImageMessage: &waProto.ImageMessage{}},
+			want: true,
+		},
+		{
+			name: "video message",
+			msg:  &waProto.Message{VideoMessage: &waProto.VideoMessage{}},
+			want: true,
+		},
+		{
+			name: "gif document by mimetype",
+			msg: &waProto.Message{DocumentMessage: &waProto.DocumentMessage{
+				Mimetype: proto.String("image/gif"),
+			}},
+			want: true,
+		},
+		{
+			name: "video document by mimetype",
+			msg: &waProto.Message{DocumentMessage: &waProto.DocumentMessage{
+				Mimetype: proto.String("video/mp4"),
+			}},
+			want: true,
+		},
+		{
+			name: "gif document by filename",
+			msg: &waProto.Message{DocumentMessage: &waProto.DocumentMessage{
+				Mimetype: proto.String("application/octet-stream"),
+				FileName: proto.String("reaction.gif"),
+			}},
+			want: true,
+		},
+		{
+			name: "video document by filename",
+			msg: &waProto.Message{DocumentMessage: &waProto.DocumentMessage{
+				Mimetype: proto.String("application/octet-stream"),
+				FileName: proto.String("clip.webm"),
+			}},
+			want: true,
+		},
+		{
+			name: "non-media document",
+			msg: &waProto.Message{DocumentMessage: &waProto.DocumentMessage{
+				Mimetype: proto.String("application/pdf"),
+				FileName: proto.String("notes.pdf"),
+			}},
+			want: false,
+		},
+		{
+			name: "text message",
+			msg:  &waProto.Message{Conversation: proto.String("hello")},
+			want: false,
+		},
+		{
+			name: "nil message",
+			msg:  nil,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isImageBanMedia(tt.msg); got != tt.want {
+				t.Fatalf("isImageBanMedia() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBanTypeMatchers(t *testing.T) {
+	pdf := &waProto.Message{DocumentMessage: &waProto.DocumentMessage{
+		Mimetype: proto.String("application/pdf"),
+		FileName: proto.String("notes.pdf"),
+	}}
+	apk := &waProto.Message{DocumentWithCaptionMessage: &waProto.FutureProofMessage{
+		Message: &waProto.Message{DocumentMessage: &waProto.DocumentMessage{
+			Mimetype: proto.String("application/vnd.android.package-archive"),
+			FileName: proto.String("game.apk"),
+		}},
+	}}
+	exe := &waProto.Message{DocumentMessage: &waProto.DocumentMessage{
+		Mimetype: proto.String("application/octet-stream"),
+		FileName: proto.String("Setup.EXE"),
+	}}
+	text := &waProto.Message{Conversation: proto.String("halo")}
+
+	anyDocument := documentBanMatcher(nil)
+	filtered := documentBanMatcher([]string{".exe", "application/vnd.android*", "application/zip"})
+
+	tests := []struct {
+		name    string
+		matches func(*waProto.Message) bool
+		msg     *waProto.Message
+		want    bool
+	}{
+		{"voice note", isAudioBanMedia, &waProto.Message{AudioMessage: &waProto.AudioMessage{PTT: proto.Bool(true)}}, true},
+		{"audio file", isAudioBanMedia, &waProto.Message{AudioMessage: &waProto.AudioMessage{}}, true},
+		{"text is not audio", isAudioBanMedia, text, false},
+		{"contact card", isContactBanMedia, &waProto.Message{ContactMessage: &waProto.ContactMessage{}}, true},
+		{"contact list", isContactBanMedia, &waProto.Message{ContactsArrayMessage: &waProto.ContactsArrayMessage{}}, true},
+		{"location", isLocationBanMedia, &waProto.Message{LocationMessage: &waProto.LocationMessage{}}, true},
+		{"live location", isLocationBanMedia, &waProto.Message{LiveLocationMessage: &waProto.LiveLocationMessage{}}, true},
+		{"poll", isPollBanMedia, &waProto.Message{PollCreationMessageV3: &waProto.PollCreationMessage{}}, true},
+		{"text is not a poll", isPollBanMedia, text, false},
+		{"any document without filters", anyDocument, pdf, true},
+		{"captioned document without filters", anyDocument, apk, true},
+		{"text is not a document", anyDocument, text, false},
+		{"filtered mimetype prefix", filtered, apk, true},
+		{"filtered extension, case-insensitive", filtered, exe, true},
+		{"document outside filters", filtered, pdf, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.matches(tt.msg); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+const maxBlacklistPrefixLen = 6
+
+// BlacklistHandler kicks blacklisted users when they join and handles .blacklist and .sweep.
+type BlacklistHandler struct {
+	store        *services.Blacklist
+	groupHandler *GroupHandler
+}
+
+// NewBlacklistHandler creates a new BlacklistHandler.
+func NewBlacklistHandler(store *services.Blacklist, groupHandler *GroupHandler) *BlacklistHandler {
+	return &BlacklistHandler{store: store, groupHandler: groupHandler}
+}
+
+// OnJoin removes a blacklisted member right after they join and tells the group admins.
+func (h *BlacklistHandler) OnJoin(client *whatsmeow.Client, evt *events.GroupInfo, member types.JID) bool {
+	if isBotJID(client, member) || h.groupHandler.IsOwner(member) || h.groupHandler.IsException(member) {
+		return true
+	}
+	entry, ok := h.store.Match(evt.JID.String(), h.memberForms(client, evt.JID, member))
+	if !ok {
+		return true
+	}
+
+	slog.Info("Blacklisted user joined — kicking", "user", member.String(), "pattern", entry.Pattern, "group", evt.JID.String())
+	results, err := client.UpdateGroupParticipants(context.Background(), evt.JID, []types.JID{member}, whatsmeow.ParticipantChangeRemove)
+	if err == nil && len(results) > 0 && results[0].Error != 0 {
+		err = fmt.Errorf("error code %d", results[0].Error)
+	}
+	if err != nil {
+		slog.Error("failed to kick blacklisted user", "error", err)
+		h.notifyAdmins(client, evt.JID, member, fmt.Sprintf("⚠️ @%s masuk blacklist (%s), tetapi gagal dikeluarkan. %s",
+			member.User, blacklistLabel(entry.Pattern), config.MsgBotNotAdmin))
+		return true
+	}
+
+	h.notifyAdmins(client, evt.JID, member, fmt.Sprintf("🚫 @%s masuk blacklist (%s) dan langsung dikeluarkan.", member.User, blacklistLabel(entry.Pattern)))
+	return false
+}
+
+// memberForms returns every known form of a member who just joined. WhatsApp may send the
+// join under the member's LID without a phone number mapping yet, so the phone number and
+// LID from the group's participant list are added as well.
+func (h *BlacklistHandler) memberForms(client *whatsmeow.Client, chat, member types.JID) []types.JID {
+	groupInfo, err := h.groupHandler.GroupInfo(client, chat)
+	if err != nil {
+		slog.Error("failed to get group info", "error", err)
+		return h.groupHandler.identity.Forms(member)
+	}
+	p, found := h.groupHandler.findParticipant(groupInfo, member)
+	if !found {
+		return h.groupHandler.identity.Forms(member)
+	}
+	return h.participantForms(p)
+}
+
+// participantForms returns every known form of a group participant.
+func (h *BlacklistHandler) participantForms(p types.GroupParticipant) []types.JID {
+	forms := h.groupHandler.identity.Forms(p.JID)
+	for _, alt := range []types.JID{p.PhoneNumber, p.LID} {
+		if !alt.IsEmpty() {
+			forms = append(forms, alt)
+		}
+	}
+	return forms
+}
+
+// notifyAdmins posts text about member in the group, mentioning the group admins.
+func (h *BlacklistHandler) notifyAdmins(client *whatsmeow.Client, chat, member types.JID, text string) {
+	mentions := []string{member.String()}
+	if groupInfo, err := h.groupHandler.GroupInfo(client, chat); err == nil {
+		var admins []string
+		for _, p := range groupInfo.Participants {
+			if (p.IsAdmin || p.IsSuperAdmin) && !isBotJID(client, p.JID) && !isBotJID(client, p.LID) {
+				admins = append(admins, "@"+p.JID.User)
+				mentions = append(mentions, p.JID.String())
+			}
+		}
+		if len(admins) > 0 {
+			text += "\n\n👮 " + strings.Join(admins, " ")
+		}
+	}
+	h.groupHandler.sendGroupMention(client, chat, text, mentions)
+}
+
+// HandleBlacklist manages the blacklist (admin only). Entries apply to the current group;
+// owners may add "global" to apply them in every group.
+// Usage: .blacklist add|remove <nomor|@user|kode*> [global] | .blacklist list
+func (h *BlacklistHandler) HandleBlacklist(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	usage := "Contoh:\n.blacklist add 628123456789\n.blacklist add @member\n.blacklist add +1* global (semua nomor +1)\n.blacklist remove 628123456789\n.blacklist list"
+	if len(args) == 0 {
+		utils.ReplyTextDirect(client, evt, usage)
+		return
+	}
+
+	sub := strings.ToLower(args[0])
+	if sub == "list" {
+		h.list(client, evt)
+		return
+	}
+	if sub != "add" && sub != "remove" {
+		utils.ReplyTextDirect(client, evt, usage)
+		return
+	}
+
+	target, ok := h.target(evt, args[1:])
+	if !ok {
+		utils.ReplyTextDirect(client, evt, usage)
+		return
+	}
+	if !target.jid.IsEmpty() && isBotJID(client, target.jid) {
+		utils.ReplyTextDirect(client, evt, "Tidak bisa memasukkan bot sendiri ke blacklist.")
+		return
+	}
+
+	scope, ok := commandScope(client, evt, args[1:], h.groupHandler)
+	if !ok {
+		return
+	}
+	where, list := scopeText(scope)
+
+	if sub == "add" {
+		pattern := target.prefix + services.BlacklistPrefixSuffix
+		if target.prefix == "" {
+			pattern = h.groupHandler.userKey(target.jid)
+		}
+		label := blacklistLabel(pattern)
+		if !h.store.Add(services.BlacklistEntry{Pattern: pattern, Scope: scope, Issuer: h.groupHandler.senderJID(evt).String()}) {
+			utils.ReplyTextDirect(client, evt, fmt.Sprintf("%s sudah ada di blacklist %s.", label, list))
+			return
+		}
+		slog.Info("Blacklist entry added", "pattern", pattern, "scope", scope, "issuer", evt.Info.Sender.User)
+		utils.ReplyTextDirect(client, evt, fmt.Sprintf("🚫 %s masuk blacklist %s dan akan langsung dikeluarkan saat bergabung.\nMember yang sudah ada di grup bisa dikeluarkan dengan .sweep", label, where))
+		return
+	}
+
+	patterns := []string{target.prefix + services.BlacklistPrefixSuffix}
+	if target.prefix == "" {
+		patterns = h.groupHandler.userKeys(target.jid)
+	}
+	removed := false
+	for _, pattern := range patterns {
+		if h.store.Remove(pattern, scope) {
+			removed = true
+		}
+	}
+	label := blacklistLabel(patterns[0])
+	if !removed {
+		utils.ReplyTextDirect(client, evt, fmt.Sprintf("%s tidak ada di blacklist %s.", label, list))
+		return
+	}
+	slog.Info("Blacklist entry removed", "pattern", patterns[0], "scope", scope, "issuer", evt.Info.Sender.User)
+	utils.ReplyTextDirect(client, evt, fmt.Sprintf("✅ %s dihapus dari blacklist %s.", label, list))
+}
+
+// list shows the blacklist entries that apply in the group.
+func (h *BlacklistHandler) list(client *whatsmeow.Client, evt *events.Message) {
+	entries := h.store.List(evt.Info.Chat.String())
+	if len(entries) == 0 {
+		utils.ReplyTextDirect(client, evt, "Blacklist grup ini kosong.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🚫 *Blacklist* (%d)\n", len(entries)))
+	for i, e := range entries {
+		sb.WriteString(fmt.Sprintf("\n%d. %s", i+1, blacklistLabel(e.Pattern)))
+		if e.Scope == services.BanScopeGlobal {
+			sb.WriteString(" _(global)_")
+		}
+	}
+	utils.ReplyTextDirect(client, evt, sb.String())
+}
+
+// HandleSweep kicks the members of the group who are on the blacklist (admin only).
+// Usage: .sweep
+func (h *BlacklistHandler) HandleSweep(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupInfo, err := h.groupHandler.GroupInfo(client, evt.Info.Chat)
+	if err != nil {
+		slog.Error("failed to get group info", "error", err)
+		utils.ReplyTextDirect(client, evt, "Gagal mengambil info grup.")
+		return
+	}
+	if !isBotAdmin(client, groupInfo) {
+		utils.ReplyTextDirect(client, evt, config.MsgBotNotAdmin)
+		return
+	}
+
+	entries := h.store.List(evt.Info.Chat.String())
+	var targets []types.JID
+	for _, p := range groupInfo.Participants {
+		if p.IsAdmin || p.IsSuperAdmin || isBotJID(client, p.JID) || h.groupHandler.IsOwner(p.JID) || h.groupHandler.IsException(p.JID) {
+			continue
+		}
+		forms := h.participantForms(p)
+		for _, entry := range entries {
+			if entry.Matches(forms) {
+				targets = append(targets, p.JID)
+				break
+			}
+		}
+	}
+	if len(targets) == 0 {
+		utils.ReplyTextDirect(client, evt, "Tidak ada member blacklist di grup ini.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🧹 *Hasil sweep* (%d member blacklist)\n", len(targets)))
+	h.groupHandler.writeParticipantResults(&sb, client, evt.Info.Chat, targets, whatsmeow.ParticipantChangeRemove)
+	utils.ReplyTextDirectWithMentions(client, evt, sb.String(), jidStrings(targets))
+}
+
+// blacklistTarget is what a .blacklist command refers to: a single user or a phone number prefix.
+type blacklistTarget struct {
+	jid    types.JID
+	prefix string // country code digits, empty for a single user
+}
+
+// target reads the target of a .blacklist add/remove from a mention or reply, or else from the arguments.
+func (h *BlacklistHandler) target(evt *events.Message, args []string) (blacklistTarget, bool) {
+	if jid, found := utils.GetTargetJID(evt); found {
+		return blacklistTarget{jid: jid.ToNonAD()}, true
+	}
+	for _, arg := range args {
+		if strings.ToLower(arg) == services.BanScopeGlobal {
+			continue
+		}
+		return parseBlacklistArg(arg)
+	}
+	return blacklistTarget{}, false
+}
+
+// parseBlacklistArg parses a phone number, or country code digits followed by "*" for a prefix rule.
+func parseBlacklistArg(arg string) (blacklistTarget, bool) {
+	if prefix, ok := strings.CutSuffix(arg, services.BlacklistPrefixSuffix); ok {
+		prefix = strings.TrimPrefix(prefix, "+")
+		if prefix == "" || len(prefix) > maxBlacklistPrefixLen || strings.Trim(prefix, "0123456789") != "" || prefix[0] == '0' {
+			return blacklistTarget{}, false
+		}
+		return blacklistTarget{prefix: prefix}, true
+	}
+
+	number, ok := parsePhoneNumber(arg)
+	if !ok {
+		return blacklistTarget{}, false
+	}
+	return blacklistTarget{jid: types.NewJID(number, types.DefaultUserServer)}, true
+}
+
+// blacklistLabel shows a blacklist pattern the way users write it: +number, +code* or id@lid.
+func blacklistLabel(pattern string) string {
+	if strings.HasSuffix(pattern, services.BlacklistPrefixSuffix) {
+		return "+" + pattern
+	}
+	if number, ok := strings.CutSuffix(pattern, "@"+types.DefaultUserServer); ok {
+		return "+" + number
+	}
+	return pattern
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"chisa_bot/internal/services"
+)
+
+func TestParseBlacklistArg(t *testing.T) {
+	tests := []struct {
+		in         string
+		wantJID    string
+		wantPrefix string
+		wantOK     bool
+	}{
+		{"628123456789", "628123456789@s.whatsapp.net", "", true},
+		{"08123456789", "628123456789@s.whatsapp.net", "", true},
+		{"+1*", "", "1", true},
+		{"62*", "", "62", true},
+		{"+1800*", "", "1800", true},
+		{"*", "", "", false},
+		{"0*", "", "", false},
+		{"1a*", "", "", false},
+		{"1234567*", "", "", false},
+		{"12345", "", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseBlacklistArg(tt.in)
+		if ok != tt.wantOK {
+			t.Errorf("parseBlacklistArg(%q) ok = %v, want %v", tt.in, ok, tt.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if jid := got.jid.String(); got.prefix != tt.wantPrefix || (tt.wantJID != "" && jid != tt.wantJID) || (tt.wantJID == "" && !got.jid.IsEmpty()) {
+			t.Errorf("parseBlacklistArg(%q) = %s/%q, want %s/%q", tt.in, jid, got.prefix, tt.wantJID, tt.wantPrefix)
+		}
+	}
+}
+
+func TestBlacklistLabel(t *testing.T) {
+	tests := map[string]string{
+		"628123@s.whatsapp.net": "+628123",
+		"1*":                    "+1*",
+		"111@lid":               "111@lid",
+	}
+	for pattern, want := range tests {
+		if got := blacklistLabel(pattern); got != want {
+			t.Errorf("blacklistLabel(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestBlacklistMemberFormsUsesParticipantList(t *testing.T) {
+	cache := services.NewGroupCache(time.Minute)
+	h := NewBlacklistHandler(services.NewBlacklist(newTestDB(t)), NewGroupHandler(cache, services.NewIdentity(nil)))
+
+	group := types.NewJID("123", types.GroupServer)
+	lid := types.NewJID("222", types.HiddenUserServer)
+	pn := types.NewJID("628222", types.DefaultUserServer)
+	cache.Set(&types.GroupInfo{JID: group, Participants: []types.GroupParticipant{{JID: lid, LID: lid, PhoneNumber: pn}}})
+	h.store.Add(services.BlacklistEntry{Pattern: pn.String(), Scope: group.String()})
+
+	// The join arrives under the LID, which the identity layer cannot map yet.
+	if _, ok := h.store.Match(group.String(), h.groupHandler.identity.Forms(lid)); ok {
+		t.Fatal("LID alone should not match a phone number entry")
+	}
+	if _, ok := h.store.Match(group.String(), h.memberForms(nil, group, lid)); !ok {
+		t.Error("member should match through the phone number in the participant list")
+	}
+
+	// Members missing from the participant list fall back to their own forms.
+	other := types.NewJID("333", types.HiddenUserServer)
+	if forms := h.memberForms(nil, group, other); len(forms) != 1 || forms[0] != other {
+		t.Errorf("memberForms() = %v, want only %v", forms, other)
+	}
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+// captchaQuestion is a small arithmetic problem posed to new members.
+type captchaQuestion struct {
+	a, b int
+	op   rune // '+', '-' or '×'
+}
+
+// newCaptchaQuestion picks a random question with a small, non-negative answer.
+func newCaptchaQuestion() captchaQuestion {
+	switch rand.IntN(3) {
+	case 0:
+		return captchaQuestion{a: rand.IntN(20) + 1, b: rand.IntN(20) + 1, op: '+'}
+	case 1:
+		a := rand.IntN(20) + 5
+		return captchaQuestion{a: a, b: rand.IntN(a) + 1, op: '-'}
+	default:
+		return captchaQuestion{a: rand.IntN(9) + 2, b: rand.IntN(9) + 2, op: '×'}
+	}
+}
+
+func (q captchaQuestion) String() string {
+	return fmt.Sprintf("%d %c %d", q.a, q.op, q.b)
+}
+
+func (q captchaQuestion) answer() string {
+	switch q.op {
+	case '+':
+		return strconv.Itoa(q.a + q.b)
+	case '-':
+		return strconv.Itoa(q.a - q.b)
+	default:
+		return strconv.Itoa(q.a * q.b)
+	}
+}
+
+// CaptchaHandler verifies new members with a question they must answer before they can chat.
+type CaptchaHandler struct {
+	store        *services.CaptchaStore
+	groupHandler *GroupHandler
+}
+
+// NewCaptchaHandler creates a new CaptchaHandler.
+func NewCaptchaHandler(store *services.CaptchaStore, groupHandler *GroupHandler) *CaptchaHandler {
+	return &CaptchaHandler{store: store, groupHandler: groupHandler}
+}
+
+// OnJoin challenges a member who joined a group with verification enabled.
+// Members added by someone else (an admin) are trusted and not challenged.
+func (h *CaptchaHandler) OnJoin(client *whatsmeow.Client, evt *events.GroupInfo, member types.JID) bool {
+	settings := h.store.Settings(evt.JID.String())
+	if !settings.Enabled {
+		return true
+	}
+	if h.addedByOther(evt, member) {
+		return true
+	}
+	if isBotJID(client, member) {
+		return true
+	}
+	if h.groupHandler.IsOwner(member) || h.groupHandler.IsException(member) {
+		return true
+	}
+
+	q := newCaptchaQuestion()
+	text := fmt.Sprintf("🔐 Halo @%s, jawab pertanyaan ini dalam %s agar bisa chat di grup:\n\n*Berapa %s?*\n\nBalas dengan angka saja. Jika tidak dijawab, kamu akan dikeluarkan dari grup.",
+		member.User, utils.FormatDuration(settings.Timeout), q)
+	msg := &waProto.Message{
+		ExtendedTextMessage: &waProto.ExtendedTextMessage{
+			Text: proto.String(text),
+			ContextInfo: &waProto.ContextInfo{
+				MentionedJID: []string{member.String()},
+			},
+		},
+	}
+	resp, err := client.SendMessage(context.Background(), evt.JID, msg)
+	if err != nil {
+		slog.Error("failed to send captcha challenge", "error", err)
+		return true
+	}
+
+	h.store.Add(services.CaptchaChallenge{
+		GroupJID:  evt.JID.String(),
+		JID:       h.groupHandler.userKey(member.ToNonAD()),
+		Answer:    q.answer(),
+		MessageID: resp.ID,
+		ExpiresAt: time.Now().Add(settings.Timeout),
+	})
+	slog.Info("Captcha challenge sent", "user", member.User, "group", evt.JID.String())
+	return true
+}
+
+// addedByOther reports whether member was added by someone else rather than joining
+// themselves. The sender and member may be given in different forms (phone number or LID).
+func (h *CaptchaHandler) addedByOther(evt *events.GroupInfo, member types.JID) bool {
+	if evt.JoinReason == "invite" || evt.Sender == nil {
+		return false
+	}
+	identity := h.groupHandler.identity
+	if identity.Same(*evt.Sender, member) {
+		return false
+	}
+	if evt.SenderPN != nil && identity.Same(*evt.SenderPN, member) {
+		return false
+	}
+	return true
+}
+
+// pending returns the member's open challenge in the group. Challenges are keyed by userKey,
+// but every form of the member is tried in case the phone number and LID were linked later.
+func (h *CaptchaHandler) pending(groupJID string, member types.JID) (services.CaptchaChallenge, bool) {
+	for _, key := range h.groupHandler.userKeys(member) {
+		if challenge, ok := h.store.Pending(groupJID, key); ok {
+			return challenge, true
+		}
+	}
+	return services.CaptchaChallenge{}, false
+}
+
+// CheckAndRevoke handles messages from members with a pending challenge: the correct answer
+// verifies them, anything else is revoked. Returns true if the message was consumed.
+func (h *CaptchaHandler) CheckAndRevoke(client *whatsmeow.Client, evt *events.Message) bool {
+	if !evt.Info.IsGroup || evt.Info.IsFromMe {
+		return false
+	}
+
+	groupJID := evt.Info.Chat.String()
+	challenge, ok := h.pending(groupJID, h.groupHandler.senderJID(evt))
+	if !ok {
+		return false
+	}
+
+	if strings.TrimSpace(utils.GetTextFromMessage(evt)) == challenge.Answer {
+		if h.store.Remove(challenge.GroupJID, challenge.JID) {
+			slog.Info("Captcha solved", "user", evt.Info.Sender.User, "group", groupJID)
+			h.revokeChallenge(client, evt.Info.Chat, challenge)
+			h.groupHandler.sendGroupMention(client, evt.Info.Chat,
+				fmt.Sprintf("✅ @%s terverifikasi, selamat bergabung!", evt.Info.Sender.User), []string{challenge.JID})
+		}
+		return true
+	}
+
+	revokeMsg := client.BuildRevoke(evt.Info.Chat, evt.Info.Sender, evt.Info.ID)
+	if _, err := client.SendMessage(context.Background(), evt.Info.Chat, revokeMsg); err != nil {
+		slog.Error("failed to revoke unverified member message", "error", err)
+	}
+	return true
+}
+
+// Expire kicks a member who did not answer in time. It is called by the challenge sweeper.
+func (h *CaptchaHandler) Expire(client *whatsmeow.Client, challenge services.CaptchaChallenge) {
+	chat, err := types.ParseJID(challenge.GroupJID)
+	if err != nil {
+		return
+	}
+	member, err := types.ParseJID(challenge.JID)
+	if err != nil {
+		return
+	}
+
+	h.revokeChallenge(client, chat, challenge)
+
+	if _, err := client.UpdateGroupParticipants(context.Background(), chat, []types.JID{member}, whatsmeow.ParticipantChangeRemove); err != nil {
+		// The member may have left already; there is nothing else to do.
+		slog.Warn("failed to kick unverified member", "user", member.User, "group", challenge.GroupJID, "error", err)
+		return
+	}
+	slog.Info("Unverified member kicked", "user", member.User, "group", challenge.GroupJID)
+	h.groupHandler.sendGroupMention(client, chat,
+		fmt.Sprintf("👢 @%s dikeluarkan karena tidak menjawab verifikasi.", member.User), []string{challenge.JID})
+}
+
+// revokeChallenge deletes the bot's challenge message once it is resolved.
+func (h *CaptchaHandler) revokeChallenge(client *whatsmeow.Client, chat types.JID, challenge services.CaptchaChallenge) {
+	if challenge.MessageID == "" || client.Store.ID == nil {
+		return
+	}
+	revokeMsg := client.BuildRevoke(chat, client.Store.ID.ToNonAD(), challenge.MessageID)
+	if _, err := client.SendMessage(context.Background(), chat, revokeMsg); err != nil {
+		slog.Warn("failed to revoke captcha challenge", "error", err)
+	}
+}
+
+// HandleCaptcha shows or changes new-member verification in the group (admin only).
+// Usage: .captcha [on|off|timeout <durasi>]
+func (h *CaptchaHandler) HandleCaptcha(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	if len(args) == 0 {
+		settings := h.store.Settings(groupJID)
+		status := "nonaktif"
+		if settings.Enabled {
+			status = "aktif"
+		}
+		utils.ReplyTextDirect(client, evt, fmt.Sprintf("🔐 Verifikasi member baru %s di grup ini (batas waktu %s).\nGunakan .captcha on|off|timeout <durasi>",
+			status, utils.FormatDuration(settings.Timeout)))
+		return
+	}
+
+	var text string
+	switch strings.ToLower(args[0]) {
+	case "on":
+		text = "Verifikasi member baru aktif: member yang masuk lewat link harus menjawab pertanyaan sebelum bisa chat."
+		if !h.store.SetEnabled(groupJID, true) {
+			text = "Gagal menyimpan pengaturan verifikasi."
+		}
+	case "off":
+		text = "Verifikasi member baru dinonaktifkan."
+		if !h.store.SetEnabled(groupJID, false) {
+			text = "Gagal menyimpan pengaturan verifikasi."
+		}
+	case "timeout":
+		var timeout time.Duration
+		ok := false
+		if len(args) > 1 {
+			timeout, ok = utils.ParseDuration(args[1])
+		}
+		if !ok || timeout < time.Minute {
+			text = "Contoh: .captcha timeout 5m (minimal 1 menit)"
+			break
+		}
+		text = fmt.Sprintf("Member baru sekarang punya waktu %s untuk menjawab.", utils.FormatDuration(timeout))
+		if !h.store.SetTimeout(groupJID, timeout) {
+			text = "Gagal menyimpan pengaturan verifikasi."
+		}
+	default:
+		text = "Contoh: .captcha on|off|timeout <durasi>"
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/services"
+)
+
+func TestCaptchaQuestion(t *testing.T) {
+	tests := []struct {
+		q        captchaQuestion
+		wantText string
+		want     string
+	}{
+		{captchaQuestion{a: 3, b: 4, op: '+'}, "3 + 4", "7"},
+		{captchaQuestion{a: 9, b: 9, op: '-'}, "9 - 9", "0"},
+		{captchaQuestion{a: 6, b: 7, op: '×'}, "6 × 7", "42"},
+	}
+	for _, tt := range tests {
+		if got := tt.q.String(); got != tt.wantText {
+			t.Errorf("String() = %q, want %q", got, tt.wantText)
+		}
+		if got := tt.q.answer(); got != tt.want {
+			t.Errorf("%s: answer() = %q, want %q", tt.wantText, got, tt.want)
+		}
+	}
+
+	for i := 0; i < 1000; i++ {
+		q := newCaptchaQuestion()
+		n, err := strconv.Atoi(q.answer())
+		if err != nil || n < 0 {
+			t.Fatalf("%s has answer %q, want a non-negative number", q, q.answer())
+		}
+	}
+}
+
+func TestCaptchaPendingMatchesBothForms(t *testing.T) {
+	db := newTestDB(t)
+	groupHandler := NewGroupHandler(services.NewGroupCache(time.Minute), services.NewIdentity(lidMap{lid: "111", pn: "628111"}))
+	h := NewCaptchaHandler(services.NewCaptchaStore(db, time.Minute), groupHandler)
+	lid := types.NewJID("111", types.HiddenUserServer)
+	pn := types.NewJID("628111", types.DefaultUserServer)
+
+	// The join event names the member by LID; the answer arrives from the phone number, and vice versa.
+	h.store.Add(services.CaptchaChallenge{GroupJID: "g1@g.us", JID: groupHandler.userKey(lid), Answer: "7", ExpiresAt: time.Now().Add(time.Minute)})
+	for _, sender := range []types.JID{pn, lid} {
+		if _, ok := h.pending("g1@g.us", sender); !ok {
+			t.Errorf("pending(%s) found no challenge", sender)
+		}
+	}
+
+	// Challenges stored under the LID before the forms were linked are still found.
+	h.store.Add(services.CaptchaChallenge{GroupJID: "g2@g.us", JID: lid.String(), Answer: "7", ExpiresAt: time.Now().Add(time.Minute)})
+	if _, ok := h.pending("g2@g.us", pn); !ok {
+		t.Error("pending() missed a challenge stored under the LID")
+	}
+}
+
+func TestCaptchaAddedByOther(t *testing.T) {
+	groupHandler := NewGroupHandler(services.NewGroupCache(time.Minute), services.NewIdentity(lidMap{lid: "111", pn: "628111"}))
+	h := NewCaptchaHandler(nil, groupHandler)
+	lid := types.NewJID("111", types.HiddenUserServer)
+	pn := types.NewJID("628111", types.DefaultUserServer)
+	admin := types.NewJID("628999", types.DefaultUserServer)
+	unmappedLID := types.NewJID("555", types.HiddenUserServer)
+
+	tests := []struct {
+		name   string
+		evt    events.GroupInfo
+		member types.JID
+		want   bool
+	}{
+		{"self join, same form", events.GroupInfo{Sender: &pn}, pn, false},
+		{"self join, LID sender and PN member", events.GroupInfo{Sender: &lid}, pn, false},
+		{"self join, PN sender and LID member", events.GroupInfo{Sender: &pn}, lid, false},
+		{"self join, unmapped LID sender with SenderPN", events.GroupInfo{Sender: &unmappedLID, SenderPN: &pn}, pn, false},
+		{"invite link", events.GroupInfo{Sender: &admin, JoinReason: "invite"}, pn, false},
+		{"no sender", events.GroupInfo{}, pn, false},
+		{"added by admin", events.GroupInfo{Sender: &admin}, lid, true},
+	}
+	for _, tt := range tests {
+		if got := h.addedByOther(&tt.evt, tt.member); got != tt.want {
+			t.Errorf("%s: addedByOther() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
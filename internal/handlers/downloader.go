@@ -15,15 +15,17 @@ import (
 
 // DownloaderHandler handles media download commands.
 type DownloaderHandler struct {
-	ytdlp *services.YtDlpService
-	pool  *services.WorkerPool
+	ytdlp  *services.YtDlpService
+	pool   *services.WorkerPool
+	quotas *services.QuotaStore
 }
 
 // NewDownloaderHandler creates a new DownloaderHandler.
-func NewDownloaderHandler(pool *services.WorkerPool) *DownloaderHandler {
+func NewDownloaderHandler(pool *services.WorkerPool, quotas *services.QuotaStore) *DownloaderHandler {
 	return &DownloaderHandler{
-		ytdlp: services.NewYtDlpService(),
-		pool:  pool,
+		ytdlp:  services.NewYtDlpService(),
+		pool:   pool,
+		quotas: quotas,
 	}
 }
 
@@ -48,16 +50,26 @@ func (h *DownloaderHandler) HandleVideo(client *whatsmeow.Client, evt *events.Me
 		return
 	}
 
+	reservation, ok := h.reserveDownload(client, evt)
+	if !ok {
+		return
+	}
+
 	utils.ReplyTextDirect(client, evt, "Sedang memproses media...")
 
 	// Use the smart "DownloadAny" service.
 	result, err := h.ytdlp.DownloadAny(url)
 	if err != nil {
 		slog.Error("download failed", "error", err)
+		h.refundDownload(evt, reservation)
 		utils.ReplyTextDirect(client, evt, "Gagal mendownload media. Pastikan link publik dan valid.")
 		return
 	}
 
+	if !h.reserveDownloadBytes(client, evt, &reservation, result) {
+		return
+	}
+
 	caption := result.Title
 	if len(caption) > 200 {
 		caption = caption[:197] + "..."
@@ -67,12 +79,14 @@ func (h *DownloaderHandler) HandleVideo(client *whatsmeow.Client, evt *events.Me
 	if result.Type == "image" {
 		if err := utils.ReplyImage(client, evt, result.Data, result.Mimetype, caption); err != nil {
 			slog.Error("failed to send image", "error", err)
+			h.refundDownload(evt, reservation)
 			utils.ReplyTextDirect(client, evt, "Gagal mengirim gambar ke WhatsApp.")
 		}
 	} else {
 		// Default to video
 		if err := utils.ReplyVideo(client, evt, result.Data, result.Mimetype, caption); err != nil {
 			slog.Error("failed to send video", "error", err)
+			h.refundDownload(evt, reservation)
 			utils.ReplyTextDirect(client, evt, "Gagal mengirim media ke WhatsApp (mungkin file terlalu besar).")
 		}
 	}
@@ -99,17 +113,68 @@ func (h *DownloaderHandler) HandleAudio(client *whatsmeow.Client, evt *events.Me
 		return
 	}
 
+	reservation, ok := h.reserveDownload(client, evt)
+	if !ok {
+		return
+	}
+
 	utils.ReplyTextDirect(client, evt, "Sedang mengambil audio...")
 
 	result, err := h.ytdlp.DownloadAudio(url)
 	if err != nil {
 		slog.Error("download failed", "error", err)
+		h.refundDownload(evt, reservation)
 		utils.ReplyTextDirect(client, evt, "Gagal mendownload audio.")
 		return
 	}
 
+	if !h.reserveDownloadBytes(client, evt, &reservation, result) {
+		return
+	}
+
 	if err := utils.ReplyAudio(client, evt, result.Data, result.Mimetype); err != nil {
 		slog.Error("failed to send audio", "error", err)
+		h.refundDownload(evt, reservation)
 		utils.ReplyTextDirect(client, evt, "Gagal mengirim audio.")
 	}
 }
+
+// downloadReservation records what a download has charged against the quotas, and on
+// which days, so a failure can give back exactly that.
+type downloadReservation struct {
+	day     string
+	size    int64
+	sizeDay string
+}
+
+// reserveDownload reserves one download against the sender's and group's quotas before any
+// work is done, so concurrent downloads cannot overshoot the count. It also requires some
+// download volume to be left. The reservation is given back with refundDownload on failure.
+func (h *DownloaderHandler) reserveDownload(client *whatsmeow.Client, evt *events.Message) (downloadReservation, bool) {
+	if !checkQuota(client, evt, h.quotas, services.QuotaDownloadBytes) {
+		return downloadReservation{}, false
+	}
+	day, ok := reserveQuota(client, evt, h.quotas, services.QuotaDownloads, 1)
+	return downloadReservation{day: day}, ok
+}
+
+// reserveDownloadBytes charges a finished download's size against the volume quota.
+// A file larger than what is left is not sent, and the download reservation is given back.
+func (h *DownloaderHandler) reserveDownloadBytes(client *whatsmeow.Client, evt *events.Message, reservation *downloadReservation, result *services.MediaResult) bool {
+	size := int64(len(result.Data))
+	day, ok := reserveQuota(client, evt, h.quotas, services.QuotaDownloadBytes, size)
+	if !ok {
+		h.refundDownload(evt, *reservation)
+		return false
+	}
+	reservation.size, reservation.sizeDay = size, day
+	return true
+}
+
+// refundDownload gives back a download reservation and the size charged for it.
+func (h *DownloaderHandler) refundDownload(evt *events.Message, reservation downloadReservation) {
+	refundQuota(evt, h.quotas, services.QuotaDownloads, 1, reservation.day)
+	if reservation.size > 0 {
+		refundQuota(evt, h.quotas, services.QuotaDownloadBytes, reservation.size, reservation.sizeDay)
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/ratelimit"
+	"chisa_bot/pkg/utils"
+)
+
+// FloodHandler mutes members who send too many messages in a short time and handles the .flood command.
+// Every flood also counts as a warning.
+type FloodHandler struct {
+	detector     *ratelimit.FloodDetector
+	store        *services.FloodStore
+	banStore     *services.BanStore
+	groupHandler *GroupHandler
+	warnHandler  *WarnHandler
+}
+
+// NewFloodHandler creates a new FloodHandler.
+func NewFloodHandler(store *services.FloodStore, banStore *services.BanStore, groupHandler *GroupHandler, warnHandler *WarnHandler) *FloodHandler {
+	return &FloodHandler{
+		detector:     ratelimit.NewFloodDetector(),
+		store:        store,
+		banStore:     banStore,
+		groupHandler: groupHandler,
+		warnHandler:  warnHandler,
+	}
+}
+
+// CheckAndRevoke records a group message and, when it completes a flood, revokes the flooded
+// messages, bans the sender from chatting in the group for the configured time and announces it.
+// Returns true if the message was revoked, false otherwise.
+func (h *FloodHandler) CheckAndRevoke(client *whatsmeow.Client, evt *events.Message) bool {
+	if !evt.Info.IsGroup || evt.Info.IsFromMe {
+		return false
+	}
+	// Deletions, edits and reactions are not new messages.
+	if evt.Message.GetProtocolMessage() != nil || evt.Message.GetReactionMessage() != nil {
+		return false
+	}
+
+	chat := evt.Info.Chat.String()
+	settings := h.store.Settings(chat)
+	if settings.MaxMessages < 1 {
+		return false
+	}
+
+	sender := evt.Info.Sender.ToNonAD()
+	ids, flood := h.detector.Record(sender.String(), chat, evt.Info.ID, settings.MaxMessages, settings.Window)
+	if !flood {
+		return false
+	}
+
+	if h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		return false
+	}
+
+	slog.Info("Flood detected — muting", "user", sender.User, "chat", chat, "messages", len(ids))
+
+	for _, id := range ids {
+		revokeMsg := client.BuildRevoke(evt.Info.Chat, evt.Info.Sender, id)
+		if _, err := client.SendMessage(context.Background(), evt.Info.Chat, revokeMsg); err != nil {
+			slog.Error("failed to revoke flood message", "error", err)
+		}
+	}
+
+	issuer := ""
+	if client.Store.ID != nil {
+		issuer = client.Store.ID.ToNonAD().String()
+	}
+	expiresAt := time.Now().Add(settings.MuteDuration)
+	notice := fmt.Sprintf("🚫 @%s dibisukan selama %s karena flood (%d pesan dalam %s).",
+		sender.User, utils.FormatDuration(settings.MuteDuration), len(ids), utils.FormatDuration(settings.Window))
+	// Never shorten an existing chat ban, e.g. a permanent .banchat.
+	if h.warnHandler.chatBannedUntil(chat, sender, expiresAt) {
+		notice = fmt.Sprintf("🚫 Flood dari @%s dihapus (%d pesan dalam %s).", sender.User, len(ids), utils.FormatDuration(settings.Window))
+	} else {
+		h.banStore.Add(services.Ban{
+			JID:        h.groupHandler.userKey(sender),
+			Category:   services.BanChat,
+			Scope:      chat,
+			ExpiresAt:  expiresAt,
+			OriginChat: chat,
+			Issuer:     issuer,
+			Reason:     "flood",
+		})
+	}
+
+	// The warning may escalate to a mute of its own; it never shortens this one, and both
+	// are announced in one message.
+	h.warnHandler.WarnWithNotice(client, evt.Info.Chat, sender, issuer, "flood", notice)
+	return true
+}
+
+// HandleFlood shows or changes the group's flood thresholds (admin only).
+// Usage: .flood | .flood <pesan> <detik> | .flood mute <durasi> | .flood off | .flood reset
+func (h *FloodHandler) HandleFlood(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	settings := h.store.Settings(groupJID)
+
+	if len(args) == 0 {
+		if settings.MaxMessages < 1 {
+			utils.ReplyTextDirect(client, evt, "🌊 Deteksi flood nonaktif di grup ini.\nAktifkan dengan .flood <pesan> <detik>")
+			return
+		}
+		utils.ReplyTextDirect(client, evt, fmt.Sprintf("🌊 *Deteksi Flood*\n\nBatas: %d pesan dalam %s\nHukuman: dibisukan %s",
+			settings.MaxMessages, utils.FormatDuration(settings.Window), utils.FormatDuration(settings.MuteDuration)))
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "off":
+		settings.MaxMessages = 0
+		h.reply(client, evt, h.store.SetSettings(groupJID, settings), "Deteksi flood dinonaktifkan.")
+		return
+
+	case "reset":
+		h.reply(client, evt, h.store.Reset(groupJID), "Pengaturan flood dikembalikan ke default.")
+		return
+
+	case "mute":
+		if len(args) < 2 {
+			utils.ReplyTextDirect(client, evt, "Contoh: .flood mute 10m")
+			return
+		}
+		duration, ok := utils.ParseDuration(args[1])
+		if !ok {
+			utils.ReplyTextDirect(client, evt, "Durasi tidak valid. Contoh: 30m, 2h, 1d")
+			return
+		}
+		settings.MuteDuration = duration
+		h.reply(client, evt, h.store.SetSettings(groupJID, settings),
+			fmt.Sprintf("Pelaku flood akan dibisukan selama %s.", utils.FormatDuration(duration)))
+		return
+	}
+
+	if len(args) < 2 {
+		utils.ReplyTextDirect(client, evt, "Contoh:\n.flood 8 10 (maks 8 pesan per 10 detik)\n.flood mute 10m\n.flood off\n.flood reset")
+		return
+	}
+	maxMessages, err1 := strconv.Atoi(args[0])
+	windowSec, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil || maxMessages < 1 || windowSec < 1 {
+		utils.ReplyTextDirect(client, evt, "Jumlah pesan dan detik harus angka positif. Contoh: .flood 8 10")
+		return
+	}
+
+	settings.MaxMessages = maxMessages
+	settings.Window = time.Duration(windowSec) * time.Second
+	if settings.MuteDuration <= 0 {
+		settings.MuteDuration = h.store.Defaults().MuteDuration
+	}
+	h.reply(client, evt, h.store.SetSettings(groupJID, settings),
+		fmt.Sprintf("Deteksi flood aktif: maks %d pesan dalam %s.", maxMessages, utils.FormatDuration(settings.Window)))
+}
+
+func (h *FloodHandler) reply(client *whatsmeow.Client, evt *events.Message, ok bool, text string) {
+	if !ok {
+		utils.ReplyTextDirect(client, evt, "Gagal menyimpan pengaturan flood.")
+		return
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
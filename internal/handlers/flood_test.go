@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"testing"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+func TestFloodHandler_IgnoresReactionsAndProtocolMessages(t *testing.T) {
+	// The handler has no store, so reaching the flood detector would panic.
+	h := &FloodHandler{}
+	info := types.MessageInfo{MessageSource: types.MessageSource{IsGroup: true}}
+
+	for name, msg := range map[string]*waProto.Message{
+		"reaction": {ReactionMessage: &waProto.ReactionMessage{}},
+		"revoke":   {ProtocolMessage: &waProto.ProtocolMessage{Type: waProto.ProtocolMessage_REVOKE.Enum()}},
+		"edit":     {ProtocolMessage: &waProto.ProtocolMessage{Type: waProto.ProtocolMessage_MESSAGE_EDIT.Enum()}},
+	} {
+		if h.CheckAndRevoke(nil, &events.Message{Info: info, Message: msg}) {
+			t.Errorf("%s should not count as a flood message", name)
+		}
+	}
+}
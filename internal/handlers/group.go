@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
 
 	"go.mau.fi/whatsmeow"
 	waProto "go.mau.fi/whatsmeow/binary/proto"
@@ -11,46 +13,113 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
 	"chisa_bot/pkg/utils"
 )
 
+// JoinHook runs for every member who joins a group, before the welcome message.
+// It returns false if the member was removed and should not be welcomed.
+type JoinHook func(client *whatsmeow.Client, evt *events.GroupInfo, member types.JID) bool
+
 // GroupHandler handles group management features.
 type GroupHandler struct {
+	cache     *services.GroupCache
+	identity  *services.Identity
+	joinHooks []JoinHook
+}
+
+// NewGroupHandler creates a new GroupHandler that reads group metadata through cache
+// and matches users in both their phone number and LID form through identity.
+func NewGroupHandler(cache *services.GroupCache, identity *services.Identity) *GroupHandler {
+	return &GroupHandler{cache: cache, identity: identity}
+}
+
+// GroupInfo returns the group's metadata from the cache, fetching it from WhatsApp when missing or expired.
+func (h *GroupHandler) GroupInfo(client *whatsmeow.Client, chatJID types.JID) (*types.GroupInfo, error) {
+	return h.cache.Get(chatJID, func(jid types.JID) (*types.GroupInfo, error) {
+		return client.GetGroupInfo(context.Background(), jid)
+	})
 }
 
-// NewGroupHandler creates a new GroupHandler.
-func NewGroupHandler() *GroupHandler {
-	return &GroupHandler{}
+// HandleJoinedGroup caches the metadata WhatsApp sends when the bot joins a group.
+func (h *GroupHandler) HandleJoinedGroup(evt *events.JoinedGroup) {
+	h.cache.Set(&evt.GroupInfo)
+}
+
+// OnJoin registers a hook for members joining a group. Hooks run in registration order.
+func (h *GroupHandler) OnJoin(hook JoinHook) {
+	h.joinHooks = append(h.joinHooks, hook)
 }
 
 // IsAdmin checks if the user is an admin in the group, or if they have special privileges (VIP/Owner or Exception list).
 func (h *GroupHandler) IsAdmin(client *whatsmeow.Client, chatJID types.JID, userJID types.JID) bool {
-	userStr := userJID.ToNonAD().String()
-	// Cek apakah user adalah Owner
-	for _, owner := range config.OwnerJIDs {
-		if userJID.User == owner || userStr == owner {
-			return true
-		}
+	if h.IsOwner(userJID) || h.IsException(userJID) {
+		return true
 	}
-	
-	// Cek apakah user ada di daftar AdminExceptions
-	for _, exception := range config.AdminExceptions {
-		if userJID.User == exception || userStr == exception {
-			return true
+	return h.IsGroupAdmin(client, chatJID, userJID)
+}
+
+// IsOwner checks if the user is listed in OwnerJIDs.
+func (h *GroupHandler) IsOwner(userJID types.JID) bool {
+	return h.listed(config.OwnerJIDs, userJID)
+}
+
+// IsException checks if the user is listed in AdminExceptions.
+func (h *GroupHandler) IsException(userJID types.JID) bool {
+	return h.listed(config.AdminExceptions, userJID)
+}
+
+// listed checks if any known form of the user's JID is in list. Entries are full JIDs
+// (number@s.whatsapp.net or id@lid) or bare phone numbers.
+func (h *GroupHandler) listed(list []string, userJID types.JID) bool {
+	for _, jid := range h.identity.Forms(userJID) {
+		userStr := jid.String()
+		for _, entry := range list {
+			if userStr == entry || (jid.Server == types.DefaultUserServer && jid.User == entry) {
+				return true
+			}
 		}
 	}
+	return false
+}
+
+// userKey returns the key a user is stored under in the ban and warning stores.
+func (h *GroupHandler) userKey(jid types.JID) string {
+	return h.identity.Key(jid)
+}
 
-	groupInfo, err := client.GetGroupInfo(context.Background(), chatJID)
+// userKeys returns the keys of every known form of a user, for lookups of rows stored
+// before the user's phone number and LID were linked.
+func (h *GroupHandler) userKeys(jid types.JID) []string {
+	return h.identity.Keys(jid)
+}
+
+// senderJID returns the canonical JID of a message's sender.
+func (h *GroupHandler) senderJID(evt *events.Message) types.JID {
+	return h.identity.Sender(evt.Info.MessageSource)
+}
+
+// IsGroupAdmin checks if the user is an actual admin or superadmin of the group, ignoring special privileges.
+func (h *GroupHandler) IsGroupAdmin(client *whatsmeow.Client, chatJID types.JID, userJID types.JID) bool {
+	groupInfo, err := h.GroupInfo(client, chatJID)
 	if err != nil {
 		slog.Error("failed to get info", "error", err)
 		return false
 	}
-	for _, p := range groupInfo.Participants {
-		if p.JID.User == userJID.User {
-			return p.IsAdmin || p.IsSuperAdmin
+	p, found := h.findParticipant(groupInfo, userJID)
+	return found && (p.IsAdmin || p.IsSuperAdmin)
+}
+
+// UpdateGroupCache patches the cached metadata with a group change.
+// It must run before the event is handled so handlers see the new state.
+func (h *GroupHandler) UpdateGroupCache(client *whatsmeow.Client, evt *events.GroupInfo) {
+	for _, leave := range evt.Leave {
+		if isBotJID(client, leave) {
+			h.cache.Invalidate(evt.JID)
+			return
 		}
 	}
-	return false
+	h.cache.Apply(evt)
 }
 
 // HandleGroupParticipants handles join/leave events in groups.
@@ -61,6 +130,9 @@ func (h *GroupHandler) HandleGroupParticipants(client *whatsmeow.Client, evt *ev
 
 	for _, join := range evt.Join {
 		slog.Info("User joined in", "user", join.String(), "group", evt.JID.String())
+		if !h.runJoinHooks(client, evt, join) {
+			continue
+		}
 		welcomeMsg := "Selamat datang member baru"
 		h.sendGroupMention(client, evt.JID, welcomeMsg, []string{join.String()})
 	}
@@ -72,6 +144,16 @@ func (h *GroupHandler) HandleGroupParticipants(client *whatsmeow.Client, evt *ev
 	}
 }
 
+// runJoinHooks runs the join hooks for a member until one of them removes the member.
+func (h *GroupHandler) runJoinHooks(client *whatsmeow.Client, evt *events.GroupInfo, member types.JID) bool {
+	for _, hook := range h.joinHooks {
+		if !hook(client, evt, member) {
+			return false
+		}
+	}
+	return true
+}
+
 // HandleTagAll mentions all group members (admin only).
 func (h *GroupHandler) HandleTagAll(client *whatsmeow.Client, evt *events.Message) {
 	if !evt.Info.IsGroup {
@@ -89,7 +171,7 @@ func (h *GroupHandler) HandleTagAll(client *whatsmeow.Client, evt *events.Messag
 
 // TagAll mentions all group members with a custom message.
 func (h *GroupHandler) TagAll(client *whatsmeow.Client, chatJID types.JID, quotedMsg *waProto.Message, stanzaID string, senderJID types.JID, title string) {
-	groupInfo, err := client.GetGroupInfo(context.Background(), chatJID)
+	groupInfo, err := h.GroupInfo(client, chatJID)
 	if err != nil {
 		slog.Error("failed to get group info", "error", err)
 		return
@@ -137,7 +219,7 @@ func (h *GroupHandler) HandleKick(client *whatsmeow.Client, evt *events.Message,
 	}
 
 	// Prevent kicking the bot itself.
-	if client.Store.ID != nil && targetJID.User == client.Store.ID.User {
+	if isBotJID(client, targetJID) {
 		utils.ReplyTextDirect(client, evt, "Tidak bisa kick bot sendiri.")
 		return
 	}
@@ -151,6 +233,265 @@ func (h *GroupHandler) HandleKick(client *whatsmeow.Client, evt *events.Message,
 	}
 }
 
+// HandlePromote makes the mentioned or replied members group admins (admin only).
+// Usage: .promote @user [@user...]
+func (h *GroupHandler) HandlePromote(client *whatsmeow.Client, evt *events.Message, args []string) {
+	h.changeParticipants(client, evt, whatsmeow.ParticipantChangePromote, "promote", "Tag atau reply user yang ingin dijadikan admin.")
+}
+
+// HandleDemote removes admin rights from the mentioned or replied members (admin only).
+// Owners, the bot itself and the group creator cannot be demoted.
+// Usage: .demote @user [@user...]
+func (h *GroupHandler) HandleDemote(client *whatsmeow.Client, evt *events.Message, args []string) {
+	h.changeParticipants(client, evt, whatsmeow.ParticipantChangeDemote, "demote", "Tag atau reply admin yang ingin diturunkan.")
+}
+
+// HandleAdd adds members to the group by phone number (admin only).
+// Usage: .add 628123456789 [08123456789...]
+func (h *GroupHandler) HandleAdd(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	var targets []types.JID
+	var invalid []string
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		number, ok := parsePhoneNumber(arg)
+		if !ok {
+			invalid = append(invalid, arg)
+			continue
+		}
+		if !seen[number] {
+			seen[number] = true
+			targets = append(targets, types.NewJID(number, types.DefaultUserServer))
+		}
+	}
+	if len(targets) == 0 {
+		utils.ReplyTextDirect(client, evt, "Sebutkan nomor yang ingin ditambahkan.\nContoh: .add 628123456789 08123456789")
+		return
+	}
+
+	if !h.botIsAdmin(client, evt.Info.Chat) {
+		utils.ReplyTextDirect(client, evt, config.MsgBotNotAdmin)
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("👥 *Hasil add*\n")
+	for _, arg := range invalid {
+		sb.WriteString(fmt.Sprintf("\n❌ %s — nomor tidak valid", arg))
+	}
+	h.writeParticipantResults(&sb, client, evt.Info.Chat, targets, whatsmeow.ParticipantChangeAdd)
+	utils.ReplyTextDirectWithMentions(client, evt, sb.String(), jidStrings(targets))
+}
+
+// changeParticipants promotes or demotes the targeted members and reports the result for each of them.
+func (h *GroupHandler) changeParticipants(client *whatsmeow.Client, evt *events.Message, action whatsmeow.ParticipantChange, label, usage string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	targets := utils.GetTargetJIDs(evt)
+	if len(targets) == 0 {
+		utils.ReplyTextDirect(client, evt, usage)
+		return
+	}
+
+	groupInfo, err := h.GroupInfo(client, evt.Info.Chat)
+	if err != nil {
+		slog.Error("failed to get group info", "error", err)
+		utils.ReplyTextDirect(client, evt, "Gagal mengambil info grup.")
+		return
+	}
+	if !isBotAdmin(client, groupInfo) {
+		utils.ReplyTextDirect(client, evt, config.MsgBotNotAdmin)
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("👮 *Hasil %s*\n", label))
+
+	var allowed []types.JID
+	for _, target := range targets {
+		if reason := h.participantGuard(client, groupInfo, target, action); reason != "" {
+			sb.WriteString(fmt.Sprintf("\n❌ @%s — %s", target.User, reason))
+			continue
+		}
+		allowed = append(allowed, target)
+	}
+	h.writeParticipantResults(&sb, client, evt.Info.Chat, allowed, action)
+	utils.ReplyTextDirectWithMentions(client, evt, sb.String(), jidStrings(targets))
+}
+
+// participantGuard returns why a member must not be promoted or demoted, or an empty string.
+func (h *GroupHandler) participantGuard(client *whatsmeow.Client, groupInfo *types.GroupInfo, target types.JID, action whatsmeow.ParticipantChange) string {
+	participant, found := h.findParticipant(groupInfo, target)
+	if !found {
+		return "bukan anggota grup"
+	}
+
+	switch action {
+	case whatsmeow.ParticipantChangePromote:
+		if participant.IsAdmin || participant.IsSuperAdmin {
+			return "sudah admin"
+		}
+	case whatsmeow.ParticipantChangeDemote:
+		if isBotJID(client, target) {
+			return "tidak bisa menurunkan bot sendiri"
+		}
+		if h.IsOwner(target) {
+			return "tidak bisa menurunkan owner bot"
+		}
+		if participant.IsSuperAdmin {
+			return "pembuat grup tidak bisa diturunkan"
+		}
+		if !participant.IsAdmin {
+			return "bukan admin"
+		}
+	}
+	return ""
+}
+
+// writeParticipantResults applies a participant change and writes one result line per target.
+func (h *GroupHandler) writeParticipantResults(sb *strings.Builder, client *whatsmeow.Client, chat types.JID, targets []types.JID, action whatsmeow.ParticipantChange) {
+	if len(targets) == 0 {
+		return
+	}
+
+	results, err := client.UpdateGroupParticipants(context.Background(), chat, targets, action)
+	if err != nil {
+		slog.Error("failed to update participants", "action", action, "error", err)
+		for _, target := range targets {
+			sb.WriteString(fmt.Sprintf("\n❌ @%s — gagal, pastikan bot adalah admin", target.User))
+		}
+		return
+	}
+
+	for _, target := range targets {
+		code := 0
+		if result, found := findParticipantIn(results, h.identity.Forms(target)); found {
+			code = result.Error
+		}
+		if code == 0 {
+			sb.WriteString(fmt.Sprintf("\n✅ @%s", target.User))
+		} else {
+			sb.WriteString(fmt.Sprintf("\n❌ @%s — %s", target.User, participantErrorText(code)))
+		}
+	}
+	slog.Info("Participants updated", "action", action, "count", len(targets), "chat", chat.String())
+}
+
+// botIsAdmin reports whether the bot is an admin of the group.
+func (h *GroupHandler) botIsAdmin(client *whatsmeow.Client, chat types.JID) bool {
+	groupInfo, err := h.GroupInfo(client, chat)
+	if err != nil {
+		slog.Error("failed to get group info", "error", err)
+		return false
+	}
+	return isBotAdmin(client, groupInfo)
+}
+
+// isBotAdmin reports whether the bot is an admin in groupInfo.
+func isBotAdmin(client *whatsmeow.Client, groupInfo *types.GroupInfo) bool {
+	for _, p := range groupInfo.Participants {
+		if isBotJID(client, p.JID) || isBotJID(client, p.PhoneNumber) || isBotJID(client, p.LID) {
+			return p.IsAdmin || p.IsSuperAdmin
+		}
+	}
+	return false
+}
+
+// isBotJID reports whether jid is the bot's phone number or LID.
+func isBotJID(client *whatsmeow.Client, jid types.JID) bool {
+	if client.Store.ID != nil && jid.Server == types.DefaultUserServer && jid.User == client.Store.ID.User {
+		return true
+	}
+	return !client.Store.LID.IsEmpty() && jid.Server == types.HiddenUserServer && jid.User == client.Store.LID.User
+}
+
+// findParticipant looks up a member of the group by any known form of their JID.
+func (h *GroupHandler) findParticipant(groupInfo *types.GroupInfo, jid types.JID) (types.GroupParticipant, bool) {
+	return findParticipantIn(groupInfo.Participants, h.identity.Forms(jid))
+}
+
+// findParticipantIn returns the participant matching any of the forms of a JID.
+func findParticipantIn(participants []types.GroupParticipant, forms []types.JID) (types.GroupParticipant, bool) {
+	for _, p := range participants {
+		for _, jid := range forms {
+			if services.IsParticipant(p, jid) {
+				return p, true
+			}
+		}
+	}
+	return types.GroupParticipant{}, false
+}
+
+// participantErrorText explains the error codes WhatsApp returns for individual participants.
+func participantErrorText(code int) string {
+	switch code {
+	case 403:
+		return "tidak bisa ditambahkan karena pengaturan privasi"
+	case 408:
+		return "baru saja keluar dari grup, coba lagi nanti"
+	case 409:
+		return "sudah menjadi anggota grup"
+	case 404:
+		return "nomor tidak terdaftar di WhatsApp"
+	case 401:
+		return "bot diblokir oleh user ini"
+	case 500:
+		return "grup sudah penuh"
+	default:
+		return fmt.Sprintf("gagal (kode %d)", code)
+	}
+}
+
+// parsePhoneNumber normalizes a phone number typed by a user to international digits.
+// Local numbers starting with 0 are assumed to be Indonesian.
+func parsePhoneNumber(text string) (string, bool) {
+	var digits strings.Builder
+	for _, r := range strings.TrimPrefix(strings.TrimSpace(text), "@") {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' || r == '-' || r == ' ' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+
+	number := digits.String()
+	if strings.HasPrefix(number, "0") {
+		number = "62" + number[1:]
+	}
+	if len(number) < 8 || len(number) > 15 {
+		return "", false
+	}
+	return number, true
+}
+
+// jidStrings converts JIDs to the string form used in mentions.
+func jidStrings(jids []types.JID) []string {
+	out := make([]string, len(jids))
+	for i, jid := range jids {
+		out[i] = jid.String()
+	}
+	return out
+}
+
 func (h *GroupHandler) sendGroupMention(client *whatsmeow.Client, chatJID types.JID, text string, mentionJIDs []string) {
 	msg := &waProto.Message{
 		ExtendedTextMessage: &waProto.ExtendedTextMessage{
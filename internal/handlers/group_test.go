@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"go.mau.fi/whatsmeow/types"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+)
+
+func TestParsePhoneNumber(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"628123456789", "628123456789", true},
+		{"+62 812-3456-789", "628123456789", true},
+		{"08123456789", "628123456789", true},
+		{"@628123456789", "628123456789", true},
+		{"(1) 415 555 0100", "14155550100", true},
+		{"12345", "", false},
+		{"0812abc", "", false},
+		{"1234567890123456", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePhoneNumber(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parsePhoneNumber(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// newTestDB opens an in-memory database for handler tests that need real stores.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test DB: %v", err)
+	}
+	// Each connection to :memory: is a separate database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// lidMap is an in-memory services.LIDMapper with a single mapping.
+type lidMap struct{ lid, pn string }
+
+func (m lidMap) GetPNForLID(_ context.Context, lid types.JID) (types.JID, error) {
+	if lid.User == m.lid {
+		return types.NewJID(m.pn, types.DefaultUserServer), nil
+	}
+	return types.EmptyJID, nil
+}
+
+func (m lidMap) GetLIDForPN(_ context.Context, pn types.JID) (types.JID, error) {
+	if pn.User == m.pn {
+		return types.NewJID(m.lid, types.HiddenUserServer), nil
+	}
+	return types.EmptyJID, nil
+}
+
+func TestIsOwnerMatchesBothForms(t *testing.T) {
+	defer func(owners []string) { config.OwnerJIDs = owners }(config.OwnerJIDs)
+	h := NewGroupHandler(services.NewGroupCache(time.Minute), services.NewIdentity(lidMap{lid: "111", pn: "628111"}))
+
+	tests := []struct {
+		owners []string
+		user   types.JID
+		want   bool
+	}{
+		{[]string{"628111"}, types.NewJID("111", types.HiddenUserServer), true},
+		{[]string{"628111@s.whatsapp.net"}, types.NewJID("111", types.HiddenUserServer), true},
+		{[]string{"111@lid"}, types.NewJID("628111", types.DefaultUserServer), true},
+		{[]string{"628111"}, types.NewJID("628111", types.DefaultUserServer), true},
+		// A bare number is a phone number, never a LID.
+		{[]string{"222"}, types.NewJID("222", types.HiddenUserServer), false},
+	}
+	for _, tt := range tests {
+		config.OwnerJIDs = tt.owners
+		if got := h.IsOwner(tt.user); got != tt.want {
+			t.Errorf("IsOwner(%s) with owners %v = %v, want %v", tt.user, tt.owners, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+// GroupModeHandler opens and closes groups (announce-only mode), on command or on a daily schedule.
+type GroupModeHandler struct {
+	store        *services.ScheduleStore
+	groupHandler *GroupHandler
+	timeZone     string // default for new schedules
+}
+
+// NewGroupModeHandler creates a new GroupModeHandler.
+func NewGroupModeHandler(store *services.ScheduleStore, groupHandler *GroupHandler, timeZone string) *GroupModeHandler {
+	return &GroupModeHandler{store: store, groupHandler: groupHandler, timeZone: timeZone}
+}
+
+// HandleClose makes the group announce-only, so only admins can send messages (admin only).
+// Usage: .close
+func (h *GroupModeHandler) HandleClose(client *whatsmeow.Client, evt *events.Message, args []string) {
+	h.setAnnounce(client, evt, true, "🔒 Grup ditutup, hanya admin yang bisa mengirim pesan.")
+}
+
+// HandleOpen lets every member send messages again (admin only).
+// Usage: .open
+func (h *GroupModeHandler) HandleOpen(client *whatsmeow.Client, evt *events.Message, args []string) {
+	h.setAnnounce(client, evt, false, "🔓 Grup dibuka, semua member bisa mengirim pesan.")
+}
+
+func (h *GroupModeHandler) setAnnounce(client *whatsmeow.Client, evt *events.Message, announce bool, text string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	if err := client.SetGroupAnnounce(context.Background(), evt.Info.Chat, announce); err != nil {
+		slog.Error("failed to set group announce", "announce", announce, "error", err)
+		utils.ReplyTextDirect(client, evt, "Gagal mengubah pengaturan grup. Pastikan bot adalah admin.")
+		return
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
+
+// HandleSchedule shows or changes the group's daily night mode (admin only).
+// Usage: .schedule close 22:00 open 06:00 [zona] | .schedule announce on|off | .schedule off
+func (h *GroupModeHandler) HandleSchedule(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	sched, exists := h.store.Get(groupJID)
+
+	if len(args) == 0 {
+		if !exists {
+			utils.ReplyTextDirect(client, evt, "Belum ada jadwal untuk grup ini.\nContoh: .schedule close 22:00 open 06:00 [Asia/Jakarta]")
+			return
+		}
+		utils.ReplyTextDirect(client, evt, "🌙 "+describeSchedule(sched)+"\nGunakan .schedule off untuk menghapus jadwal.")
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "off":
+		text := "Jadwal buka/tutup grup dihapus."
+		if !h.store.Remove(groupJID) {
+			text = "Belum ada jadwal untuk grup ini."
+		}
+		utils.ReplyTextDirect(client, evt, text)
+		return
+
+	case "announce":
+		if !exists {
+			utils.ReplyTextDirect(client, evt, "Belum ada jadwal untuk grup ini.")
+			return
+		}
+		if len(args) < 2 || (args[1] != "on" && args[1] != "off") {
+			utils.ReplyTextDirect(client, evt, "Contoh: .schedule announce on|off")
+			return
+		}
+		sched.Announce = args[1] == "on"
+		text := "Pengumuman saat grup dibuka/ditutup dimatikan."
+		if sched.Announce {
+			text = "Bot akan mengumumkan setiap kali grup dibuka/ditutup."
+		}
+		if !h.store.Set(sched) {
+			text = "Gagal menyimpan jadwal."
+		}
+		utils.ReplyTextDirect(client, evt, text)
+		return
+	}
+
+	parsed, errText := parseScheduleArgs(args, h.timeZone)
+	if errText != "" {
+		utils.ReplyTextDirect(client, evt, errText)
+		return
+	}
+	parsed.GroupJID = groupJID
+	parsed.Announce = !exists || sched.Announce
+
+	if !h.store.Set(parsed) {
+		utils.ReplyTextDirect(client, evt, "Gagal menyimpan jadwal.")
+		return
+	}
+	utils.ReplyTextDirect(client, evt, "✅ "+describeSchedule(parsed))
+}
+
+// Apply opens or closes a group when its schedule says so. It is called by the scheduler.
+func (h *GroupModeHandler) Apply(client *whatsmeow.Client, sched services.GroupSchedule, action services.ScheduleAction) {
+	chat, err := types.ParseJID(sched.GroupJID)
+	if err != nil {
+		return
+	}
+
+	if err := client.SetGroupAnnounce(context.Background(), chat, action == services.ScheduleClose); err != nil {
+		slog.Error("failed to apply group schedule", "group", sched.GroupJID, "action", action, "error", err)
+		return
+	}
+	slog.Info("Group schedule applied", "group", sched.GroupJID, "action", action)
+
+	if !sched.Announce {
+		return
+	}
+	text := fmt.Sprintf("🌙 Grup ditutup sampai %s. Selamat beristirahat!", services.FormatClock(sched.OpenAt))
+	if action == services.ScheduleOpen {
+		text = "☀️ Grup dibuka kembali, selamat beraktivitas!"
+	}
+	h.groupHandler.sendGroupMention(client, chat, text, nil)
+}
+
+// parseScheduleArgs parses "close HH:MM open HH:MM [zona]" in either order.
+// Returns a user-facing error text if the arguments are invalid.
+func parseScheduleArgs(args []string, defaultTimeZone string) (services.GroupSchedule, string) {
+	const usage = "Contoh: .schedule close 22:00 open 06:00 [Asia/Jakarta]"
+
+	sched := services.GroupSchedule{TimeZone: defaultTimeZone}
+	closeSet, openSet := false, false
+	for i := 0; i < len(args); i++ {
+		switch key := strings.ToLower(args[i]); key {
+		case "close", "open":
+			if i+1 >= len(args) {
+				return sched, usage
+			}
+			minute, ok := services.ParseClock(args[i+1])
+			if !ok {
+				return sched, fmt.Sprintf("Jam %q tidak valid, gunakan format HH:MM.", args[i+1])
+			}
+			if key == "close" {
+				sched.CloseAt, closeSet = minute, true
+			} else {
+				sched.OpenAt, openSet = minute, true
+			}
+			i++
+		default:
+			if _, err := time.LoadLocation(args[i]); err != nil {
+				return sched, fmt.Sprintf("Zona waktu %q tidak dikenal. Contoh: Asia/Jakarta, Asia/Makassar, UTC", args[i])
+			}
+			sched.TimeZone = args[i]
+		}
+	}
+
+	if !closeSet || !openSet {
+		return sched, usage
+	}
+	if sched.CloseAt == sched.OpenAt {
+		return sched, "Jam tutup dan jam buka tidak boleh sama."
+	}
+	return sched, ""
+}
+
+// describeSchedule summarizes a schedule for replies.
+func describeSchedule(sched services.GroupSchedule) string {
+	announce := "tanpa pengumuman"
+	if sched.Announce {
+		announce = "dengan pengumuman"
+	}
+	return fmt.Sprintf("Grup otomatis ditutup pukul %s dan dibuka pukul %s (%s), %s.",
+		services.FormatClock(sched.CloseAt), services.FormatClock(sched.OpenAt), sched.TimeZone, announce)
+}
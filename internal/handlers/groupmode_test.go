@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"testing"
+
+	"chisa_bot/internal/services"
+)
+
+func TestParseScheduleArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    services.GroupSchedule
+		wantErr bool
+	}{
+		{"default zone", []string{"close", "22:00", "open", "06:00"},
+			services.GroupSchedule{CloseAt: 22 * 60, OpenAt: 6 * 60, TimeZone: "Asia/Jakarta"}, false},
+		{"reversed with zone", []string{"open", "5:30", "close", "23:15", "UTC"},
+			services.GroupSchedule{CloseAt: 23*60 + 15, OpenAt: 5*60 + 30, TimeZone: "UTC"}, false},
+		{"missing open", []string{"close", "22:00"}, services.GroupSchedule{}, true},
+		{"bad clock", []string{"close", "25:00", "open", "06:00"}, services.GroupSchedule{}, true},
+		{"unknown zone", []string{"close", "22:00", "open", "06:00", "Mars/Olympus"}, services.GroupSchedule{}, true},
+		{"same time", []string{"close", "06:00", "open", "06:00"}, services.GroupSchedule{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errText := parseScheduleArgs(tt.args, "Asia/Jakarta")
+			if (errText != "") != tt.wantErr {
+				t.Fatalf("parseScheduleArgs() error = %q, wantErr %v", errText, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseScheduleArgs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/imagehash"
+	"chisa_bot/pkg/utils"
+)
+
+// imageHashMaxDownload caps how much media is downloaded to hash a message that has no thumbnail.
+const imageHashMaxDownload = 5 * 1024 * 1024
+
+// ImageBlockHandler revokes images and stickers that look like a blocked image
+// in groups that enabled the image filter, and handles the image blocklist commands.
+type ImageBlockHandler struct {
+	store        *services.ImageFilterStore
+	blocklist    *services.MediaBlocklist
+	groupHandler *GroupHandler
+	ffmpeg       *services.FFmpegService
+	pool         *services.WorkerPool
+}
+
+// NewImageBlockHandler creates a new ImageBlockHandler.
+func NewImageBlockHandler(store *services.ImageFilterStore, blocklist *services.MediaBlocklist, groupHandler *GroupHandler, pool *services.WorkerPool) *ImageBlockHandler {
+	return &ImageBlockHandler{
+		store:        store,
+		blocklist:    blocklist,
+		groupHandler: groupHandler,
+		ffmpeg:       services.NewFFmpegService(),
+		pool:         pool,
+	}
+}
+
+// CheckAndRevoke revokes an image, video or sticker whose perceptual hash is within
+// the group's distance of a blocked image. Returns true if the message was revoked, false otherwise.
+func (h *ImageBlockHandler) CheckAndRevoke(client *whatsmeow.Client, evt *events.Message) bool {
+	if !evt.Info.IsGroup || evt.Info.IsFromMe {
+		return false
+	}
+
+	groupJID := evt.Info.Chat.String()
+	settings := h.store.Settings(groupJID)
+	if !settings.Enabled {
+		return false
+	}
+	if !isImageBanMedia(evt.Message) && utils.UnwrapViewOnce(evt.Message).GetStickerMessage() == nil {
+		return false
+	}
+
+	items := h.blocklist.List(services.BlockImage, groupJID)
+	if len(items) == 0 {
+		return false
+	}
+
+	hash, ok := h.mediaHash(client, evt.Message)
+	if !ok {
+		return false
+	}
+	match, distance, ok := nearestBlockedImage(items, hash, settings.MaxDistance)
+	if !ok {
+		return false
+	}
+
+	slog.Info("Blocked image — revoking", "hash", match.Hash[:blockHashPrefixLen], "distance", distance, "user", evt.Info.Sender.User, "chat", groupJID)
+
+	revokeMsg := client.BuildRevoke(evt.Info.Chat, evt.Info.Sender, evt.Info.ID)
+	if _, err := client.SendMessage(context.Background(), evt.Info.Chat, revokeMsg); err != nil {
+		slog.Error("failed to revoke blocked image", "error", err)
+		return false
+	}
+	return true
+}
+
+// HandleImageFilter shows or changes the image filter settings of the group (admin only).
+// Usage: .imgfilter [on|off|distance <0-32>]
+func (h *ImageBlockHandler) HandleImageFilter(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	if len(args) == 0 {
+		settings := h.store.Settings(groupJID)
+		status := "nonaktif"
+		if settings.Enabled {
+			status = "aktif"
+		}
+		utils.ReplyTextDirect(client, evt, fmt.Sprintf("🖼️ Filter gambar %s di grup ini (jarak maksimum %d, %d gambar diblokir).\nGunakan .imgfilter on|off|distance <0-32>",
+			status, settings.MaxDistance, len(h.blocklist.List(services.BlockImage, groupJID))))
+		return
+	}
+
+	var text string
+	switch strings.ToLower(args[0]) {
+	case "on":
+		text = "Filter gambar aktif: gambar dan sticker yang mirip gambar yang diblokir akan otomatis dihapus."
+		if !h.store.SetEnabled(groupJID, true) {
+			text = "Gagal menyimpan pengaturan filter gambar."
+		}
+	case "off":
+		text = "Filter gambar dinonaktifkan."
+		if !h.store.SetEnabled(groupJID, false) {
+			text = "Gagal menyimpan pengaturan filter gambar."
+		}
+	case "distance":
+		distance := -1
+		if len(args) > 1 {
+			if n, err := strconv.Atoi(args[1]); err == nil {
+				distance = n
+			}
+		}
+		if distance < 0 || distance > 32 {
+			text = "Contoh: .imgfilter distance 10 (0 = hanya gambar identik, makin besar makin longgar)"
+			break
+		}
+		text = fmt.Sprintf("Jarak maksimum filter gambar diatur ke %d.", distance)
+		if !h.store.SetMaxDistance(groupJID, distance) {
+			text = "Gagal menyimpan pengaturan filter gambar."
+		}
+	default:
+		text = "Contoh: .imgfilter on|off|distance <0-32>"
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
+
+// HandleBlockImage blocks the replied image or sticker in the group, or globally for owners (admin only).
+// Usage: reply to an image with .blockimg [global]
+func (h *ImageBlockHandler) HandleBlockImage(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	quoted := utils.GetQuotedMessage(evt)
+	if !isImageBanMedia(quoted) && utils.UnwrapViewOnce(quoted).GetStickerMessage() == nil {
+		utils.ReplyTextDirect(client, evt, "Reply gambar atau sticker yang ingin diblokir dengan .blockimg [global]")
+		return
+	}
+
+	scope, ok := commandScope(client, evt, args, h.groupHandler)
+	if !ok {
+		return
+	}
+
+	hash, ok := h.mediaHash(client, quoted)
+	if !ok {
+		utils.ReplyTextDirect(client, evt, "Gagal membaca gambar tersebut, coba kirim ulang gambarnya.")
+		return
+	}
+
+	hex := imagehash.Format(hash)
+	where, list := scopeText(scope)
+	text := fmt.Sprintf("Gambar %s sekarang diblokir %s. Gambar yang mirip akan otomatis dihapus", hex[:blockHashPrefixLen], where)
+	if !h.blocklist.Add(services.BlockedMedia{
+		Kind:   services.BlockImage,
+		Hash:   hex,
+		Scope:  scope,
+		Issuer: evt.Info.Sender.ToNonAD().String(),
+	}) {
+		text = fmt.Sprintf("Gambar ini sudah ada di daftar blokir %s.", list)
+	} else if !h.store.Settings(evt.Info.Chat.String()).Enabled {
+		text += " setelah filter diaktifkan dengan .imgfilter on."
+	} else {
+		text += "."
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
+
+// HandleUnblockImage unblocks the replied image, or the image with the given hash from .blockedimgs (admin only).
+// Usage: reply to an image with .unblockimg [global] | .unblockimg <hash> [global]
+func (h *ImageBlockHandler) HandleUnblockImage(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	scope, ok := commandScope(client, evt, args, h.groupHandler)
+	if !ok {
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	var hex string
+	if quoted := utils.GetQuotedMessage(evt); isImageBanMedia(quoted) || utils.UnwrapViewOnce(quoted).GetStickerMessage() != nil {
+		// The replied copy rarely hashes exactly like the blocked one, so lift the closest entry in scope.
+		if hash, ok := h.mediaHash(client, quoted); ok {
+			var inScope []services.BlockedMedia
+			for _, item := range h.blocklist.List(services.BlockImage, groupJID) {
+				if item.Scope == scope {
+					inScope = append(inScope, item)
+				}
+			}
+			if match, _, ok := nearestBlockedImage(inScope, hash, h.store.Settings(groupJID).MaxDistance); ok {
+				hex = match.Hash
+			}
+		}
+	} else {
+		hex = resolveBlockedHash(h.blocklist, services.BlockImage, groupJID, scope, args)
+	}
+
+	if hex == "" {
+		utils.ReplyTextDirect(client, evt, "Reply gambar yang ingin dibuka blokirnya, atau sebutkan kodenya dari .blockedimgs.\nContoh: .unblockimg 3f2a9c1b7d04")
+		return
+	}
+
+	where, list := scopeText(scope)
+	text := fmt.Sprintf("Blokir gambar %s %s sudah dicabut.", hex[:blockHashPrefixLen], where)
+	if !h.blocklist.Remove(services.BlockImage, hex, scope) {
+		text = fmt.Sprintf("Gambar ini tidak ada di daftar blokir %s.", list)
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
+
+// HandleBlockedImages lists the images blocked in the group, including global ones (admin only).
+// Usage: .blockedimgs
+func (h *ImageBlockHandler) HandleBlockedImages(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	items := h.blocklist.List(services.BlockImage, evt.Info.Chat.String())
+	if len(items) == 0 {
+		utils.ReplyTextDirect(client, evt, "Tidak ada gambar yang diblokir di grup ini.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🚫 *Gambar Diblokir*\n")
+	for i, item := range items {
+		_, list := scopeText(item.Scope)
+		sb.WriteString(fmt.Sprintf("\n%d. %s (%s, %s)", i+1, item.Hash[:blockHashPrefixLen], list, item.CreatedAt.Format("02/01/2006")))
+	}
+	sb.WriteString("\n\nGunakan .unblockimg <kode> untuk membuka blokir.")
+	utils.ReplyTextDirect(client, evt, sb.String())
+}
+
+// mediaHash computes the perceptual hash of an image, video or sticker message.
+// The embedded thumbnail is used when present; otherwise images and stickers are downloaded.
+func (h *ImageBlockHandler) mediaHash(client *whatsmeow.Client, msg *waProto.Message) (uint64, bool) {
+	msg = utils.UnwrapViewOnce(msg)
+
+	if thumb := mediaThumbnail(msg); len(thumb) > 0 {
+		if hash, err := imagehash.DHashBytes(thumb); err == nil {
+			return hash, true
+		}
+	}
+
+	// Videos without a thumbnail would need frame extraction, which is too heavy for every message.
+	img, stk := msg.GetImageMessage(), msg.GetStickerMessage()
+	if (img == nil && stk == nil) || img.GetFileLength()+stk.GetFileLength() > imageHashMaxDownload {
+		return 0, false
+	}
+
+	data, err := utils.DownloadMediaFromMessage(client, msg)
+	if err != nil {
+		slog.Warn("failed to download media for image hash", "error", err)
+		return 0, false
+	}
+
+	if stk != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := h.pool.AcquireContext(ctx); err != nil {
+			return 0, false
+		}
+		data, err = h.ffmpeg.WebPToImage(data)
+		h.pool.Release()
+		if err != nil {
+			slog.Warn("failed to convert sticker for image hash", "error", err)
+			return 0, false
+		}
+	}
+
+	hash, err := imagehash.DHashBytes(data)
+	if err != nil {
+		slog.Warn("failed to hash image", "error", err)
+		return 0, false
+	}
+	return hash, true
+}
+
+// mediaThumbnail returns the preview WhatsApp embeds in image, video, document and sticker messages.
+func mediaThumbnail(msg *waProto.Message) []byte {
+	switch {
+	case msg.GetImageMessage() != nil:
+		return msg.GetImageMessage().GetJPEGThumbnail()
+	case msg.GetVideoMessage() != nil:
+		return msg.GetVideoMessage().GetJPEGThumbnail()
+	case msg.GetDocumentMessage() != nil:
+		return msg.GetDocumentMessage().GetJPEGThumbnail()
+	case msg.GetStickerMessage() != nil:
+		return msg.GetStickerMessage().GetPngThumbnail()
+	}
+	return nil
+}
+
+// nearestBlockedImage returns the entry closest to hash, if it is within maxDistance.
+func nearestBlockedImage(items []services.BlockedMedia, hash uint64, maxDistance int) (services.BlockedMedia, int, bool) {
+	var best services.BlockedMedia
+	bestDistance := -1
+	for _, item := range items {
+		blocked, err := imagehash.Parse(item.Hash)
+		if err != nil {
+			continue
+		}
+		if d := imagehash.Distance(hash, blocked); d <= maxDistance && (bestDistance < 0 || d < bestDistance) {
+			best, bestDistance = item, d
+		}
+	}
+	return best, bestDistance, bestDistance >= 0
+}
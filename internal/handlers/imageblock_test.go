@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+
+	"chisa_bot/internal/services"
+)
+
+func TestNearestBlockedImage(t *testing.T) {
+	items := []services.BlockedMedia{
+		{Hash: "00000000000000ff"}, // 8 bits from zero
+		{Hash: "0000000000000003"}, // 2 bits from zero
+		{Hash: "not-a-hash"},
+	}
+
+	tests := []struct {
+		name        string
+		hash        uint64
+		maxDistance int
+		wantHash    string
+		wantOK      bool
+	}{
+		{"closest entry wins", 0, 10, "0000000000000003", true},
+		{"exact match at distance 0", 0x3, 0, "0000000000000003", true},
+		{"outside distance", 0, 1, "", false},
+		{"far image", ^uint64(0), 10, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, _, ok := nearestBlockedImage(items, tt.hash, tt.maxDistance)
+			if ok != tt.wantOK || match.Hash != tt.wantHash {
+				t.Errorf("nearestBlockedImage() = %q, %v; want %q, %v", match.Hash, ok, tt.wantHash, tt.wantOK)
+			}
+		})
+	}
+}
@@ -19,13 +19,15 @@ import (
 type MediaHandler struct {
 	ffmpeg *services.FFmpegService
 	pool   *services.WorkerPool
+	quotas *services.QuotaStore
 }
 
 // NewMediaHandler creates a new MediaHandler.
-func NewMediaHandler(pool *services.WorkerPool) *MediaHandler {
+func NewMediaHandler(pool *services.WorkerPool, quotas *services.QuotaStore) *MediaHandler {
 	return &MediaHandler{
 		ffmpeg: services.NewFFmpegService(),
 		pool:   pool,
+		quotas: quotas,
 	}
 }
 
@@ -56,10 +58,16 @@ func (h *MediaHandler) HandleSticker(client *whatsmeow.Client, evt *events.Messa
 		mediaMsg = quoted
 	}
 
+	day, ok := reserveQuota(client, evt, h.quotas, services.QuotaMedia, 1)
+	if !ok {
+		return
+	}
+
 	// Download the media.
 	data, err := utils.DownloadMediaFromMessage(client, mediaMsg)
 	if err != nil {
 		slog.Error("failed to download media", "error", err)
+		h.refundMedia(evt, day)
 		utils.ReplyTextDirect(client, evt, "Gagal download media.")
 		return
 	}
@@ -98,6 +106,7 @@ func (h *MediaHandler) HandleSticker(client *whatsmeow.Client, evt *events.Messa
 
 	if err != nil {
 		slog.Error("conversion failed", "error", err)
+		h.refundMedia(evt, day)
 		utils.ReplyTextDirect(client, evt, fmt.Sprintf("Gagal convert ke sticker: %v", err))
 		return
 	}
@@ -112,7 +121,9 @@ func (h *MediaHandler) HandleSticker(client *whatsmeow.Client, evt *events.Messa
 	// Send the sticker.
 	if err := utils.ReplySticker(client, evt, webpData, isAnimated); err != nil {
 		slog.Error("failed to send sticker", "error", err)
+		h.refundMedia(evt, day)
 		utils.ReplyTextDirect(client, evt, "Gagal mengirim sticker.")
+		return
 	}
 }
 
@@ -133,10 +144,16 @@ func (h *MediaHandler) HandleStickerToImage(client *whatsmeow.Client, evt *event
 		return
 	}
 
+	day, ok := reserveQuota(client, evt, h.quotas, services.QuotaMedia, 1)
+	if !ok {
+		return
+	}
+
 	// Download the sticker.
 	data, err := utils.DownloadMediaFromMessage(client, quoted)
 	if err != nil {
 		slog.Error("failed to download sticker", "error", err)
+		h.refundMedia(evt, day)
 		utils.ReplyTextDirect(client, evt, "Gagal download sticker.")
 		return
 	}
@@ -145,6 +162,7 @@ func (h *MediaHandler) HandleStickerToImage(client *whatsmeow.Client, evt *event
 	pngData, err := h.ffmpeg.WebPToImage(data)
 	if err != nil {
 		slog.Error("conversion failed", "error", err)
+		h.refundMedia(evt, day)
 		utils.ReplyTextDirect(client, evt, "Gagal convert sticker ke gambar.")
 		return
 	}
@@ -152,7 +170,9 @@ func (h *MediaHandler) HandleStickerToImage(client *whatsmeow.Client, evt *event
 	// Send the image.
 	if err := utils.ReplyImage(client, evt, pngData, "image/png", ""); err != nil {
 		slog.Error("failed to send image", "error", err)
+		h.refundMedia(evt, day)
 		utils.ReplyTextDirect(client, evt, "Gagal mengirim gambar.")
+		return
 	}
 }
 
@@ -173,10 +193,16 @@ func (h *MediaHandler) HandleRetrieveViewOnce(client *whatsmeow.Client, evt *eve
 		return
 	}
 
+	day, ok := reserveQuota(client, evt, h.quotas, services.QuotaMedia, 1)
+	if !ok {
+		return
+	}
+
 	// Download media.
 	data, err := utils.DownloadMediaFromMessage(client, quoted)
 	if err != nil {
 		slog.Error("failed to download media", "error", err)
+		h.refundMedia(evt, day)
 		utils.ReplyTextDirect(client, evt, "Gagal download media.")
 		return
 	}
@@ -196,7 +222,9 @@ func (h *MediaHandler) HandleRetrieveViewOnce(client *whatsmeow.Client, evt *eve
 
 	if err != nil {
 		slog.Error("failed to send media", "error", err)
+		h.refundMedia(evt, day)
 		utils.ReplyTextDirect(client, evt, "Gagal mengirim ulang media.")
+		return
 	}
 }
 
@@ -248,10 +276,16 @@ func (h *MediaHandler) HandleBrat(client *whatsmeow.Client, evt *events.Message,
 		text = " " + text
 	}
 
+	day, ok := reserveQuota(client, evt, h.quotas, services.QuotaMedia, 1)
+	if !ok {
+		return
+	}
+
 	// Generate brat sticker image data.
 	webpData, err := h.ffmpeg.GenerateBratSticker(text)
 	if err != nil {
 		slog.Error("failed to generate", "error", err)
+		h.refundMedia(evt, day)
 		utils.ReplyTextDirect(client, evt, "Gagal membuat sticker brat. Pastikan ImageMagick (magick/convert) terinstal.")
 		return
 	}
@@ -262,6 +296,13 @@ func (h *MediaHandler) HandleBrat(client *whatsmeow.Client, evt *events.Message,
 	// Send sticker.
 	if err := utils.ReplySticker(client, evt, webpData, false); err != nil {
 		slog.Error("failed to send sticker", "error", err)
+		h.refundMedia(evt, day)
 		utils.ReplyTextDirect(client, evt, "Gagal mengirim sticker brat.")
+		return
 	}
 }
+
+// refundMedia gives back a media reservation made on day when the command fails.
+func (h *MediaHandler) refundMedia(evt *events.Message, day string) {
+	refundQuota(evt, h.quotas, services.QuotaMedia, 1, day)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/pkg/ratelimit"
+	"chisa_bot/pkg/utils"
+)
+
+// PenaltyHandler lets owners inspect and clear the rate limiter's penalty box.
+// The penalty box is shared by all chats, so it is not exposed to group admins.
+type PenaltyHandler struct {
+	limiter      *ratelimit.Limiter
+	groupHandler *GroupHandler
+}
+
+// NewPenaltyHandler creates a new PenaltyHandler.
+func NewPenaltyHandler(limiter *ratelimit.Limiter, groupHandler *GroupHandler) *PenaltyHandler {
+	return &PenaltyHandler{limiter: limiter, groupHandler: groupHandler}
+}
+
+// HandlePenalty lists users in the penalty box, or releases one (owner only).
+// Usage: .penalty | .penalty clear @user
+func (h *PenaltyHandler) HandlePenalty(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !h.groupHandler.IsOwner(evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyOwner)
+		return
+	}
+
+	if len(args) > 0 && strings.ToLower(args[0]) == "clear" {
+		h.handleClear(client, evt)
+		return
+	}
+
+	penalties := h.limiter.Penalties()
+	if len(penalties) == 0 {
+		utils.ReplyTextDirect(client, evt, "Tidak ada user di penalty box.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("⛔ *Penalty Box*\n")
+	var mentions []string
+	now := time.Now()
+	for i, p := range penalties {
+		jid, err := types.ParseJID(p.UserJID)
+		if err != nil {
+			continue
+		}
+		minutes := int(p.Until.Sub(now).Minutes()) + 1
+		sb.WriteString(fmt.Sprintf("\n%d. @%s — sisa %d menit", i+1, jid.User, minutes))
+		mentions = append(mentions, p.UserJID)
+	}
+	sb.WriteString("\n\nGunakan .penalty clear @user untuk membebaskan.")
+
+	utils.ReplyTextDirectWithMentions(client, evt, sb.String(), mentions)
+}
+
+func (h *PenaltyHandler) handleClear(client *whatsmeow.Client, evt *events.Message) {
+	targetJID, found := utils.GetTargetJID(evt)
+	if !found {
+		utils.ReplyTextDirect(client, evt, "Reply pesan atau tag member yang ingin dibebaskan dari penalty box.\nContoh: .penalty clear @member")
+		return
+	}
+
+	targetStr := targetJID.ToNonAD().String()
+	mentionText := fmt.Sprintf("@%s sudah dibebaskan dari penalty box.", targetJID.ToNonAD().User)
+	if !h.limiter.ClearPenalty(targetStr) {
+		mentionText = fmt.Sprintf("@%s tidak sedang berada di penalty box.", targetJID.ToNonAD().User)
+	}
+	utils.ReplyTextDirectWithMentions(client, evt, mentionText, []string{targetStr})
+}
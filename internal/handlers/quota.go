@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+const bytesPerMB = 1024 * 1024
+
+// quotaMetricNames maps user-facing metric names to quota metrics.
+var quotaMetricNames = map[string]services.QuotaMetric{
+	"dl":    services.QuotaDownloads,
+	"dlmb":  services.QuotaDownloadBytes,
+	"media": services.QuotaMedia,
+}
+
+// QuotaHandler handles the daily quota command.
+type QuotaHandler struct {
+	store        *services.QuotaStore
+	groupHandler *GroupHandler
+}
+
+// NewQuotaHandler creates a new QuotaHandler.
+func NewQuotaHandler(store *services.QuotaStore, groupHandler *GroupHandler) *QuotaHandler {
+	return &QuotaHandler{store: store, groupHandler: groupHandler}
+}
+
+// HandleQuota shows the remaining daily quota, or configures the group's limits (admin only).
+// Usage: .quota | .quota set <dl|dlmb|media> <user|group> <limit> | .quota reset
+func (h *QuotaHandler) HandleQuota(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if len(args) == 0 {
+		h.sendUsage(client, evt)
+		return
+	}
+
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupStr := evt.Info.Chat.String()
+	switch strings.ToLower(args[0]) {
+	case "reset":
+		if !h.store.ResetLimits(groupStr) {
+			utils.ReplyTextDirect(client, evt, "Gagal mereset kuota grup.")
+			return
+		}
+		utils.ReplyTextDirect(client, evt, "Kuota grup dikembalikan ke pengaturan default.")
+
+	case "set":
+		if len(args) < 4 {
+			utils.ReplyTextDirect(client, evt, "Penggunaan: .quota set <dl|dlmb|media> <user|group> <batas>\nGunakan 0 untuk tanpa batas.")
+			return
+		}
+		metric, ok := quotaMetricNames[strings.ToLower(args[1])]
+		if !ok {
+			utils.ReplyTextDirect(client, evt, "Jenis kuota tidak dikenal. Pilih: dl, dlmb, media.")
+			return
+		}
+		limit, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil || limit < 0 {
+			utils.ReplyTextDirect(client, evt, "Batas harus berupa angka 0 atau lebih.")
+			return
+		}
+		if metric == services.QuotaDownloadBytes {
+			limit *= bytesPerMB
+		}
+
+		limits := h.store.Limits(groupStr, metric)
+		switch strings.ToLower(args[2]) {
+		case "user":
+			limits.PerUser = limit
+		case "group", "grup":
+			limits.PerGroup = limit
+		default:
+			utils.ReplyTextDirect(client, evt, "Target kuota harus user atau group.")
+			return
+		}
+
+		if !h.store.SetLimits(groupStr, metric, limits) {
+			utils.ReplyTextDirect(client, evt, "Gagal menyimpan kuota grup.")
+			return
+		}
+		utils.ReplyTextDirect(client, evt, "Kuota grup berhasil diperbarui.")
+
+	default:
+		utils.ReplyTextDirect(client, evt, "Penggunaan: .quota | .quota set <dl|dlmb|media> <user|group> <batas> | .quota reset")
+	}
+}
+
+// sendUsage replies with the sender's and the group's usage for every metric.
+func (h *QuotaHandler) sendUsage(client *whatsmeow.Client, evt *events.Message) {
+	userStr, groupStr := quotaSubjects(evt)
+
+	metrics := []struct {
+		label  string
+		metric services.QuotaMetric
+	}{
+		{"Download", services.QuotaDownloads},
+		{"Ukuran download (MB)", services.QuotaDownloadBytes},
+		{"Sticker/gambar", services.QuotaMedia},
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📊 *Kuota Harian*\n")
+	for _, m := range metrics {
+		usage := h.store.Usage(userStr, groupStr, m.metric)
+		scale := int64(1)
+		if m.metric == services.QuotaDownloadBytes {
+			scale = bytesPerMB
+		}
+
+		sb.WriteString(fmt.Sprintf("\n• %s\n  Kamu: %s", m.label, formatQuota(usage.UserUsed, usage.Limits.PerUser, scale)))
+		if groupStr != "" {
+			sb.WriteString(fmt.Sprintf("\n  Grup: %s", formatQuota(usage.GroupUsed, usage.Limits.PerGroup, scale)))
+		}
+	}
+	sb.WriteString("\n\nKuota direset setiap hari.")
+
+	utils.ReplyTextDirect(client, evt, sb.String())
+}
+
+func formatQuota(used, limit, scale int64) string {
+	if limit <= 0 {
+		return fmt.Sprintf("%d terpakai (tanpa batas)", used/scale)
+	}
+	remaining := limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return fmt.Sprintf("sisa %d dari %d", remaining/scale, limit/scale)
+}
+
+// quotaSubjects returns the user and group keys used for quota accounting.
+// The group key is empty for private chats.
+func quotaSubjects(evt *events.Message) (string, string) {
+	groupStr := ""
+	if evt.Info.IsGroup {
+		groupStr = evt.Info.Chat.String()
+	}
+	return evt.Info.Sender.ToNonAD().String(), groupStr
+}
+
+// checkQuota replies with a notice and returns false if the sender or group has no quota left for metric today.
+func checkQuota(client *whatsmeow.Client, evt *events.Message, quotas *services.QuotaStore, metric services.QuotaMetric) bool {
+	userStr, groupStr := quotaSubjects(evt)
+	if quotas.Allow(userStr, groupStr, metric) {
+		return true
+	}
+	utils.ReplyTextDirect(client, evt, config.MsgQuotaExceeded)
+	return false
+}
+
+// reserveQuota records amount units of metric for the sender and group if they fit in today's quotas
+// and returns the reservation day. Otherwise it replies with a notice and returns false.
+func reserveQuota(client *whatsmeow.Client, evt *events.Message, quotas *services.QuotaStore, metric services.QuotaMetric, amount int64) (string, bool) {
+	userStr, groupStr := quotaSubjects(evt)
+	if day, ok := quotas.Reserve(userStr, groupStr, metric, amount); ok {
+		return day, true
+	}
+	utils.ReplyTextDirect(client, evt, config.MsgQuotaExceeded)
+	return "", false
+}
+
+// refundQuota gives back amount units of metric reserved for the sender and group on day.
+func refundQuota(evt *events.Message, quotas *services.QuotaStore, metric services.QuotaMetric, amount int64, day string) {
+	userStr, groupStr := quotaSubjects(evt)
+	quotas.Refund(userStr, groupStr, metric, amount, day)
+}
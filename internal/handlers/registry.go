@@ -11,9 +11,13 @@ import (
 // CommandHandler is a function type for handling commands.
 type CommandHandler func(client *whatsmeow.Client, evt *events.Message, args []string)
 
+// DefaultCommandCost is the rate limit cost of commands registered without an explicit cost.
+const DefaultCommandCost = 1.0
+
 // Registry manages command handlers.
 type Registry struct {
 	handlers map[string]CommandHandler
+	costs    map[string]float64
 	mu       sync.RWMutex
 }
 
@@ -21,14 +25,42 @@ type Registry struct {
 func NewRegistry() *Registry {
 	return &Registry{
 		handlers: make(map[string]CommandHandler),
+		costs:    make(map[string]float64),
 	}
 }
 
-// Register adds a new command handler.
+// Register adds a new command handler with the default rate limit cost.
 func (r *Registry) Register(command string, handler CommandHandler) {
+	r.RegisterWithCost(command, DefaultCommandCost, handler)
+}
+
+// RegisterWithCost adds a new command handler that consumes cost rate limit tokens per use.
+// Heavy commands (downloads, media conversion) should declare a higher cost.
+func (r *Registry) RegisterWithCost(command string, cost float64, handler CommandHandler) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.handlers[strings.ToLower(command)] = handler
+	command = strings.ToLower(command)
+	r.handlers[command] = handler
+	r.costs[command] = cost
+}
+
+// Cost returns the rate limit cost of a command.
+// Unknown commands cost DefaultCommandCost so they still count against the limiter.
+func (r *Registry) Cost(command string) float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if cost, ok := r.costs[strings.ToLower(command)]; ok {
+		return cost
+	}
+	return DefaultCommandCost
+}
+
+// Has reports whether a handler is registered for command.
+func (r *Registry) Has(command string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, exists := r.handlers[strings.ToLower(command)]
+	return exists
 }
 
 // Execute runs the handler for a given command.
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/ratelimit"
+	"chisa_bot/pkg/utils"
+)
+
+const maxSlowModeInterval = time.Hour
+
+// SlowModeHandler revokes messages from members who post again before the group's slow mode
+// interval has passed and handles the .slowmode command.
+type SlowModeHandler struct {
+	tracker      *ratelimit.SlowMode
+	store        *services.SlowModeStore
+	groupHandler *GroupHandler
+}
+
+// NewSlowModeHandler creates a new SlowModeHandler.
+func NewSlowModeHandler(store *services.SlowModeStore, groupHandler *GroupHandler) *SlowModeHandler {
+	return &SlowModeHandler{tracker: ratelimit.NewSlowMode(), store: store, groupHandler: groupHandler}
+}
+
+// CheckAndRevoke revokes a group message sent within the slow mode interval of the sender's
+// previous message. The first violation in an interval is answered with a notice.
+// Returns true if the message was revoked, false otherwise.
+func (h *SlowModeHandler) CheckAndRevoke(client *whatsmeow.Client, evt *events.Message) bool {
+	if !evt.Info.IsGroup || evt.Info.IsFromMe {
+		return false
+	}
+	// Deletions, edits and reactions are not new messages.
+	if evt.Message.GetProtocolMessage() != nil || evt.Message.GetReactionMessage() != nil {
+		return false
+	}
+
+	chat := evt.Info.Chat.String()
+	interval := h.store.Interval(chat)
+	if interval <= 0 {
+		return false
+	}
+
+	sender := evt.Info.Sender.ToNonAD()
+	result, wait := h.tracker.Check(h.groupHandler.userKey(sender), chat, interval)
+	if result == ratelimit.Allowed {
+		return false
+	}
+
+	// Only resolve admin status once a violation was found, since it may need a network call.
+	if h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		return false
+	}
+
+	slog.Info("Slow mode — revoking", "user", sender.User, "chat", chat, "wait", wait)
+
+	revokeMsg := client.BuildRevoke(evt.Info.Chat, evt.Info.Sender, evt.Info.ID)
+	if _, err := client.SendMessage(context.Background(), evt.Info.Chat, revokeMsg); err != nil {
+		slog.Error("failed to revoke slow mode message", "error", err)
+		return false
+	}
+
+	if result == ratelimit.UserCooldown {
+		// Round up so the notice never says to wait 0 seconds.
+		wait = max((wait + time.Second - 1).Truncate(time.Second), time.Second)
+		h.groupHandler.sendGroupMention(client, evt.Info.Chat,
+			fmt.Sprintf("🐢 @%s, slow mode aktif: 1 pesan per %s. Tunggu %s lagi.",
+				sender.User, utils.FormatDuration(interval), utils.FormatDuration(wait)),
+			[]string{sender.String()})
+	}
+	return true
+}
+
+// HandleSlowMode shows or changes the group's slow mode interval (admin only).
+// Usage: .slowmode [detik|durasi|off]
+func (h *SlowModeHandler) HandleSlowMode(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	usage := "Contoh:\n.slowmode 30 (1 pesan per 30 detik)\n.slowmode 5m\n.slowmode off"
+
+	if len(args) == 0 {
+		text := "🐢 Slow mode nonaktif di grup ini."
+		if interval := h.store.Interval(groupJID); interval > 0 {
+			text = fmt.Sprintf("🐢 Slow mode aktif: setiap member boleh mengirim 1 pesan per %s.", utils.FormatDuration(interval))
+		}
+		utils.ReplyTextDirect(client, evt, text+"\n\n"+usage)
+		return
+	}
+
+	var interval time.Duration
+	text := "Slow mode dinonaktifkan."
+	if strings.ToLower(args[0]) != "off" {
+		var ok bool
+		interval, ok = parseSlowModeInterval(args[0])
+		if !ok {
+			utils.ReplyTextDirect(client, evt, usage)
+			return
+		}
+		if interval > maxSlowModeInterval {
+			utils.ReplyTextDirect(client, evt, "Interval slow mode maksimal 1 jam.")
+			return
+		}
+		text = fmt.Sprintf("🐢 Slow mode aktif: setiap member boleh mengirim 1 pesan per %s. Pesan yang lebih cepat akan dihapus.",
+			utils.FormatDuration(interval))
+	}
+
+	if !h.store.SetInterval(groupJID, interval) {
+		text = "Gagal menyimpan pengaturan slow mode."
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
+
+// parseSlowModeInterval reads a number of seconds or a duration such as "5m".
+func parseSlowModeInterval(arg string) (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(arg); err == nil {
+		return time.Duration(seconds) * time.Second, seconds > 0
+	}
+	return utils.ParseDuration(arg)
+}
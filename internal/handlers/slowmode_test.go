@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSlowModeInterval(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"30", 30 * time.Second, true},
+		{"5m", 5 * time.Minute, true},
+		{"45s", 45 * time.Second, true},
+		{"0", 0, false},
+		{"-5", 0, false},
+		{"lama", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSlowModeInterval(tt.in)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("parseSlowModeInterval(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
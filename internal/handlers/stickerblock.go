@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+// blockHashPrefixLen is how many hex characters of a hash are shown in lists and accepted by unblock commands.
+const blockHashPrefixLen = 12
+
+// StickerBlockHandler revokes blocklisted stickers from anyone and handles the sticker blocklist commands.
+type StickerBlockHandler struct {
+	blocklist    *services.MediaBlocklist
+	groupHandler *GroupHandler
+}
+
+// NewStickerBlockHandler creates a new StickerBlockHandler.
+func NewStickerBlockHandler(blocklist *services.MediaBlocklist, groupHandler *GroupHandler) *StickerBlockHandler {
+	return &StickerBlockHandler{blocklist: blocklist, groupHandler: groupHandler}
+}
+
+// CheckAndRevoke revokes a sticker whose file hash is blocked in the group or globally.
+// Returns true if the message was revoked, false otherwise.
+func (h *StickerBlockHandler) CheckAndRevoke(client *whatsmeow.Client, evt *events.Message) bool {
+	if !evt.Info.IsGroup || evt.Info.IsFromMe {
+		return false
+	}
+
+	hash := stickerHash(evt.Message.GetStickerMessage().GetFileSHA256())
+	if hash == "" || !h.blocklist.Contains(services.BlockSticker, hash, evt.Info.Chat.String()) {
+		return false
+	}
+
+	slog.Info("Blocked sticker — revoking", "hash", hash[:blockHashPrefixLen], "user", evt.Info.Sender.User, "chat", evt.Info.Chat.String())
+
+	revokeMsg := client.BuildRevoke(evt.Info.Chat, evt.Info.Sender, evt.Info.ID)
+	if _, err := client.SendMessage(context.Background(), evt.Info.Chat, revokeMsg); err != nil {
+		slog.Error("failed to revoke blocked sticker", "error", err)
+		return false
+	}
+	return true
+}
+
+// HandleBlockSticker blocks the replied sticker in the group, or globally for owners (admin only).
+// Usage: reply to a sticker with .blocksticker [global]
+func (h *StickerBlockHandler) HandleBlockSticker(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	hash := stickerHash(utils.GetQuotedMessage(evt).GetStickerMessage().GetFileSHA256())
+	if hash == "" {
+		utils.ReplyTextDirect(client, evt, "Reply sticker yang ingin diblokir dengan .blocksticker [global]")
+		return
+	}
+
+	scope, ok := commandScope(client, evt, args, h.groupHandler)
+	if !ok {
+		return
+	}
+
+	where, list := scopeText(scope)
+	text := fmt.Sprintf("Sticker %s sekarang diblokir %s dan akan otomatis dihapus.", hash[:blockHashPrefixLen], where)
+	if !h.blocklist.Add(services.BlockedMedia{
+		Kind:   services.BlockSticker,
+		Hash:   hash,
+		Scope:  scope,
+		Issuer: evt.Info.Sender.ToNonAD().String(),
+	}) {
+		text = fmt.Sprintf("Sticker ini sudah ada di daftar blokir %s.", list)
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
+
+// HandleUnblockSticker unblocks the replied sticker, or the sticker with the given hash from .blockedstickers (admin only).
+// Usage: reply to a sticker with .unblocksticker [global] | .unblocksticker <hash> [global]
+func (h *StickerBlockHandler) HandleUnblockSticker(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	scope, ok := commandScope(client, evt, args, h.groupHandler)
+	if !ok {
+		return
+	}
+
+	hash := stickerHash(utils.GetQuotedMessage(evt).GetStickerMessage().GetFileSHA256())
+	if hash == "" {
+		hash = resolveBlockedHash(h.blocklist, services.BlockSticker, evt.Info.Chat.String(), scope, args)
+	}
+	if hash == "" {
+		utils.ReplyTextDirect(client, evt, "Reply sticker yang ingin dibuka blokirnya, atau sebutkan kodenya dari .blockedstickers.\nContoh: .unblocksticker 3f2a9c1b7d04")
+		return
+	}
+
+	where, list := scopeText(scope)
+	text := fmt.Sprintf("Blokir sticker %s %s sudah dicabut.", hash[:blockHashPrefixLen], where)
+	if !h.blocklist.Remove(services.BlockSticker, hash, scope) {
+		text = fmt.Sprintf("Sticker ini tidak ada di daftar blokir %s.", list)
+	}
+	utils.ReplyTextDirect(client, evt, text)
+}
+
+// HandleBlockedStickers lists the stickers blocked in the group, including global ones (admin only).
+// Usage: .blockedstickers
+func (h *StickerBlockHandler) HandleBlockedStickers(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	items := h.blocklist.List(services.BlockSticker, evt.Info.Chat.String())
+	if len(items) == 0 {
+		utils.ReplyTextDirect(client, evt, "Tidak ada sticker yang diblokir di grup ini.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🚫 *Sticker Diblokir*\n")
+	for i, item := range items {
+		_, list := scopeText(item.Scope)
+		sb.WriteString(fmt.Sprintf("\n%d. %s (%s, %s)", i+1, item.Hash[:blockHashPrefixLen], list, item.CreatedAt.Format("02/01/2006")))
+	}
+	sb.WriteString("\n\nGunakan .unblocksticker <kode> untuk membuka blokir.")
+	utils.ReplyTextDirect(client, evt, sb.String())
+}
+
+// resolveBlockedHash finds the blocklist entry in scope whose hash starts with the first non-keyword argument.
+// Returns an empty string if there is no single match.
+func resolveBlockedHash(blocklist *services.MediaBlocklist, kind services.BlockKind, groupJID, scope string, args []string) string {
+	var prefix string
+	for _, arg := range args {
+		if strings.ToLower(arg) != services.BanScopeGlobal {
+			prefix = strings.ToLower(arg)
+			break
+		}
+	}
+	if len(prefix) < 6 {
+		return ""
+	}
+
+	var match string
+	for _, item := range blocklist.List(kind, groupJID) {
+		if item.Scope != scope || !strings.HasPrefix(item.Hash, prefix) {
+			continue
+		}
+		if match != "" {
+			return "" // ambiguous
+		}
+		match = item.Hash
+	}
+	return match
+}
+
+// stickerHash hex-encodes a sticker's FileSHA256. Returns an empty string for non-stickers.
+func stickerHash(fileSHA256 []byte) string {
+	if len(fileSHA256)*2 < blockHashPrefixLen {
+		return ""
+	}
+	return hex.EncodeToString(fileSHA256)
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+)
+
+// WarnPolicy configures how warnings escalate. A threshold of 0 disables that step.
+type WarnPolicy struct {
+	MuteAt       int           // warnings that trigger a temporary chat ban
+	MuteDuration time.Duration // length of that ban
+	KickAt       int           // warnings that get the member removed from the group
+}
+
+// warnEscalation is the action taken after a warning.
+type warnEscalation int
+
+const (
+	escalateNone warnEscalation = iota
+	escalateMute
+	escalateKick
+)
+
+// escalation returns the action for a member who now has count warnings.
+// Every warning from MuteAt on renews the chat ban until KickAt is reached.
+func (p WarnPolicy) escalation(count int) warnEscalation {
+	switch {
+	case p.KickAt > 0 && count >= p.KickAt:
+		return escalateKick
+	case p.MuteAt > 0 && count >= p.MuteAt:
+		return escalateMute
+	}
+	return escalateNone
+}
+
+// WarnHandler records warnings, escalates repeat offenders and handles the warning commands.
+// Automatic moderators issue warnings through Warn.
+type WarnHandler struct {
+	store        *services.WarningStore
+	banStore     *services.BanStore
+	groupHandler *GroupHandler
+	policy       WarnPolicy
+}
+
+// NewWarnHandler creates a new WarnHandler.
+func NewWarnHandler(store *services.WarningStore, banStore *services.BanStore, groupHandler *GroupHandler, policy WarnPolicy) *WarnHandler {
+	return &WarnHandler{store: store, banStore: banStore, groupHandler: groupHandler, policy: policy}
+}
+
+// Warn records a warning against target in chat, announces it and applies the escalation policy.
+// issuer is the warning admin's JID; an empty issuer means the bot's automatic moderation.
+func (h *WarnHandler) Warn(client *whatsmeow.Client, chat, target types.JID, issuer, reason string) {
+	h.WarnWithNotice(client, chat, target, issuer, reason, "")
+}
+
+// WarnWithNotice is Warn for moderators that already punished the target themselves:
+// notice is sent as the first lines of the warning announcement, so the group gets one message.
+func (h *WarnHandler) WarnWithNotice(client *whatsmeow.Client, chat, target types.JID, issuer, reason, notice string) {
+	target = target.ToNonAD()
+	key := h.groupHandler.userKey(target)
+	if issuer == "" && client.Store.ID != nil {
+		issuer = client.Store.ID.ToNonAD().String()
+	}
+
+	count := h.store.Add(services.Warning{
+		GroupJID: chat.String(),
+		JID:      key,
+		Issuer:   issuer,
+		Reason:   reason,
+	})
+	if notice != "" {
+		notice += "\n"
+	}
+	if count == 0 {
+		h.groupHandler.sendGroupMention(client, chat, notice+"Gagal menyimpan peringatan.", []string{target.String()})
+		return
+	}
+	count = h.count(chat.String(), target)
+
+	text := notice + fmt.Sprintf("⚠️ @%s mendapat peringatan ke-%d", target.User, count)
+	if h.policy.KickAt > 0 {
+		text = notice + fmt.Sprintf("⚠️ @%s mendapat peringatan (%d/%d)", target.User, count, h.policy.KickAt)
+	}
+	if reason != "" {
+		text += "\nAlasan: " + reason
+	}
+
+	escalation := h.policy.escalation(count)
+	// Admins, owners and exceptions can be warned but are never muted or kicked.
+	if escalation != escalateNone && h.groupHandler.IsAdmin(client, chat, target) {
+		slog.Info("Not escalating warning for privileged member", "user", target.User, "chat", chat.String(), "count", count)
+		escalation = escalateNone
+	}
+
+	switch escalation {
+	case escalateMute:
+		expiresAt := time.Now().Add(h.policy.MuteDuration)
+		// Never shorten an existing chat ban, e.g. a permanent .banchat or a longer flood mute.
+		if h.chatBannedUntil(chat.String(), target, expiresAt) {
+			break
+		}
+		h.banStore.Add(services.Ban{
+			JID:        key,
+			Category:   services.BanChat,
+			Scope:      chat.String(),
+			ExpiresAt:  expiresAt,
+			OriginChat: chat.String(),
+			Issuer:     issuer,
+			Reason:     fmt.Sprintf("%d peringatan", count),
+		})
+		text += fmt.Sprintf("\n🔇 Dilarang chat selama %s.", utils.FormatDuration(h.policy.MuteDuration))
+
+	case escalateKick:
+		_, err := client.UpdateGroupParticipants(context.Background(), chat, []types.JID{target}, whatsmeow.ParticipantChangeRemove)
+		if err != nil {
+			slog.Error("failed to kick warned member", "error", err)
+			text += "\nGagal kick member. Pastikan bot adalah admin."
+			break
+		}
+		h.reset(chat.String(), target)
+		text += "\n👢 Dikeluarkan dari grup karena mencapai batas peringatan."
+	}
+
+	slog.Info("Member warned", "user", target.User, "chat", chat.String(), "count", count, "reason", reason)
+	h.groupHandler.sendGroupMention(client, chat, text, []string{target.String()})
+}
+
+// HandleWarn warns a member (admin only).
+// Usage: reply or tag user with .warn @user [alasan]
+func (h *WarnHandler) HandleWarn(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	targetJID, found := utils.GetTargetJID(evt)
+	if !found {
+		utils.ReplyTextDirect(client, evt, "Reply pesan atau tag member yang ingin diberi peringatan.\nContoh: .warn @member [alasan]")
+		return
+	}
+
+	if isBotJID(client, targetJID) {
+		utils.ReplyTextDirect(client, evt, "Tidak bisa memberi peringatan ke bot sendiri.")
+		return
+	}
+
+	if h.groupHandler.IsAdmin(client, evt.Info.Chat, targetJID) {
+		utils.ReplyTextDirect(client, evt, "Tidak bisa memberi peringatan ke admin, owner atau pengecualian bot.")
+		return
+	}
+
+	h.Warn(client, evt.Info.Chat, targetJID, h.groupHandler.senderJID(evt).String(), warnReason(args))
+}
+
+// HandleWarnings lists a member's warnings in the group. Without a target it shows the sender's own.
+// Usage: .warnings [@user]
+func (h *WarnHandler) HandleWarnings(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	targetJID, found := utils.GetTargetJID(evt)
+	if !found {
+		targetJID = evt.Info.Sender
+	}
+	target := targetJID.ToNonAD()
+
+	warnings := h.list(evt.Info.Chat.String(), target)
+	if len(warnings) == 0 {
+		utils.ReplyTextDirectWithMentions(client, evt, fmt.Sprintf("@%s tidak punya peringatan di grup ini.", target.User), []string{target.String()})
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⚠️ *Peringatan @%s* (%d", target.User, len(warnings)))
+	if h.policy.KickAt > 0 {
+		sb.WriteString(fmt.Sprintf("/%d", h.policy.KickAt))
+	}
+	sb.WriteString(")\n")
+	for i, w := range warnings {
+		reason := w.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		sb.WriteString(fmt.Sprintf("\n%d. %s — %s", i+1, w.CreatedAt.Format("02/01/2006 15:04"), reason))
+	}
+	utils.ReplyTextDirectWithMentions(client, evt, sb.String(), []string{target.String()})
+}
+
+// HandleResetWarn clears a member's warnings in the group (admin only).
+// Usage: reply or tag user with .resetwarn @user
+func (h *WarnHandler) HandleResetWarn(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	targetJID, found := utils.GetTargetJID(evt)
+	if !found {
+		utils.ReplyTextDirect(client, evt, "Reply pesan atau tag member yang peringatannya ingin dihapus.\nContoh: .resetwarn @member")
+		return
+	}
+
+	target := targetJID.ToNonAD()
+	mentionText := fmt.Sprintf("Semua peringatan @%s sudah dihapus.", target.User)
+	if h.reset(evt.Info.Chat.String(), target) == 0 {
+		mentionText = fmt.Sprintf("@%s tidak punya peringatan di grup ini.", target.User)
+	}
+	utils.ReplyTextDirectWithMentions(client, evt, mentionText, []string{target.String()})
+}
+
+// chatBannedUntil reports whether target already has a chat ban in the group that is permanent
+// or lasts at least until expiresAt, under any form of their JID.
+func (h *WarnHandler) chatBannedUntil(groupJID string, target types.JID, expiresAt time.Time) bool {
+	for _, key := range h.groupHandler.userKeys(target) {
+		if until, ok := h.banStore.ActiveExpiry(key, services.BanChat, groupJID); ok && (until.IsZero() || !until.Before(expiresAt)) {
+			return true
+		}
+	}
+	return false
+}
+
+// count returns the target's warnings in the group under every form of their JID, since warnings
+// may have been stored under a LID before its phone number was known.
+func (h *WarnHandler) count(groupJID string, target types.JID) int {
+	count := 0
+	for _, key := range h.groupHandler.userKeys(target) {
+		count += h.store.Count(groupJID, key)
+	}
+	return count
+}
+
+// list returns the target's warnings in the group under every form of their JID, oldest first.
+func (h *WarnHandler) list(groupJID string, target types.JID) []services.Warning {
+	var warnings []services.Warning
+	for _, key := range h.groupHandler.userKeys(target) {
+		warnings = append(warnings, h.store.List(groupJID, key)...)
+	}
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].CreatedAt.Before(warnings[j].CreatedAt) })
+	return warnings
+}
+
+// reset clears the target's warnings in the group under every form of their JID.
+// Returns the number of warnings removed.
+func (h *WarnHandler) reset(groupJID string, target types.JID) int {
+	removed := 0
+	for _, key := range h.groupHandler.userKeys(target) {
+		removed += h.store.Reset(groupJID, key)
+	}
+	return removed
+}
+
+// warnReason joins the words after the mention into the warning reason.
+func warnReason(args []string) string {
+	var words []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "@") {
+			continue
+		}
+		words = append(words, arg)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"chisa_bot/internal/services"
+)
+
+func TestWarnPolicy_Escalation(t *testing.T) {
+	policy := WarnPolicy{MuteAt: 3, MuteDuration: time.Hour, KickAt: 5}
+
+	tests := []struct {
+		count int
+		want  warnEscalation
+	}{
+		{1, escalateNone},
+		{2, escalateNone},
+		{3, escalateMute},
+		{4, escalateMute},
+		{5, escalateKick},
+		{6, escalateKick},
+	}
+	for _, tt := range tests {
+		if got := policy.escalation(tt.count); got != tt.want {
+			t.Errorf("escalation(%d) = %v, want %v", tt.count, got, tt.want)
+		}
+	}
+
+	if got := (WarnPolicy{}).escalation(10); got != escalateNone {
+		t.Errorf("disabled policy escalation = %v, want none", got)
+	}
+}
+
+func TestWarnReason(t *testing.T) {
+	if got := warnReason([]string{"@628123", "kirim", "link", "judi"}); got != "kirim link judi" {
+		t.Errorf("warnReason() = %q", got)
+	}
+	if got := warnReason(nil); got != "" {
+		t.Errorf("warnReason(nil) = %q, want empty", got)
+	}
+}
+
+func TestWarnHandlerMatchesBothForms(t *testing.T) {
+	db := newTestDB(t)
+	groupHandler := NewGroupHandler(services.NewGroupCache(time.Minute), services.NewIdentity(lidMap{lid: "111", pn: "628111"}))
+	banStore := services.NewBanStore(db)
+	h := NewWarnHandler(services.NewWarningStore(db), banStore, groupHandler, WarnPolicy{MuteAt: 1, MuteDuration: time.Hour})
+	lid := types.NewJID("111", types.HiddenUserServer)
+	pn := types.NewJID("628111", types.DefaultUserServer)
+
+	// One warning stored under the LID before the phone number was known, one under the phone number.
+	h.store.Add(services.Warning{GroupJID: "g1@g.us", JID: lid.String(), Reason: "lama"})
+	h.store.Add(services.Warning{GroupJID: "g1@g.us", JID: pn.String(), Reason: "baru"})
+
+	if got := h.count("g1@g.us", lid); got != 2 {
+		t.Errorf("count() = %d, want 2", got)
+	}
+	if got := h.list("g1@g.us", pn); len(got) != 2 {
+		t.Errorf("list() returned %d warnings, want 2", len(got))
+	}
+	if got := h.reset("g1@g.us", pn); got != 2 {
+		t.Errorf("reset() removed %d warnings, want 2", got)
+	}
+	if got := h.count("g1@g.us", pn); got != 0 {
+		t.Errorf("count() after reset = %d, want 0", got)
+	}
+
+	// A permanent chat ban under the LID is never shortened by a warning mute.
+	banStore.Add(services.Ban{JID: lid.String(), Category: services.BanChat, Scope: "g1@g.us"})
+	if !h.chatBannedUntil("g1@g.us", pn, time.Now().Add(time.Hour)) {
+		t.Error("a permanent ban should cover the warning mute")
+	}
+	banStore.Add(services.Ban{JID: pn.String(), Category: services.BanChat, Scope: "g2@g.us", ExpiresAt: time.Now().Add(time.Minute)})
+	if h.chatBannedUntil("g2@g.us", pn, time.Now().Add(time.Hour)) {
+		t.Error("a shorter ban should not cover the warning mute")
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"chisa_bot/internal/config"
+	"chisa_bot/internal/services"
+	"chisa_bot/pkg/utils"
+	"chisa_bot/pkg/wordfilter"
+)
+
+// WordFilterHandler revokes messages containing blocked words and handles the .filter command.
+type WordFilterHandler struct {
+	store        *services.WordFilterStore
+	groupHandler *GroupHandler
+	warnHandler  *WarnHandler
+
+	// Compiled rules per group, rebuilt after a group's rules change.
+	mu       sync.RWMutex
+	matchers map[string]*wordfilter.Matcher
+}
+
+// NewWordFilterHandler creates a new WordFilterHandler.
+func NewWordFilterHandler(store *services.WordFilterStore, groupHandler *GroupHandler, warnHandler *WarnHandler) *WordFilterHandler {
+	return &WordFilterHandler{
+		store:        store,
+		groupHandler: groupHandler,
+		warnHandler:  warnHandler,
+		matchers:     make(map[string]*wordfilter.Matcher),
+	}
+}
+
+// CheckAndRevoke revokes group messages that match one of the group's filter rules.
+// Returns true if the message was revoked, false otherwise.
+func (h *WordFilterHandler) CheckAndRevoke(client *whatsmeow.Client, evt *events.Message) bool {
+	if !evt.Info.IsGroup || evt.Info.IsFromMe {
+		return false
+	}
+
+	text := moderationText(evt)
+	if text == "" {
+		return false
+	}
+
+	chat := evt.Info.Chat.String()
+	rule, matched := h.matcher(chat).Match(text)
+	if !matched {
+		return false
+	}
+
+	if h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		return false
+	}
+
+	slog.Info("Filtered word — revoking", "rule", rule.String(), "user", evt.Info.Sender.User, "chat", chat)
+
+	revokeMsg := client.BuildRevoke(evt.Info.Chat, evt.Info.Sender, evt.Info.ID)
+	if _, err := client.SendMessage(context.Background(), evt.Info.Chat, revokeMsg); err != nil {
+		slog.Error("failed to revoke filtered message", "error", err)
+		return false
+	}
+
+	if h.store.Warn(chat) {
+		h.warnHandler.Warn(client, evt.Info.Chat, evt.Info.Sender, "", "kata terlarang")
+	}
+	return true
+}
+
+// matcher returns the compiled rules of a group, loading them on first use.
+func (h *WordFilterHandler) matcher(groupJID string) *wordfilter.Matcher {
+	h.mu.RLock()
+	m, ok := h.matchers[groupJID]
+	h.mu.RUnlock()
+	if ok {
+		return m
+	}
+
+	m, err := wordfilter.New(h.store.Rules(groupJID))
+	if err != nil {
+		slog.Error("skipping invalid word filter rules", "group", groupJID, "error", err)
+	}
+
+	h.mu.Lock()
+	h.matchers[groupJID] = m
+	h.mu.Unlock()
+	return m
+}
+
+// invalidate drops a group's compiled rules so the next message reloads them.
+func (h *WordFilterHandler) invalidate(groupJID string) {
+	h.mu.Lock()
+	delete(h.matchers, groupJID)
+	h.mu.Unlock()
+}
+
+// HandleFilter manages the group's word filter (admin only).
+// Words are matched ignoring case, leetspeak and repeated letters; /.../ is a regular expression.
+// Usage: .filter add <kata|/regex/> | .filter remove <kata|/regex/> | .filter list | .filter warn on|off
+func (h *WordFilterHandler) HandleFilter(client *whatsmeow.Client, evt *events.Message, args []string) {
+	if !evt.Info.IsGroup {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyGroup)
+		return
+	}
+
+	if !h.groupHandler.IsAdmin(client, evt.Info.Chat, evt.Info.Sender) {
+		utils.ReplyTextDirect(client, evt, config.MsgOnlyAdmin)
+		return
+	}
+
+	groupJID := evt.Info.Chat.String()
+	if len(args) == 0 {
+		utils.ReplyTextDirect(client, evt, "Contoh:\n.filter add slot gacor\n.filter add /sl[o0]t\\s*gacor/\n.filter remove slot gacor\n.filter list\n.filter warn on|off")
+		return
+	}
+
+	switch sub := strings.ToLower(args[0]); sub {
+	case "list":
+		h.sendList(client, evt, groupJID)
+
+	case "warn":
+		if len(args) < 2 || (args[1] != "on" && args[1] != "off") {
+			utils.ReplyTextDirect(client, evt, "Contoh: .filter warn on|off")
+			return
+		}
+		warn := args[1] == "on"
+		text := "Pengirim kata terlarang sekarang juga mendapat peringatan."
+		if !warn {
+			text = "Pesan dengan kata terlarang hanya dihapus tanpa peringatan."
+		}
+		if !h.store.SetWarn(groupJID, warn) {
+			text = "Gagal menyimpan pengaturan filter."
+		}
+		utils.ReplyTextDirect(client, evt, text)
+
+	case "add", "remove":
+		if len(args) < 2 {
+			utils.ReplyTextDirect(client, evt, fmt.Sprintf("Contoh: .filter %s slot gacor", sub))
+			return
+		}
+		rule, err := wordfilter.ParseRule(strings.Join(args[1:], " "))
+		if err != nil {
+			utils.ReplyTextDirect(client, evt, "Filter tidak valid: "+err.Error())
+			return
+		}
+
+		var text string
+		if sub == "add" {
+			text = fmt.Sprintf("Filter %s ditambahkan.", rule)
+			if !h.store.Add(groupJID, rule) {
+				text = fmt.Sprintf("Filter %s sudah ada.", rule)
+			}
+		} else {
+			text = fmt.Sprintf("Filter %s dihapus.", rule)
+			if !h.store.Remove(groupJID, rule) {
+				text = fmt.Sprintf("Filter %s tidak ditemukan.", rule)
+			}
+		}
+		h.invalidate(groupJID)
+		utils.ReplyTextDirect(client, evt, text)
+
+	default:
+		utils.ReplyTextDirect(client, evt, "Sub-perintah tidak dikenal. Pilihan: add, remove, list, warn")
+	}
+}
+
+func (h *WordFilterHandler) sendList(client *whatsmeow.Client, evt *events.Message, groupJID string) {
+	rules := h.store.Rules(groupJID)
+	if len(rules) == 0 {
+		utils.ReplyTextDirect(client, evt, "Belum ada kata yang difilter di grup ini.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🚫 *Filter Kata*\n")
+	for i, rule := range rules {
+		sb.WriteString(fmt.Sprintf("\n%d. %s", i+1, rule))
+	}
+	if h.store.Warn(groupJID) {
+		sb.WriteString("\n\nPengirim mendapat peringatan.")
+	}
+	utils.ReplyTextDirect(client, evt, sb.String())
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// MemberActivity is a member's message count and last activity in a group.
+// Joining a group counts as activity, so new members are not reported as inactive right away.
+type MemberActivity struct {
+	JID        string
+	Messages   int
+	LastActive time.Time
+}
+
+// pendingActivity accumulates activity between flushes.
+type pendingActivity struct {
+	groupJID, jid string
+	messages      int
+	last          time.Time
+	seen          time.Time // wall clock time the activity was first buffered
+}
+
+// ActivityStore keeps per-group member activity counters.
+// Activity is recorded for every group message, so it is buffered in memory and written in batches.
+type ActivityStore struct {
+	db *sql.DB
+
+	mu      sync.Mutex
+	pending map[string]*pendingActivity
+}
+
+// NewActivityStore creates a new store and ensures the tables exist.
+func NewActivityStore(db *sql.DB) *ActivityStore {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS member_activity (
+			group_jid TEXT NOT NULL,
+			jid TEXT NOT NULL,
+			message_count INTEGER NOT NULL DEFAULT 0,
+			last_active INTEGER NOT NULL,
+			PRIMARY KEY (group_jid, jid)
+		);
+		CREATE TABLE IF NOT EXISTS activity_tracking (
+			group_jid TEXT PRIMARY KEY,
+			since INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create activity tables", "error", err)
+		os.Exit(1)
+	}
+	return &ActivityStore{db: db, pending: make(map[string]*pendingActivity)}
+}
+
+// RecordMessage counts a message from a member.
+func (s *ActivityStore) RecordMessage(groupJID, jid string, at time.Time) {
+	s.add(groupJID, jid, 1, at)
+}
+
+// RecordJoin marks a member as active when they join, without counting a message.
+func (s *ActivityStore) RecordJoin(groupJID, jid string, at time.Time) {
+	s.add(groupJID, jid, 0, at)
+}
+
+func (s *ActivityStore) add(groupJID, jid string, messages int, at time.Time) {
+	key := groupJID + "|" + jid
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.pending[key]
+	if !ok {
+		p = &pendingActivity{groupJID: groupJID, jid: jid, seen: time.Now()}
+		s.pending[key] = p
+	}
+	p.messages += messages
+	if at.After(p.last) {
+		p.last = at
+	}
+}
+
+// Flush writes buffered activity to the database. If the write fails, the activity is
+// buffered again and retried on the next flush.
+func (s *ActivityStore) Flush() {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = make(map[string]*pendingActivity)
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := s.write(batch); err != nil {
+		slog.Error("failed to flush member activity", "error", err)
+		s.requeue(batch)
+	}
+}
+
+// write stores a batch in one transaction. Tracking starts at the wall clock time the
+// group's first activity was buffered, not at message timestamps, which may be older
+// for messages delivered after the bot was offline.
+func (s *ActivityStore) write(batch map[string]*pendingActivity) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, p := range batch {
+		_, err = tx.Exec(`INSERT INTO member_activity (group_jid, jid, message_count, last_active) VALUES (?, ?, ?, ?)
+			ON CONFLICT(group_jid, jid) DO UPDATE SET
+				message_count = message_count + excluded.message_count,
+				last_active = MAX(last_active, excluded.last_active)`,
+			p.groupJID, p.jid, p.messages, p.last.Unix())
+		if err == nil {
+			_, err = tx.Exec(`INSERT INTO activity_tracking (group_jid, since) VALUES (?, ?)
+				ON CONFLICT(group_jid) DO UPDATE SET since = MIN(since, excluded.since)`, p.groupJID, p.seen.Unix())
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// requeue merges a batch that could not be written back into the buffer.
+func (s *ActivityStore) requeue(batch map[string]*pendingActivity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, p := range batch {
+		cur, ok := s.pending[key]
+		if !ok {
+			s.pending[key] = p
+			continue
+		}
+		cur.messages += p.messages
+		if p.last.After(cur.last) {
+			cur.last = p.last
+		}
+		if p.seen.Before(cur.seen) {
+			cur.seen = p.seen
+		}
+	}
+}
+
+// StartFlusher runs a background goroutine that flushes buffered activity every interval
+// and once more when ctx is cancelled.
+func (s *ActivityStore) StartFlusher(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				s.Flush()
+				return
+			case <-ticker.C:
+				s.Flush()
+			}
+		}
+	}()
+}
+
+// Members returns the recorded activity of a group, keyed by member JID.
+func (s *ActivityStore) Members(groupJID string) map[string]MemberActivity {
+	s.Flush()
+
+	rows, err := s.db.Query(`SELECT jid, message_count, last_active FROM member_activity WHERE group_jid = ?`, groupJID)
+	if err != nil {
+		slog.Error("failed to read member activity", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	members := make(map[string]MemberActivity)
+	for rows.Next() {
+		a, err := scanActivity(rows)
+		if err != nil {
+			continue
+		}
+		members[a.JID] = a
+	}
+	return members
+}
+
+// Top returns the members with the most messages in a group.
+func (s *ActivityStore) Top(groupJID string, limit int) []MemberActivity {
+	s.Flush()
+
+	rows, err := s.db.Query(`SELECT jid, message_count, last_active FROM member_activity
+		WHERE group_jid = ? AND message_count > 0 ORDER BY message_count DESC, last_active DESC LIMIT ?`, groupJID, limit)
+	if err != nil {
+		slog.Error("failed to read activity leaderboard", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var top []MemberActivity
+	for rows.Next() {
+		a, err := scanActivity(rows)
+		if err != nil {
+			continue
+		}
+		top = append(top, a)
+	}
+	return top
+}
+
+// TrackingSince returns when activity was first recorded in a group.
+func (s *ActivityStore) TrackingSince(groupJID string) (time.Time, bool) {
+	s.Flush()
+
+	var since int64
+	err := s.db.QueryRow(`SELECT since FROM activity_tracking WHERE group_jid = ?`, groupJID).Scan(&since)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			slog.Error("failed to read activity tracking start", "error", err)
+		}
+		return time.Time{}, false
+	}
+	return time.Unix(since, 0), true
+}
+
+func scanActivity(rows *sql.Rows) (MemberActivity, error) {
+	var a MemberActivity
+	var last int64
+	if err := rows.Scan(&a.JID, &a.Messages, &last); err != nil {
+		slog.Error("failed to scan member activity", "error", err)
+		return a, err
+	}
+	a.LastActive = time.Unix(last, 0)
+	return a, nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActivityStore(t *testing.T) {
+	store := NewActivityStore(newTestDB(t))
+	start := time.Unix(1_700_000_000, 0)
+	tracked := time.Now().Truncate(time.Second)
+
+	if _, ok := store.TrackingSince("g1@g.us"); ok {
+		t.Error("group without activity should not be tracked yet")
+	}
+
+	store.RecordMessage("g1@g.us", "a@s.whatsapp.net", start)
+	store.RecordMessage("g1@g.us", "a@s.whatsapp.net", start.Add(time.Minute))
+	store.RecordMessage("g1@g.us", "b@s.whatsapp.net", start.Add(2*time.Minute))
+	store.RecordJoin("g1@g.us", "c@s.whatsapp.net", start.Add(3*time.Minute))
+	store.RecordMessage("g2@g.us", "a@s.whatsapp.net", start)
+	store.Flush()
+
+	// Counters keep adding up across flushes, and an older timestamp does not move last activity back.
+	store.RecordMessage("g1@g.us", "a@s.whatsapp.net", start.Add(-time.Hour))
+
+	members := store.Members("g1@g.us")
+	if len(members) != 3 {
+		t.Fatalf("Members() returned %d members, want 3", len(members))
+	}
+	if a := members["a@s.whatsapp.net"]; a.Messages != 3 || !a.LastActive.Equal(start.Add(time.Minute)) {
+		t.Errorf("member a = %+v, want 3 messages last active at %v", a, start.Add(time.Minute))
+	}
+	if c := members["c@s.whatsapp.net"]; c.Messages != 0 || !c.LastActive.Equal(start.Add(3*time.Minute)) {
+		t.Errorf("joined member c = %+v, want no messages and active at join", c)
+	}
+
+	top := store.Top("g1@g.us", 10)
+	if len(top) != 2 || top[0].JID != "a@s.whatsapp.net" || top[1].JID != "b@s.whatsapp.net" {
+		t.Errorf("Top() = %+v, want a then b (members without messages excluded)", top)
+	}
+
+	// Tracking starts when the bot first saw activity, not at the (older) message timestamps.
+	if since, ok := store.TrackingSince("g1@g.us"); !ok || since.Before(tracked) || since.After(time.Now()) {
+		t.Errorf("TrackingSince() = %v, %v; want the time the first activity was recorded", since, ok)
+	}
+}
+
+func TestActivityStore_FlushRetriesFailedBatch(t *testing.T) {
+	db := newTestDB(t)
+	store := NewActivityStore(db)
+	at := time.Unix(1_700_000_000, 0)
+
+	store.RecordMessage("g1@g.us", "a@s.whatsapp.net", at)
+	if _, err := db.Exec(`ALTER TABLE member_activity RENAME TO member_activity_away`); err != nil {
+		t.Fatal(err)
+	}
+	store.Flush()
+	if _, err := db.Exec(`ALTER TABLE member_activity_away RENAME TO member_activity`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Activity recorded after the failed flush is merged with the batch that was kept.
+	store.RecordMessage("g1@g.us", "a@s.whatsapp.net", at.Add(time.Minute))
+
+	a := store.Members("g1@g.us")["a@s.whatsapp.net"]
+	if a.Messages != 2 || !a.LastActive.Equal(at.Add(time.Minute)) {
+		t.Errorf("member a = %+v, want 2 messages last active at %v", a, at.Add(time.Minute))
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// AntiDeleteStore manages which groups have opted in to anti-delete.
+// The setting is read for every group message, so enabled groups are kept in memory.
+type AntiDeleteStore struct {
+	db *sql.DB
+
+	mu      sync.RWMutex
+	enabled map[string]bool
+}
+
+// NewAntiDeleteStore creates a new store, ensures the table exists and loads the enabled groups.
+func NewAntiDeleteStore(db *sql.DB) *AntiDeleteStore {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS antidelete_groups (group_jid TEXT PRIMARY KEY)`)
+	if err != nil {
+		slog.Error("Failed to create antidelete_groups table", "error", err)
+		os.Exit(1)
+	}
+
+	store := &AntiDeleteStore{db: db, enabled: make(map[string]bool)}
+
+	rows, err := db.Query(`SELECT group_jid FROM antidelete_groups`)
+	if err != nil {
+		slog.Error("Failed to load anti-delete groups", "error", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var groupJID string
+		if err := rows.Scan(&groupJID); err == nil {
+			store.enabled[groupJID] = true
+		}
+	}
+	return store
+}
+
+// Enabled reports whether anti-delete is on in a group.
+func (s *AntiDeleteStore) Enabled(groupJID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.enabled[groupJID]
+}
+
+// SetEnabled turns anti-delete on or off in a group.
+func (s *AntiDeleteStore) SetEnabled(groupJID string, enabled bool) bool {
+	var err error
+	if enabled {
+		_, err = s.db.Exec(`INSERT OR IGNORE INTO antidelete_groups (group_jid) VALUES (?)`, groupJID)
+	} else {
+		_, err = s.db.Exec(`DELETE FROM antidelete_groups WHERE group_jid = ?`, groupJID)
+	}
+	if err != nil {
+		slog.Error("failed to set anti-delete", "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	if enabled {
+		s.enabled[groupJID] = true
+	} else {
+		delete(s.enabled, groupJID)
+	}
+	s.mu.Unlock()
+	return true
+}
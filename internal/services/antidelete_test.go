@@ -0,0 +1,20 @@
+package services
+
+import "testing"
+
+func TestAntiDeleteStore(t *testing.T) {
+	db := newTestDB(t)
+	store := NewAntiDeleteStore(db)
+
+	if store.Enabled("g1@g.us") {
+		t.Error("anti-delete should be off by default")
+	}
+	store.SetEnabled("g1@g.us", true)
+	if !NewAntiDeleteStore(db).Enabled("g1@g.us") {
+		t.Error("enabled groups should be loaded on startup")
+	}
+	store.SetEnabled("g1@g.us", false)
+	if store.Enabled("g1@g.us") {
+		t.Error("SetEnabled(false) should turn anti-delete off")
+	}
+}
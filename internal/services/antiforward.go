@@ -0,0 +1,131 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// ForwardSettings holds a group's policy for forwarded messages.
+// An empty action leaves that kind of message alone.
+type ForwardSettings struct {
+	Forwarded     ModerationAction // applied to every forwarded message
+	Frequent      ModerationAction // applied to messages forwarded many times
+	FrequentScore int              // forwarding score from which a message counts as forwarded many times
+}
+
+// Enabled reports whether the policy acts on any forwarded message.
+func (s ForwardSettings) Enabled() bool {
+	return s.Forwarded != "" || s.Frequent != ""
+}
+
+// Action returns what to do with a message with the given forwarded flag and forwarding score,
+// and whether it counts as forwarded many times. Messages forwarded many times get the stronger
+// of both actions. An empty action means the message is allowed.
+func (s ForwardSettings) Action(forwarded bool, score uint32) (ModerationAction, bool) {
+	if !forwarded && score == 0 {
+		return "", false
+	}
+	frequent := s.FrequentScore > 0 && score >= uint32(s.FrequentScore)
+	if !frequent || actionRank(s.Forwarded) >= actionRank(s.Frequent) {
+		return s.Forwarded, frequent
+	}
+	return s.Frequent, frequent
+}
+
+// actionRank orders moderation actions by severity, with no action lowest.
+func actionRank(action ModerationAction) int {
+	switch action {
+	case ActionRevoke:
+		return 1
+	case ActionWarn:
+		return 2
+	case ActionKick:
+		return 3
+	}
+	return 0
+}
+
+// ForwardStore manages persistent per-group forwarded-message policies.
+// Policies are read for every forwarded group message, so they are cached in memory.
+type ForwardStore struct {
+	db       *sql.DB
+	defaults ForwardSettings
+
+	mu    sync.RWMutex
+	cache map[string]ForwardSettings
+}
+
+// NewForwardStore creates a new store and ensures the table exists.
+// frequentScore is the default threshold for messages forwarded many times; the policy is off until a group sets it.
+func NewForwardStore(db *sql.DB, frequentScore int) *ForwardStore {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS forward_settings (
+			group_jid TEXT PRIMARY KEY,
+			forwarded TEXT NOT NULL DEFAULT '',
+			frequent TEXT NOT NULL DEFAULT '',
+			frequent_score INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create forward_settings table", "error", err)
+		os.Exit(1)
+	}
+
+	return &ForwardStore{
+		db:       db,
+		defaults: ForwardSettings{FrequentScore: frequentScore},
+		cache:    make(map[string]ForwardSettings),
+	}
+}
+
+// Settings returns the policy of a group, falling back to the defaults.
+func (s *ForwardStore) Settings(groupJID string) ForwardSettings {
+	s.mu.RLock()
+	settings, ok := s.cache[groupJID]
+	s.mu.RUnlock()
+	if ok {
+		return settings
+	}
+
+	var forwarded, frequent string
+	err := s.db.QueryRow(`SELECT forwarded, frequent, frequent_score FROM forward_settings WHERE group_jid = ?`, groupJID).
+		Scan(&forwarded, &frequent, &settings.FrequentScore)
+	switch {
+	case err == nil:
+		settings.Forwarded = ModerationAction(forwarded)
+		settings.Frequent = ModerationAction(frequent)
+	case err == sql.ErrNoRows:
+		settings = s.defaults
+	default:
+		// Do not cache on errors so the next message retries.
+		slog.Error("failed to load forward settings", "error", err)
+		return s.defaults
+	}
+
+	s.mu.Lock()
+	s.cache[groupJID] = settings
+	s.mu.Unlock()
+	return settings
+}
+
+// SetSettings stores a group's policy.
+func (s *ForwardStore) SetSettings(groupJID string, settings ForwardSettings) bool {
+	_, err := s.db.Exec(`
+		INSERT INTO forward_settings (group_jid, forwarded, frequent, frequent_score) VALUES (?, ?, ?, ?)
+		ON CONFLICT (group_jid) DO UPDATE SET
+			forwarded = excluded.forwarded,
+			frequent = excluded.frequent,
+			frequent_score = excluded.frequent_score
+	`, groupJID, string(settings.Forwarded), string(settings.Frequent), settings.FrequentScore)
+	if err != nil {
+		slog.Error("failed to set forward settings", "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	s.cache[groupJID] = settings
+	s.mu.Unlock()
+	return true
+}
@@ -0,0 +1,48 @@
+package services
+
+import "testing"
+
+func TestForwardSettings_Action(t *testing.T) {
+	tests := []struct {
+		name         string
+		settings     ForwardSettings
+		forwarded    bool
+		score        uint32
+		wantAction   ModerationAction
+		wantFrequent bool
+	}{
+		{"not forwarded", ForwardSettings{Forwarded: ActionWarn, FrequentScore: 5}, false, 0, "", false},
+		{"forwarded once", ForwardSettings{Forwarded: ActionRevoke, FrequentScore: 5}, true, 1, ActionRevoke, false},
+		{"score without flag", ForwardSettings{Forwarded: ActionRevoke, FrequentScore: 5}, false, 2, ActionRevoke, false},
+		{"only frequent, forwarded once", ForwardSettings{Frequent: ActionWarn, FrequentScore: 5}, true, 4, "", false},
+		{"only frequent, forwarded many times", ForwardSettings{Frequent: ActionWarn, FrequentScore: 5}, true, 5, ActionWarn, true},
+		{"frequent is stronger", ForwardSettings{Forwarded: ActionRevoke, Frequent: ActionWarn, FrequentScore: 5}, true, 127, ActionWarn, true},
+		{"forwarded is stronger", ForwardSettings{Forwarded: ActionWarn, Frequent: ActionRevoke, FrequentScore: 5}, true, 10, ActionWarn, true},
+		{"no threshold", ForwardSettings{Frequent: ActionWarn}, true, 100, "", false},
+	}
+	for _, tt := range tests {
+		action, frequent := tt.settings.Action(tt.forwarded, tt.score)
+		if action != tt.wantAction || frequent != tt.wantFrequent {
+			t.Errorf("%s: Action(%v, %d) = %q, %v, want %q, %v", tt.name, tt.forwarded, tt.score, action, frequent, tt.wantAction, tt.wantFrequent)
+		}
+	}
+}
+
+func TestForwardStore_Settings(t *testing.T) {
+	db := newTestDB(t)
+	store := NewForwardStore(db, 5)
+
+	if got := store.Settings("g1@g.us"); got.Enabled() || got.FrequentScore != 5 {
+		t.Errorf("default settings = %+v, want off with score 5", got)
+	}
+
+	want := ForwardSettings{Forwarded: ActionRevoke, Frequent: ActionWarn, FrequentScore: 10}
+	if !store.SetSettings("g1@g.us", want) {
+		t.Fatal("SetSettings failed")
+	}
+
+	// A fresh store reads the settings back from the database.
+	if got := NewForwardStore(db, 5).Settings("g1@g.us"); got != want {
+		t.Errorf("Settings() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// AntiLinkMode selects which links the anti-link filter acts on.
+type AntiLinkMode string
+
+const (
+	AntiLinkOff    AntiLinkMode = "off"    // filter disabled
+	AntiLinkInvite AntiLinkMode = "invite" // only WhatsApp group invites and denied domains
+	AntiLinkAll    AntiLinkMode = "all"    // every link except allowed domains
+)
+
+// ModerationAction is what an automatic moderator does with an offending message.
+type ModerationAction string
+
+const (
+	ActionRevoke ModerationAction = "revoke" // delete the message
+	ActionWarn   ModerationAction = "warn"   // delete the message and warn the sender
+	ActionKick   ModerationAction = "kick"   // delete the message and remove the sender
+)
+
+// ParseModerationAction returns the action named by s, if any.
+func ParseModerationAction(s string) (ModerationAction, bool) {
+	switch action := ModerationAction(strings.ToLower(s)); action {
+	case ActionRevoke, ActionWarn, ActionKick:
+		return action, true
+	}
+	return "", false
+}
+
+// AntiLinkSettings holds a group's anti-link configuration.
+type AntiLinkSettings struct {
+	Mode   AntiLinkMode
+	Action ModerationAction
+	Allow  []string // domains that are never acted on
+	Deny   []string // domains that are always acted on, even in invite mode
+}
+
+// AntiLinkStore manages the persistent per-group anti-link configuration.
+type AntiLinkStore struct {
+	db *sql.DB
+}
+
+// NewAntiLinkStore creates a new store and ensures the tables exist.
+func NewAntiLinkStore(db *sql.DB) *AntiLinkStore {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS antilink_settings (
+			group_jid TEXT PRIMARY KEY,
+			mode TEXT NOT NULL DEFAULT 'off',
+			action TEXT NOT NULL DEFAULT 'revoke'
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create antilink_settings table", "error", err)
+		os.Exit(1)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS antilink_domains (
+			group_jid TEXT NOT NULL,
+			domain TEXT NOT NULL,
+			allowed INTEGER NOT NULL,
+			PRIMARY KEY (group_jid, domain)
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create antilink_domains table", "error", err)
+		os.Exit(1)
+	}
+
+	return &AntiLinkStore{db: db}
+}
+
+// Settings returns a group's configuration. Groups without a row are off with the revoke action.
+// Domain lists are only loaded when the filter is enabled.
+func (s *AntiLinkStore) Settings(groupJID string) AntiLinkSettings {
+	settings := AntiLinkSettings{Mode: AntiLinkOff, Action: ActionRevoke}
+
+	var mode, action string
+	err := s.db.QueryRow(`SELECT mode, action FROM antilink_settings WHERE group_jid = ?`, groupJID).Scan(&mode, &action)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			slog.Error("failed to load anti-link settings", "error", err)
+		}
+		return settings
+	}
+	settings.Mode = AntiLinkMode(mode)
+	settings.Action = ModerationAction(action)
+	if settings.Mode == AntiLinkOff {
+		return settings
+	}
+
+	rows, err := s.db.Query(`SELECT domain, allowed FROM antilink_domains WHERE group_jid = ? ORDER BY domain`, groupJID)
+	if err != nil {
+		slog.Error("failed to load anti-link domains", "error", err)
+		return settings
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var domain string
+		var allowed bool
+		if err := rows.Scan(&domain, &allowed); err != nil {
+			slog.Error("failed to scan anti-link domain", "error", err)
+			continue
+		}
+		if allowed {
+			settings.Allow = append(settings.Allow, domain)
+		} else {
+			settings.Deny = append(settings.Deny, domain)
+		}
+	}
+	return settings
+}
+
+// SetMode changes a group's anti-link mode.
+func (s *AntiLinkStore) SetMode(groupJID string, mode AntiLinkMode) bool {
+	_, err := s.db.Exec(`
+		INSERT INTO antilink_settings (group_jid, mode) VALUES (?, ?)
+		ON CONFLICT (group_jid) DO UPDATE SET mode = excluded.mode
+	`, groupJID, string(mode))
+	if err != nil {
+		slog.Error("failed to set anti-link mode", "error", err)
+		return false
+	}
+	return true
+}
+
+// SetAction changes what the anti-link filter does with offending messages in a group.
+func (s *AntiLinkStore) SetAction(groupJID string, action ModerationAction) bool {
+	_, err := s.db.Exec(`
+		INSERT INTO antilink_settings (group_jid, action) VALUES (?, ?)
+		ON CONFLICT (group_jid) DO UPDATE SET action = excluded.action
+	`, groupJID, string(action))
+	if err != nil {
+		slog.Error("failed to set anti-link action", "error", err)
+		return false
+	}
+	return true
+}
+
+// SetDomain adds a domain to a group's allow list (allowed) or deny list, moving it if already listed.
+func (s *AntiLinkStore) SetDomain(groupJID, domain string, allowed bool) bool {
+	_, err := s.db.Exec(`
+		INSERT INTO antilink_domains (group_jid, domain, allowed) VALUES (?, ?, ?)
+		ON CONFLICT (group_jid, domain) DO UPDATE SET allowed = excluded.allowed
+	`, groupJID, strings.ToLower(domain), allowed)
+	if err != nil {
+		slog.Error("failed to set anti-link domain", "error", err)
+		return false
+	}
+	return true
+}
+
+// RemoveDomain removes a domain from both of a group's lists. Returns false if it was not listed.
+func (s *AntiLinkStore) RemoveDomain(groupJID, domain string) bool {
+	result, err := s.db.Exec(`DELETE FROM antilink_domains WHERE group_jid = ? AND domain = ?`, groupJID, strings.ToLower(domain))
+	if err != nil {
+		slog.Error("failed to remove anti-link domain", "error", err)
+		return false
+	}
+	n, _ := result.RowsAffected()
+	return n > 0
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAntiLinkStore_Settings(t *testing.T) {
+	store := NewAntiLinkStore(newTestDB(t))
+
+	if got := store.Settings("g1@g.us"); got.Mode != AntiLinkOff || got.Action != ActionRevoke {
+		t.Errorf("default Settings() = %+v, want off/revoke", got)
+	}
+
+	store.SetDomain("g1@g.us", "YouTube.com", true)
+	store.SetDomain("g1@g.us", "slot.xyz", false)
+	store.SetAction("g1@g.us", ActionKick)
+	if got := store.Settings("g1@g.us"); got.Mode != AntiLinkOff || got.Action != ActionKick || got.Allow != nil {
+		t.Errorf("Settings() before enabling = %+v, want off/kick without domains", got)
+	}
+
+	store.SetMode("g1@g.us", AntiLinkAll)
+	got := store.Settings("g1@g.us")
+	if got.Mode != AntiLinkAll || got.Action != ActionKick {
+		t.Errorf("Settings() = %+v, want all/kick", got)
+	}
+	if !reflect.DeepEqual(got.Allow, []string{"youtube.com"}) || !reflect.DeepEqual(got.Deny, []string{"slot.xyz"}) {
+		t.Errorf("domains = %v / %v, want [youtube.com] / [slot.xyz]", got.Allow, got.Deny)
+	}
+
+	// Moving a domain between lists keeps a single entry.
+	store.SetDomain("g1@g.us", "slot.xyz", true)
+	if got := store.Settings("g1@g.us"); len(got.Allow) != 2 || len(got.Deny) != 0 {
+		t.Errorf("after moving domain = %v / %v, want two allowed", got.Allow, got.Deny)
+	}
+
+	if !store.RemoveDomain("g1@g.us", "slot.xyz") || store.RemoveDomain("g1@g.us", "slot.xyz") {
+		t.Error("RemoveDomain should succeed once")
+	}
+
+	if got := store.Settings("g2@g.us"); got.Mode != AntiLinkOff {
+		t.Errorf("other group Settings() = %+v, want off", got)
+	}
+}
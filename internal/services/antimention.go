@@ -0,0 +1,108 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// minPercentMentions keeps the percentage rule from firing in small groups,
+// where a handful of mentions is already a large share of the members.
+const minPercentMentions = 5
+
+// MentionSettings holds a group's anti-mass-mention rule.
+type MentionSettings struct {
+	Enabled     bool
+	MaxMentions int  // messages mentioning more members than this are revoked, 0 = no count limit
+	MaxPercent  int  // messages mentioning more than this percentage of the members are revoked, 0 = no percentage limit
+	Warn        bool // also give the sender a warning
+}
+
+// Exceeded reports whether a message mentioning mentions of members group members breaks the rule.
+// The percentage limit only applies from minPercentMentions mentions on.
+func (s MentionSettings) Exceeded(mentions, members int) bool {
+	if s.MaxMentions > 0 && mentions > s.MaxMentions {
+		return true
+	}
+	return s.MaxPercent > 0 && members > 0 && mentions >= minPercentMentions && mentions*100 > s.MaxPercent*members
+}
+
+// MentionStore manages persistent per-group anti-mass-mention settings.
+// Settings are read for every group message with mentions, so they are cached in memory.
+type MentionStore struct {
+	db       *sql.DB
+	defaults MentionSettings
+
+	mu    sync.RWMutex
+	cache map[string]MentionSettings
+}
+
+// NewMentionStore creates a new store and ensures the table exists.
+// defaults are used for groups without their own settings; the rule is off until a group enables it.
+func NewMentionStore(db *sql.DB, defaults MentionSettings) *MentionStore {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS mention_settings (
+			group_jid TEXT PRIMARY KEY,
+			enabled INTEGER NOT NULL DEFAULT 0,
+			max_mentions INTEGER NOT NULL,
+			max_percent INTEGER NOT NULL,
+			warn INTEGER NOT NULL DEFAULT 0
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create mention_settings table", "error", err)
+		os.Exit(1)
+	}
+
+	defaults.Enabled = false
+	return &MentionStore{db: db, defaults: defaults, cache: make(map[string]MentionSettings)}
+}
+
+// Settings returns the settings of a group, falling back to the defaults.
+func (s *MentionStore) Settings(groupJID string) MentionSettings {
+	s.mu.RLock()
+	settings, ok := s.cache[groupJID]
+	s.mu.RUnlock()
+	if ok {
+		return settings
+	}
+
+	err := s.db.QueryRow(`SELECT enabled, max_mentions, max_percent, warn FROM mention_settings WHERE group_jid = ?`, groupJID).
+		Scan(&settings.Enabled, &settings.MaxMentions, &settings.MaxPercent, &settings.Warn)
+	switch {
+	case err == nil:
+	case err == sql.ErrNoRows:
+		settings = s.defaults
+	default:
+		// Do not cache on errors so the next message retries.
+		slog.Error("failed to load mention settings", "error", err)
+		return s.defaults
+	}
+
+	s.mu.Lock()
+	s.cache[groupJID] = settings
+	s.mu.Unlock()
+	return settings
+}
+
+// SetSettings stores a group's settings.
+func (s *MentionStore) SetSettings(groupJID string, settings MentionSettings) bool {
+	_, err := s.db.Exec(`
+		INSERT INTO mention_settings (group_jid, enabled, max_mentions, max_percent, warn) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (group_jid) DO UPDATE SET
+			enabled = excluded.enabled,
+			max_mentions = excluded.max_mentions,
+			max_percent = excluded.max_percent,
+			warn = excluded.warn
+	`, groupJID, settings.Enabled, settings.MaxMentions, settings.MaxPercent, settings.Warn)
+	if err != nil {
+		slog.Error("failed to set mention settings", "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	s.cache[groupJID] = settings
+	s.mu.Unlock()
+	return true
+}
@@ -0,0 +1,46 @@
+package services
+
+import "testing"
+
+func TestMentionSettings_Exceeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings MentionSettings
+		mentions int
+		members  int
+		want     bool
+	}{
+		{"under count limit", MentionSettings{MaxMentions: 10}, 10, 200, false},
+		{"over count limit", MentionSettings{MaxMentions: 10}, 11, 200, true},
+		{"over percentage", MentionSettings{MaxPercent: 50}, 60, 100, true},
+		{"at percentage", MentionSettings{MaxPercent: 50}, 50, 100, false},
+		{"small group below minimum", MentionSettings{MaxPercent: 50}, 4, 5, false},
+		{"small group at minimum", MentionSettings{MaxPercent: 50}, 5, 6, true},
+		{"unknown group size", MentionSettings{MaxPercent: 50}, 60, 0, false},
+		{"no limits", MentionSettings{}, 500, 500, false},
+	}
+	for _, tt := range tests {
+		if got := tt.settings.Exceeded(tt.mentions, tt.members); got != tt.want {
+			t.Errorf("%s: Exceeded(%d, %d) = %v, want %v", tt.name, tt.mentions, tt.members, got, tt.want)
+		}
+	}
+}
+
+func TestMentionStore_Settings(t *testing.T) {
+	db := newTestDB(t)
+	store := NewMentionStore(db, MentionSettings{Enabled: true, MaxMentions: 10, MaxPercent: 50})
+
+	if got := store.Settings("g1@g.us"); got.Enabled || got.MaxMentions != 10 || got.MaxPercent != 50 {
+		t.Errorf("default settings = %+v, want disabled with default limits", got)
+	}
+
+	want := MentionSettings{Enabled: true, MaxMentions: 5, MaxPercent: 0, Warn: true}
+	if !store.SetSettings("g1@g.us", want) {
+		t.Fatal("SetSettings failed")
+	}
+
+	// A fresh store reads the settings back from the database.
+	if got := NewMentionStore(db, MentionSettings{}).Settings("g1@g.us"); got != want {
+		t.Errorf("Settings() = %+v, want %+v", got, want)
+	}
+}
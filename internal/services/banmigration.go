@@ -4,33 +4,17 @@ import (
 	"database/sql"
 	"log/slog"
 	"strings"
+
+	"go.mau.fi/whatsmeow/types"
 )
 
 // ensureGlobalBanTable migrates a ban table to the global schema (jid-only PK).
 // Existing per-group entries are collapsed to distinct JIDs, making prior bans global.
 func ensureGlobalBanTable(db *sql.DB, tableName string) error {
-	// Check if group_jid column exists by querying PRAGMA.
-	var hasGroupJID bool
-	rows, err := db.Query(`PRAGMA table_info(` + tableName + `)`)
+	hasGroupJID, err := hasColumn(db, tableName, "group_jid")
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var cid int
-		var name, colType string
-		var notNull int
-		var dfltValue sql.NullString
-		var pk int
-		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
-			continue
-		}
-		if name == "group_jid" {
-			hasGroupJID = true
-			break
-		}
-	}
 
 	if !hasGroupJID {
 		return nil
@@ -82,6 +66,207 @@ func ensureGlobalBanTable(db *sql.DB, tableName string) error {
 	return nil
 }
 
+// ensureBanScope migrates the bans table to the scoped schema (jid, category, scope PK).
+// Rows from before scopes existed were global bans and are kept as such.
+func ensureBanScope(db *sql.DB) error {
+	hasScope, err := hasColumn(db, "bans", "scope")
+	if err != nil {
+		return err
+	}
+
+	if hasScope {
+		return nil
+	}
+
+	slog.Info("Migrating bans table to scoped schema")
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DROP TABLE IF EXISTS bans_scope_migration`); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec(`CREATE TABLE bans_scope_migration (
+		jid TEXT NOT NULL,
+		category TEXT NOT NULL,
+		scope TEXT NOT NULL DEFAULT 'global',
+		expires_at INTEGER NOT NULL DEFAULT 0,
+		origin_chat TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (jid, category, scope)
+	)`); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	res, err := tx.Exec(`INSERT OR IGNORE INTO bans_scope_migration (jid, category, scope, expires_at, origin_chat)
+		SELECT jid, category, ?, expires_at, origin_chat FROM bans`, BanScopeGlobal)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	migrated, _ := res.RowsAffected()
+
+	if _, err = tx.Exec(`DROP TABLE bans`); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec(`ALTER TABLE bans_scope_migration RENAME TO bans`); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	slog.Info("Migrated bans table to scoped schema", "global_bans", migrated)
+	return nil
+}
+
+// migrateLegacyBanTable moves the rows of a legacy per-category ban table into the unified bans table
+// under the given category, then drops the legacy table. Missing legacy tables are ignored.
+func migrateLegacyBanTable(db *sql.DB, tableName string, category BanCategory) error {
+	exists, err := tableExists(db, tableName)
+	if err != nil || !exists {
+		return err
+	}
+
+	// Collapse any per-group rows first so every legacy table has a plain jid column.
+	if err := ensureGlobalBanTable(db, tableName); err != nil {
+		return err
+	}
+
+	slog.Info("Migrating legacy ban table to unified bans table", "table", tableName, "category", category)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	tableIdent := quoteSQLiteIdent(tableName)
+
+	res, err := tx.Exec(`INSERT OR IGNORE INTO bans (jid, category)
+		SELECT DISTINCT jid, ? FROM `+tableIdent+` WHERE jid IS NOT NULL AND jid != ''`, string(category))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	migrated, _ := res.RowsAffected()
+
+	if _, err = tx.Exec(`DROP TABLE ` + tableIdent); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	slog.Info("Migrated legacy ban table", "table", tableName, "category", category, "jids", migrated)
+	return nil
+}
+
+// migrateLIDKeys rewrites the LID keys in a table's jid column to the phone number form wherever
+// identity knows the mapping. Rows that would duplicate an existing phone number row are dropped,
+// keeping the existing row. Returns how many users were rewritten.
+func migrateLIDKeys(db *sql.DB, tableName string, identity *Identity) (int, error) {
+	tableIdent := quoteSQLiteIdent(tableName)
+
+	rows, err := db.Query(`SELECT DISTINCT jid FROM `+tableIdent+` WHERE jid LIKE ?`, "%@"+types.HiddenUserServer)
+	if err != nil {
+		return 0, err
+	}
+	renames := make(map[string]string)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			continue
+		}
+		jid, err := types.ParseJID(key)
+		if err != nil {
+			continue
+		}
+		if canonical := identity.Key(jid); canonical != key {
+			renames[key] = canonical
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(renames) == 0 {
+		return 0, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	for oldKey, newKey := range renames {
+		if _, err = tx.Exec(`UPDATE OR IGNORE `+tableIdent+` SET jid = ? WHERE jid = ?`, newKey, oldKey); err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+		if _, err = tx.Exec(`DELETE FROM `+tableIdent+` WHERE jid = ?`, oldKey); err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(renames), nil
+}
+
+// ensureColumn adds a column to a table if it does not exist yet.
+// definition is the column type and constraints, e.g. "INTEGER NOT NULL DEFAULT 0".
+func ensureColumn(db *sql.DB, tableName, column, definition string) error {
+	exists, err := hasColumn(db, tableName, column)
+	if err != nil || exists {
+		return err
+	}
+
+	slog.Info("Adding column to table", "table", tableName, "column", column)
+	_, err = db.Exec(`ALTER TABLE ` + quoteSQLiteIdent(tableName) + ` ADD COLUMN ` + quoteSQLiteIdent(column) + ` ` + definition)
+	return err
+}
+
+// tableExists reports whether a table with the given name exists.
+func tableExists(db *sql.DB, tableName string) (bool, error) {
+	var name string
+	err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`, tableName).Scan(&name)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// hasColumn reports whether a table has a column with the given name, using PRAGMA table_info.
+func hasColumn(db *sql.DB, tableName, column string) (bool, error) {
+	rows, err := db.Query(`PRAGMA table_info(` + quoteSQLiteIdent(tableName) + `)`)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, colType string
+		var notNull int
+		var dfltValue sql.NullString
+		var pk int
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			continue
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
 func quoteSQLiteIdent(name string) string {
 	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
 }
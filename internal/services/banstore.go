@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// BanCategory identifies what a banned user is forbidden from sending.
+// Categories are stored as plain strings, so new ones need no schema change.
+type BanCategory string
+
+const (
+	BanChat     BanCategory = "chat"     // any message
+	BanSticker  BanCategory = "sticker"  // stickers
+	BanImage    BanCategory = "image"    // images, videos and GIFs
+	BanAudio    BanCategory = "audio"    // voice notes and audio files
+	BanDocument BanCategory = "document" // documents, optionally only some file types
+	BanContact  BanCategory = "contact"  // contact cards
+	BanLocation BanCategory = "location" // static and live locations
+	BanPoll     BanCategory = "poll"     // new polls
+)
+
+// BanScopeGlobal is the scope of bans that apply in every group.
+// Any other scope is the JID of the single group the ban applies to.
+const BanScopeGlobal = "global"
+
+// legacyBanTables maps the per-category tables used before the unified store to their category.
+var legacyBanTables = map[string]BanCategory{
+	"banned_chat_users":    BanChat,
+	"banned_sticker_users": BanSticker,
+	"banned_image_users":   BanImage,
+}
+
+// Ban is a single (user JID, category, scope) ban.
+type Ban struct {
+	JID      string
+	Category BanCategory
+	// Scope is BanScopeGlobal or a group JID.
+	Scope string
+	// ExpiresAt is zero for permanent bans.
+	ExpiresAt time.Time
+	// OriginChat is the chat the ban was issued in, used to announce expiry.
+	OriginChat string
+	// Issuer is the JID of the admin who issued the ban, empty for migrated bans.
+	Issuer    string
+	CreatedAt time.Time
+	Reason    string
+}
+
+// BanStore manages a persistent list of (user JID, category, scope) bans.
+// A ban applies either globally or in a single group.
+// Temporary bans carry an expiry timestamp and are ignored once expired.
+type BanStore struct {
+	db *sql.DB
+}
+
+// NewBanStore creates a new store, ensures the table exists and migrates legacy ban tables.
+func NewBanStore(db *sql.DB) *BanStore {
+	store := &BanStore{db: db}
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS bans (
+			jid TEXT NOT NULL,
+			category TEXT NOT NULL,
+			scope TEXT NOT NULL DEFAULT 'global',
+			expires_at INTEGER NOT NULL DEFAULT 0,
+			origin_chat TEXT NOT NULL DEFAULT '',
+			issuer TEXT NOT NULL DEFAULT '',
+			created_at INTEGER NOT NULL DEFAULT 0,
+			reason TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (jid, category, scope)
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create bans table", "error", err)
+		os.Exit(1)
+	}
+
+	if err := ensureColumn(db, "bans", "expires_at", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		slog.Error("Failed to migrate bans table", "error", err)
+		os.Exit(1)
+	}
+	if err := ensureColumn(db, "bans", "origin_chat", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		slog.Error("Failed to migrate bans table", "error", err)
+		os.Exit(1)
+	}
+	if err := ensureBanScope(db); err != nil {
+		slog.Error("Failed to migrate bans table to scoped schema", "error", err)
+		os.Exit(1)
+	}
+	for column, definition := range map[string]string{
+		"issuer":     "TEXT NOT NULL DEFAULT ''",
+		"created_at": "INTEGER NOT NULL DEFAULT 0",
+		"reason":     "TEXT NOT NULL DEFAULT ''",
+	} {
+		if err := ensureColumn(db, "bans", column, definition); err != nil {
+			slog.Error("Failed to migrate bans table", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	for table, category := range legacyBanTables {
+		if err := migrateLegacyBanTable(db, table, category); err != nil {
+			slog.Error("Failed to migrate legacy ban table", "table", table, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	store.migrateLegacyJSON()
+	return store
+}
+
+// MigrateLIDKeys moves bans stored under a LID to the user's phone number once the mapping is known,
+// so every ban of a user is found under one key. Failures are logged and retried on the next start.
+func (s *BanStore) MigrateLIDKeys(identity *Identity) {
+	migrated, err := migrateLIDKeys(s.db, "bans", identity)
+	if err != nil {
+		slog.Error("Failed to migrate LID ban keys", "error", err)
+		return
+	}
+	if migrated > 0 {
+		slog.Info("Migrated LID ban keys to phone numbers", "users", migrated)
+	}
+}
+
+// IsBanned checks if a user is banned in the given category, either globally or in groupJID.
+// Expired bans are ignored.
+func (s *BanStore) IsBanned(jid string, category BanCategory, groupJID string) bool {
+	var count int
+	err := s.db.QueryRow(`SELECT 1 FROM bans WHERE jid = ? AND category = ? AND scope IN (?, ?)
+		AND (expires_at = 0 OR expires_at > ?)`,
+		jid, string(category), BanScopeGlobal, groupJID, time.Now().Unix()).Scan(&count)
+	return err == nil
+}
+
+// ActiveExpiry returns when the user's longest active ban in a category that applies in groupJID
+// (global or group-scoped) ends, and whether there is one. A zero time means the ban is permanent.
+func (s *BanStore) ActiveExpiry(jid string, category BanCategory, groupJID string) (time.Time, bool) {
+	var count, minExpiry, maxExpiry int64
+	err := s.db.QueryRow(`SELECT COUNT(*), COALESCE(MIN(expires_at), 0), COALESCE(MAX(expires_at), 0) FROM bans
+		WHERE jid = ? AND category = ? AND scope IN (?, ?) AND (expires_at = 0 OR expires_at > ?)`,
+		jid, string(category), BanScopeGlobal, groupJID, time.Now().Unix()).Scan(&count, &minExpiry, &maxExpiry)
+	if err != nil {
+		slog.Error("Error reading user ban expiry", "error", err)
+		return time.Time{}, false
+	}
+	switch {
+	case count == 0:
+		return time.Time{}, false
+	case minExpiry == 0:
+		return time.Time{}, true
+	}
+	return time.Unix(maxExpiry, 0), true
+}
+
+// Categories returns every category the user is banned in, either globally or in groupJID.
+// Expired bans are ignored.
+func (s *BanStore) Categories(jid string, groupJID string) map[BanCategory]bool {
+	categories := make(map[BanCategory]bool)
+
+	rows, err := s.db.Query(`SELECT category FROM bans WHERE jid = ? AND scope IN (?, ?)
+		AND (expires_at = 0 OR expires_at > ?)`,
+		jid, BanScopeGlobal, groupJID, time.Now().Unix())
+	if err != nil {
+		slog.Error("Error reading user bans", "error", err)
+		return categories
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			continue
+		}
+		categories[BanCategory(category)] = true
+	}
+	return categories
+}
+
+// Add stores a ban. Scope must be BanScopeGlobal or a group JID; a zero ExpiresAt makes it permanent
+// and a zero CreatedAt is set to now.
+// Banning an already banned user replaces the existing ban's expiry, issuer and reason.
+// Returns true if newly added, false if the user was already banned in that scope.
+func (s *BanStore) Add(ban Ban) bool {
+	if ban.CreatedAt.IsZero() {
+		ban.CreatedAt = time.Now()
+	}
+
+	var count int
+	existed := s.db.QueryRow(`SELECT 1 FROM bans WHERE jid = ? AND category = ? AND scope = ? AND (expires_at = 0 OR expires_at > ?)`,
+		ban.JID, string(ban.Category), ban.Scope, time.Now().Unix()).Scan(&count) == nil
+
+	_, err := s.db.Exec(`INSERT INTO bans (jid, category, scope, expires_at, origin_chat, issuer, created_at, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(jid, category, scope) DO UPDATE SET expires_at = excluded.expires_at, origin_chat = excluded.origin_chat,
+			issuer = excluded.issuer, created_at = excluded.created_at, reason = excluded.reason`,
+		ban.JID, string(ban.Category), ban.Scope, unixOrZero(ban.ExpiresAt), ban.OriginChat,
+		ban.Issuer, ban.CreatedAt.Unix(), ban.Reason)
+	if err != nil {
+		slog.Error("Error adding user to ban list", "category", ban.Category, "error", err)
+		return false
+	}
+	return !existed
+}
+
+// List returns the active bans that apply in groupJID (global and group-scoped), newest first.
+// An empty category lists every category.
+func (s *BanStore) List(groupJID string, category BanCategory) []Ban {
+	rows, err := s.db.Query(`SELECT jid, category, scope, expires_at, origin_chat, issuer, created_at, reason FROM bans
+		WHERE scope IN (?, ?) AND (? = '' OR category = ?) AND (expires_at = 0 OR expires_at > ?)
+		ORDER BY created_at DESC, jid`,
+		BanScopeGlobal, groupJID, string(category), string(category), time.Now().Unix())
+	if err != nil {
+		slog.Error("Error listing bans", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var bans []Ban
+	for rows.Next() {
+		ban, err := scanBan(rows)
+		if err != nil {
+			continue
+		}
+		bans = append(bans, ban)
+	}
+	return bans
+}
+
+// Remove lifts a user's ban in a category within scope. Returns true if removed, false if they weren't banned there.
+func (s *BanStore) Remove(jid string, category BanCategory, scope string) bool {
+	res, err := s.db.Exec(`DELETE FROM bans WHERE jid = ? AND category = ? AND scope = ?`, jid, string(category), scope)
+	if err != nil {
+		slog.Error("Error removing user from ban list", "category", category, "error", err)
+		return false
+	}
+	rows, _ := res.RowsAffected()
+	return rows > 0
+}
+
+// StartExpirySweeper runs a background goroutine that periodically deletes expired bans
+// and calls onExpire for each of them, e.g. to announce that the restriction was lifted.
+// onExpire may be nil.
+func (s *BanStore) StartExpirySweeper(ctx context.Context, interval time.Duration, onExpire func(Ban)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			expired := s.sweepExpired(time.Now())
+			if len(expired) > 0 {
+				slog.Info("Lifted expired bans", "count", len(expired))
+			}
+			if onExpire == nil {
+				continue
+			}
+			for _, ban := range expired {
+				onExpire(ban)
+			}
+		}
+	}()
+}
+
+// sweepExpired deletes bans that expired at or before now and returns them.
+func (s *BanStore) sweepExpired(now time.Time) []Ban {
+	rows, err := s.db.Query(`SELECT jid, category, scope, expires_at, origin_chat, issuer, created_at, reason FROM bans
+		WHERE expires_at != 0 AND expires_at <= ?`, now.Unix())
+	if err != nil {
+		slog.Error("Error reading expired bans", "error", err)
+		return nil
+	}
+
+	var candidates []Ban
+	for rows.Next() {
+		ban, err := scanBan(rows)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, ban)
+	}
+	rows.Close()
+
+	var expired []Ban
+	for _, ban := range candidates {
+		// Match on expires_at so a ban renewed since the query is left alone.
+		res, err := s.db.Exec(`DELETE FROM bans WHERE jid = ? AND category = ? AND scope = ? AND expires_at = ?`,
+			ban.JID, string(ban.Category), ban.Scope, ban.ExpiresAt.Unix())
+		if err != nil {
+			slog.Error("Error deleting expired ban", "error", err)
+			continue
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			expired = append(expired, ban)
+		}
+	}
+	return expired
+}
+
+// scanBan reads a ban row selected as jid, category, scope, expires_at, origin_chat, issuer, created_at, reason.
+func scanBan(rows *sql.Rows) (Ban, error) {
+	var ban Ban
+	var category string
+	var expiresAt, createdAt int64
+	if err := rows.Scan(&ban.JID, &category, &ban.Scope, &expiresAt, &ban.OriginChat, &ban.Issuer, &createdAt, &ban.Reason); err != nil {
+		return Ban{}, err
+	}
+	ban.Category = BanCategory(category)
+	if expiresAt != 0 {
+		ban.ExpiresAt = time.Unix(expiresAt, 0)
+	}
+	if createdAt != 0 {
+		ban.CreatedAt = time.Unix(createdAt, 0)
+	}
+	return ban, nil
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// migrateLegacyJSON reads banned_sticker_users.json and inserts global sticker bans to DB.
+func (s *BanStore) migrateLegacyJSON() {
+	legacyFile := "banned_sticker_users.json"
+	data, err := os.ReadFile(legacyFile)
+	if err != nil {
+		return
+	}
+
+	var wrappedData struct {
+		JIDs map[string]bool `json:"jids"`
+	}
+	if err := json.Unmarshal(data, &wrappedData); err == nil && wrappedData.JIDs != nil {
+		count := 0
+		for jid, isBanned := range wrappedData.JIDs {
+			if isBanned && s.Add(Ban{JID: jid, Category: BanSticker, Scope: BanScopeGlobal}) {
+				count++
+			}
+		}
+		if count > 0 {
+			slog.Info("[banstore] Migrated legacy JSON sticker bans to global DB", "count", count)
+		}
+	}
+
+	_ = os.Rename(legacyFile, legacyFile+".bak")
+}
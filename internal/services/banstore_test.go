@@ -0,0 +1,233 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBanStore_AddRemove(t *testing.T) {
+	store := NewBanStore(newTestDB(t))
+
+	if !store.Add(Ban{JID: "user1@s.whatsapp.net", Category: BanSticker, Scope: BanScopeGlobal}) {
+		t.Fatal("first Add should report a new ban")
+	}
+	if store.Add(Ban{JID: "user1@s.whatsapp.net", Category: BanSticker, Scope: BanScopeGlobal}) {
+		t.Error("second Add should report an existing ban")
+	}
+
+	if !store.IsBanned("user1@s.whatsapp.net", BanSticker, "g1@g.us") {
+		t.Error("user1 should be banned from stickers")
+	}
+	if store.IsBanned("user1@s.whatsapp.net", BanChat, "g1@g.us") {
+		t.Error("user1 should not be banned from chat")
+	}
+
+	if got := store.Categories("user1@s.whatsapp.net", "g1@g.us"); len(got) != 1 || !got[BanSticker] {
+		t.Errorf("Categories() = %v, want only sticker", got)
+	}
+
+	if !store.Remove("user1@s.whatsapp.net", BanSticker, BanScopeGlobal) {
+		t.Error("Remove should report the lifted ban")
+	}
+	if store.IsBanned("user1@s.whatsapp.net", BanSticker, "g1@g.us") {
+		t.Error("user1 should no longer be banned")
+	}
+}
+
+func TestBanStore_MigratesLegacyTables(t *testing.T) {
+	db := newTestDB(t)
+
+	stmts := []string{
+		`CREATE TABLE banned_chat_users (jid TEXT PRIMARY KEY)`,
+		`INSERT INTO banned_chat_users (jid) VALUES ('chat1@s.whatsapp.net')`,
+		// Pre-global schema with per-group rows.
+		`CREATE TABLE banned_image_users (group_jid TEXT, jid TEXT, PRIMARY KEY (group_jid, jid))`,
+		`INSERT INTO banned_image_users (group_jid, jid) VALUES ('g1@g.us', 'img1@s.whatsapp.net')`,
+		`INSERT INTO banned_image_users (group_jid, jid) VALUES ('g2@g.us', 'img1@s.whatsapp.net')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("setup %q: %v", stmt, err)
+		}
+	}
+
+	store := NewBanStore(db)
+
+	if !store.IsBanned("chat1@s.whatsapp.net", BanChat, "g1@g.us") {
+		t.Error("legacy chat ban should be migrated")
+	}
+	if !store.IsBanned("img1@s.whatsapp.net", BanImage, "g3@g.us") {
+		t.Error("legacy per-group image ban should be migrated as global")
+	}
+
+	for table := range legacyBanTables {
+		exists, err := tableExists(db, table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists {
+			t.Errorf("legacy table %s should be dropped", table)
+		}
+	}
+}
+
+func TestBanStore_TemporaryBanExpiry(t *testing.T) {
+	store := NewBanStore(newTestDB(t))
+
+	expired := time.Now().Add(-time.Minute)
+	store.Add(Ban{JID: "user1@s.whatsapp.net", Category: BanChat, Scope: "g1@g.us", ExpiresAt: expired, OriginChat: "g1@g.us"})
+	store.Add(Ban{JID: "user2@s.whatsapp.net", Category: BanChat, Scope: "g1@g.us", ExpiresAt: time.Now().Add(time.Hour), OriginChat: "g1@g.us"})
+	store.Add(Ban{JID: "user3@s.whatsapp.net", Category: BanChat, Scope: BanScopeGlobal, OriginChat: "g1@g.us"})
+
+	if store.IsBanned("user1@s.whatsapp.net", BanChat, "g1@g.us") {
+		t.Error("expired ban should be ignored")
+	}
+	if len(store.Categories("user1@s.whatsapp.net", "g1@g.us")) != 0 {
+		t.Error("expired ban should not be listed in Categories")
+	}
+	if !store.IsBanned("user2@s.whatsapp.net", BanChat, "g1@g.us") {
+		t.Error("unexpired temporary ban should apply")
+	}
+
+	lifted := store.sweepExpired(time.Now())
+	if len(lifted) != 1 || lifted[0].JID != "user1@s.whatsapp.net" || lifted[0].OriginChat != "g1@g.us" {
+		t.Fatalf("sweepExpired() = %+v, want only user1", lifted)
+	}
+
+	// Re-adding after the sweep is a new ban again.
+	if !store.Add(Ban{JID: "user1@s.whatsapp.net", Category: BanChat, Scope: "g1@g.us"}) {
+		t.Error("ban after sweep should be new")
+	}
+	if !store.IsBanned("user3@s.whatsapp.net", BanChat, "g1@g.us") {
+		t.Error("permanent ban should survive the sweep")
+	}
+}
+
+func TestBanStore_ListWithDetails(t *testing.T) {
+	store := NewBanStore(newTestDB(t))
+
+	store.Add(Ban{JID: "user1@s.whatsapp.net", Category: BanChat, Scope: "g1@g.us",
+		Issuer: "admin@s.whatsapp.net", Reason: "spamming promo", CreatedAt: time.Now().Add(-time.Hour)})
+	store.Add(Ban{JID: "user2@s.whatsapp.net", Category: BanSticker, Scope: BanScopeGlobal})
+	store.Add(Ban{JID: "user3@s.whatsapp.net", Category: BanChat, Scope: "g2@g.us"})
+
+	bans := store.List("g1@g.us", "")
+	if len(bans) != 2 {
+		t.Fatalf("List() returned %d bans, want 2 (own group + global)", len(bans))
+	}
+	if bans[0].JID != "user2@s.whatsapp.net" {
+		t.Errorf("List() should be newest first, got %s first", bans[0].JID)
+	}
+
+	chatBans := store.List("g1@g.us", BanChat)
+	if len(chatBans) != 1 {
+		t.Fatalf("List(chat) returned %d bans, want 1", len(chatBans))
+	}
+	ban := chatBans[0]
+	if ban.Issuer != "admin@s.whatsapp.net" || ban.Reason != "spamming promo" || ban.CreatedAt.IsZero() {
+		t.Errorf("List(chat) = %+v, want issuer, reason and timestamp", ban)
+	}
+}
+
+func TestBanStore_GroupScope(t *testing.T) {
+	store := NewBanStore(newTestDB(t))
+
+	store.Add(Ban{JID: "user1@s.whatsapp.net", Category: BanChat, Scope: "g1@g.us", OriginChat: "g1@g.us"})
+
+	if !store.IsBanned("user1@s.whatsapp.net", BanChat, "g1@g.us") {
+		t.Error("group ban should apply in its own group")
+	}
+	if store.IsBanned("user1@s.whatsapp.net", BanChat, "g2@g.us") {
+		t.Error("group ban should not apply in other groups")
+	}
+
+	// A global ban applies everywhere and is independent of the group ban.
+	if !store.Add(Ban{JID: "user1@s.whatsapp.net", Category: BanChat, Scope: BanScopeGlobal, OriginChat: "g1@g.us"}) {
+		t.Fatal("global ban should be new alongside a group ban")
+	}
+	if !store.IsBanned("user1@s.whatsapp.net", BanChat, "g2@g.us") {
+		t.Error("global ban should apply in every group")
+	}
+
+	if !store.Remove("user1@s.whatsapp.net", BanChat, "g1@g.us") {
+		t.Fatal("group ban should be removable")
+	}
+	if !store.IsBanned("user1@s.whatsapp.net", BanChat, "g1@g.us") {
+		t.Error("removing the group ban should leave the global ban")
+	}
+}
+
+func TestBanStore_MigratesUnscopedBansAsGlobal(t *testing.T) {
+	db := newTestDB(t)
+
+	stmts := []string{
+		`CREATE TABLE bans (jid TEXT NOT NULL, category TEXT NOT NULL, expires_at INTEGER NOT NULL DEFAULT 0,
+			origin_chat TEXT NOT NULL DEFAULT '', PRIMARY KEY (jid, category))`,
+		`INSERT INTO bans (jid, category) VALUES ('user1@s.whatsapp.net', 'sticker')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("setup %q: %v", stmt, err)
+		}
+	}
+
+	store := NewBanStore(db)
+
+	if !store.IsBanned("user1@s.whatsapp.net", BanSticker, "g1@g.us") {
+		t.Error("pre-scope ban should be migrated as global")
+	}
+	if !store.Remove("user1@s.whatsapp.net", BanSticker, BanScopeGlobal) {
+		t.Error("migrated ban should have the global scope")
+	}
+}
+
+func TestBanStore_MigrateLIDKeys(t *testing.T) {
+	store := NewBanStore(newTestDB(t))
+	store.Add(Ban{JID: "111@lid", Category: BanChat, Scope: "g1@g.us", Reason: "lid"})
+	store.Add(Ban{JID: "111@lid", Category: BanSticker, Scope: BanScopeGlobal})
+	store.Add(Ban{JID: "628111@s.whatsapp.net", Category: BanChat, Scope: "g1@g.us", Reason: "pn"})
+	store.Add(Ban{JID: "999@lid", Category: BanChat, Scope: BanScopeGlobal})
+
+	store.MigrateLIDKeys(NewIdentity(fakeLIDs{"111": "628111"}))
+
+	got := store.Categories("628111@s.whatsapp.net", "g1@g.us")
+	if len(got) != 2 || !got[BanChat] || !got[BanSticker] {
+		t.Errorf("Categories() after migration = %v, want chat and sticker", got)
+	}
+	if got := store.Categories("111@lid", "g1@g.us"); len(got) != 0 {
+		t.Errorf("LID rows should be gone after migration, got %v", got)
+	}
+	for _, ban := range store.List("g1@g.us", BanChat) {
+		if ban.JID == "628111@s.whatsapp.net" && ban.Reason != "pn" {
+			t.Errorf("existing phone number ban should be kept, got %+v", ban)
+		}
+	}
+	if !store.IsBanned("999@lid", BanChat, "g1@g.us") {
+		t.Error("LIDs without a known phone number should be kept")
+	}
+}
+
+func TestBanStore_ActiveExpiry(t *testing.T) {
+	store := NewBanStore(newTestDB(t))
+	user := "user1@s.whatsapp.net"
+
+	if _, ok := store.ActiveExpiry(user, BanChat, "g1@g.us"); ok {
+		t.Fatal("ActiveExpiry should report no ban")
+	}
+
+	soon := time.Now().Add(time.Hour).Truncate(time.Second)
+	later := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	store.Add(Ban{JID: user, Category: BanChat, Scope: "g1@g.us", ExpiresAt: soon})
+	store.Add(Ban{JID: user, Category: BanChat, Scope: BanScopeGlobal, ExpiresAt: later})
+	store.Add(Ban{JID: user, Category: BanChat, Scope: "g2@g.us"})
+
+	if got, ok := store.ActiveExpiry(user, BanChat, "g1@g.us"); !ok || !got.Equal(later) {
+		t.Errorf("ActiveExpiry(g1) = %v, %v, want the longer global ban %v", got, ok, later)
+	}
+	if got, ok := store.ActiveExpiry(user, BanChat, "g2@g.us"); !ok || !got.IsZero() {
+		t.Errorf("ActiveExpiry(g2) = %v, %v, want a permanent ban", got, ok)
+	}
+	if _, ok := store.ActiveExpiry(user, BanSticker, "g1@g.us"); ok {
+		t.Error("ActiveExpiry should only look at the given category")
+	}
+}
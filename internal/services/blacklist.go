@@ -0,0 +1,130 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// BlacklistPrefixSuffix marks a blacklist pattern as a phone number prefix rule, e.g. "1*" for +1 numbers.
+const BlacklistPrefixSuffix = "*"
+
+// BlacklistEntry is a user who is kicked on sight, or a rule for a whole range of phone numbers.
+type BlacklistEntry struct {
+	// Pattern is a user key (number@s.whatsapp.net or id@lid), or country code digits followed by
+	// BlacklistPrefixSuffix for a prefix rule.
+	Pattern   string
+	Scope     string // BanScopeGlobal or a group JID
+	Issuer    string
+	CreatedAt time.Time
+}
+
+// IsPrefix reports whether the entry is a phone number prefix rule.
+func (e BlacklistEntry) IsPrefix() bool {
+	return strings.HasSuffix(e.Pattern, BlacklistPrefixSuffix)
+}
+
+// Matches reports whether the entry matches any of the given forms of a user's JID.
+// Prefix rules only match phone numbers, never LIDs.
+func (e BlacklistEntry) Matches(forms []types.JID) bool {
+	prefix := strings.TrimSuffix(e.Pattern, BlacklistPrefixSuffix)
+	for _, jid := range forms {
+		jid = jid.ToNonAD()
+		if e.IsPrefix() {
+			if jid.Server == types.DefaultUserServer && strings.HasPrefix(jid.User, prefix) {
+				return true
+			}
+		} else if jid.String() == e.Pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// Blacklist manages blacklisted users and phone number prefixes, global or per group.
+type Blacklist struct {
+	db *sql.DB
+}
+
+// NewBlacklist creates a new blacklist and ensures the table exists.
+func NewBlacklist(db *sql.DB) *Blacklist {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS blacklist (
+			pattern TEXT NOT NULL,
+			scope TEXT NOT NULL,
+			issuer TEXT NOT NULL DEFAULT '',
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (pattern, scope)
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create blacklist table", "error", err)
+		os.Exit(1)
+	}
+	return &Blacklist{db: db}
+}
+
+// Add blacklists a pattern. A zero CreatedAt is set to now.
+// Returns true if newly added, false if it was already blacklisted in that scope.
+func (b *Blacklist) Add(entry BlacklistEntry) bool {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	result, err := b.db.Exec(`INSERT OR IGNORE INTO blacklist (pattern, scope, issuer, created_at) VALUES (?, ?, ?, ?)`,
+		entry.Pattern, entry.Scope, entry.Issuer, entry.CreatedAt.Unix())
+	if err != nil {
+		slog.Error("failed to add to blacklist", "error", err)
+		return false
+	}
+	n, _ := result.RowsAffected()
+	return n > 0
+}
+
+// Remove takes a pattern off the blacklist in a scope. Returns false if it was not blacklisted there.
+func (b *Blacklist) Remove(pattern, scope string) bool {
+	result, err := b.db.Exec(`DELETE FROM blacklist WHERE pattern = ? AND scope = ?`, pattern, scope)
+	if err != nil {
+		slog.Error("failed to remove from blacklist", "error", err)
+		return false
+	}
+	n, _ := result.RowsAffected()
+	return n > 0
+}
+
+// List returns the entries that apply in groupJID (global and group-scoped), global entries first.
+func (b *Blacklist) List(groupJID string) []BlacklistEntry {
+	rows, err := b.db.Query(`SELECT pattern, scope, issuer, created_at FROM blacklist WHERE scope IN (?, ?)
+		ORDER BY scope = ? DESC, created_at, pattern`, BanScopeGlobal, groupJID, BanScopeGlobal)
+	if err != nil {
+		slog.Error("failed to list blacklist", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var entries []BlacklistEntry
+	for rows.Next() {
+		var e BlacklistEntry
+		var created int64
+		if err := rows.Scan(&e.Pattern, &e.Scope, &e.Issuer, &created); err != nil {
+			slog.Error("failed to scan blacklist entry", "error", err)
+			continue
+		}
+		e.CreatedAt = time.Unix(created, 0)
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// Match returns the first entry that applies in groupJID and matches any of the given forms of a user's JID.
+func (b *Blacklist) Match(groupJID string, forms []types.JID) (BlacklistEntry, bool) {
+	for _, entry := range b.List(groupJID) {
+		if entry.Matches(forms) {
+			return entry, true
+		}
+	}
+	return BlacklistEntry{}, false
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestBlacklistEntry_Matches(t *testing.T) {
+	pn := func(user string) types.JID { return types.NewJID(user, types.DefaultUserServer) }
+	lid := func(user string) types.JID { return types.NewJID(user, types.HiddenUserServer) }
+
+	tests := []struct {
+		pattern string
+		forms   []types.JID
+		want    bool
+	}{
+		{"628111@s.whatsapp.net", []types.JID{pn("628111")}, true},
+		{"628111@s.whatsapp.net", []types.JID{lid("111"), pn("628111")}, true},
+		{"628111@s.whatsapp.net", []types.JID{pn("6281110")}, false},
+		{"111@lid", []types.JID{lid("111")}, true},
+		{"111@lid", []types.JID{pn("111")}, false},
+		{"1*", []types.JID{pn("14155550100")}, true},
+		{"1*", []types.JID{pn("628111")}, false},
+		{"1*", []types.JID{lid("14155550100")}, false}, // prefix rules never match LIDs
+		{"62*", []types.JID{lid("999"), pn("628111")}, true},
+	}
+	for _, tt := range tests {
+		if got := (BlacklistEntry{Pattern: tt.pattern}).Matches(tt.forms); got != tt.want {
+			t.Errorf("%q.Matches(%v) = %v, want %v", tt.pattern, tt.forms, got, tt.want)
+		}
+	}
+}
+
+func TestBlacklist_Scopes(t *testing.T) {
+	b := NewBlacklist(newTestDB(t))
+
+	if !b.Add(BlacklistEntry{Pattern: "628111@s.whatsapp.net", Scope: "g1@g.us"}) {
+		t.Fatal("first Add should report a new entry")
+	}
+	if b.Add(BlacklistEntry{Pattern: "628111@s.whatsapp.net", Scope: "g1@g.us"}) {
+		t.Error("second Add should report an existing entry")
+	}
+	b.Add(BlacklistEntry{Pattern: "1*", Scope: BanScopeGlobal})
+
+	user := []types.JID{types.NewJID("628111", types.DefaultUserServer)}
+	if _, ok := b.Match("g1@g.us", user); !ok {
+		t.Error("user should be blacklisted in g1")
+	}
+	if _, ok := b.Match("g2@g.us", user); ok {
+		t.Error("group entry should not apply in g2")
+	}
+	if entry, ok := b.Match("g2@g.us", []types.JID{types.NewJID("14155550100", types.DefaultUserServer)}); !ok || entry.Pattern != "1*" {
+		t.Errorf("global prefix rule should apply in g2, got %+v, %v", entry, ok)
+	}
+
+	if list := b.List("g1@g.us"); len(list) != 2 || list[0].Scope != BanScopeGlobal {
+		t.Errorf("List() = %+v, want the global rule first", list)
+	}
+
+	if !b.Remove("628111@s.whatsapp.net", "g1@g.us") {
+		t.Error("Remove should report the removed entry")
+	}
+	if _, ok := b.Match("g1@g.us", user); ok {
+		t.Error("user should no longer be blacklisted")
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// BlockKind identifies what a media blocklist entry matches.
+type BlockKind string
+
+const (
+	BlockSticker BlockKind = "sticker" // exact sticker file by FileSHA256
+	BlockImage   BlockKind = "image"   // perceptual image hash, matched by Hamming distance
+)
+
+// BlockedMedia is a media blocklist entry.
+type BlockedMedia struct {
+	Kind      BlockKind
+	Hash      string // hex-encoded
+	Scope     string // BanScopeGlobal or a group JID
+	Issuer    string
+	CreatedAt time.Time
+}
+
+// MediaBlocklist manages blocked media hashes, global or per group.
+type MediaBlocklist struct {
+	db *sql.DB
+}
+
+// NewMediaBlocklist creates a new blocklist and ensures the table exists.
+func NewMediaBlocklist(db *sql.DB) *MediaBlocklist {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS media_blocklist (
+			kind TEXT NOT NULL,
+			hash TEXT NOT NULL,
+			scope TEXT NOT NULL,
+			issuer TEXT NOT NULL DEFAULT '',
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (kind, hash, scope)
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create media_blocklist table", "error", err)
+		os.Exit(1)
+	}
+	return &MediaBlocklist{db: db}
+}
+
+// Contains reports whether hash is blocked globally or in groupJID.
+func (b *MediaBlocklist) Contains(kind BlockKind, hash, groupJID string) bool {
+	var one int
+	err := b.db.QueryRow(`SELECT 1 FROM media_blocklist WHERE kind = ? AND hash = ? AND scope IN (?, ?) LIMIT 1`,
+		string(kind), hash, BanScopeGlobal, groupJID).Scan(&one)
+	if err != nil && err != sql.ErrNoRows {
+		slog.Error("failed to check media blocklist", "kind", kind, "error", err)
+	}
+	return err == nil
+}
+
+// Add blocks a hash. A zero CreatedAt is set to now.
+// Returns true if newly added, false if it was already blocked in that scope.
+func (b *MediaBlocklist) Add(item BlockedMedia) bool {
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	result, err := b.db.Exec(`INSERT OR IGNORE INTO media_blocklist (kind, hash, scope, issuer, created_at) VALUES (?, ?, ?, ?, ?)`,
+		string(item.Kind), item.Hash, item.Scope, item.Issuer, item.CreatedAt.Unix())
+	if err != nil {
+		slog.Error("failed to add to media blocklist", "kind", item.Kind, "error", err)
+		return false
+	}
+	n, _ := result.RowsAffected()
+	return n > 0
+}
+
+// Remove unblocks a hash in a scope. Returns false if it was not blocked there.
+func (b *MediaBlocklist) Remove(kind BlockKind, hash, scope string) bool {
+	result, err := b.db.Exec(`DELETE FROM media_blocklist WHERE kind = ? AND hash = ? AND scope = ?`, string(kind), hash, scope)
+	if err != nil {
+		slog.Error("failed to remove from media blocklist", "kind", kind, "error", err)
+		return false
+	}
+	n, _ := result.RowsAffected()
+	return n > 0
+}
+
+// List returns the entries that apply in groupJID (global and group-scoped), newest first.
+func (b *MediaBlocklist) List(kind BlockKind, groupJID string) []BlockedMedia {
+	rows, err := b.db.Query(`SELECT hash, scope, issuer, created_at FROM media_blocklist
+		WHERE kind = ? AND scope IN (?, ?) ORDER BY created_at DESC, rowid DESC`,
+		string(kind), BanScopeGlobal, groupJID)
+	if err != nil {
+		slog.Error("failed to list media blocklist", "kind", kind, "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var list []BlockedMedia
+	for rows.Next() {
+		item := BlockedMedia{Kind: kind}
+		var createdAt int64
+		if err := rows.Scan(&item.Hash, &item.Scope, &item.Issuer, &createdAt); err != nil {
+			slog.Error("failed to scan media blocklist entry", "error", err)
+			continue
+		}
+		item.CreatedAt = time.Unix(createdAt, 0)
+		list = append(list, item)
+	}
+	return list
+}
@@ -0,0 +1,39 @@
+package services
+
+import "testing"
+
+func TestMediaBlocklist(t *testing.T) {
+	blocklist := NewMediaBlocklist(newTestDB(t))
+
+	if !blocklist.Add(BlockedMedia{Kind: BlockSticker, Hash: "aa11", Scope: "g1@g.us"}) {
+		t.Fatal("Add should report a new entry")
+	}
+	if blocklist.Add(BlockedMedia{Kind: BlockSticker, Hash: "aa11", Scope: "g1@g.us"}) {
+		t.Error("Add should report an existing entry")
+	}
+	blocklist.Add(BlockedMedia{Kind: BlockSticker, Hash: "bb22", Scope: BanScopeGlobal})
+
+	if !blocklist.Contains(BlockSticker, "aa11", "g1@g.us") {
+		t.Error("group entry should apply in its group")
+	}
+	if blocklist.Contains(BlockSticker, "aa11", "g2@g.us") {
+		t.Error("group entry should not apply in other groups")
+	}
+	if !blocklist.Contains(BlockSticker, "bb22", "g2@g.us") {
+		t.Error("global entry should apply everywhere")
+	}
+
+	if got := blocklist.List(BlockSticker, "g1@g.us"); len(got) != 2 {
+		t.Errorf("List(g1) returned %d entries, want 2", len(got))
+	}
+	if got := blocklist.List(BlockSticker, "g2@g.us"); len(got) != 1 || got[0].Hash != "bb22" {
+		t.Errorf("List(g2) = %+v, want only the global entry", got)
+	}
+
+	if blocklist.Remove(BlockSticker, "bb22", "g1@g.us") {
+		t.Error("Remove should not lift a global entry through a group scope")
+	}
+	if !blocklist.Remove(BlockSticker, "bb22", BanScopeGlobal) {
+		t.Error("Remove should lift the global entry")
+	}
+}
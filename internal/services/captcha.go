@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// CaptchaSettings is the new-member verification configuration of a group.
+type CaptchaSettings struct {
+	Enabled bool
+	Timeout time.Duration // how long a new member has to answer before being kicked
+}
+
+// CaptchaChallenge is a verification question waiting for a new member's answer.
+type CaptchaChallenge struct {
+	GroupJID  string
+	JID       string
+	Answer    string
+	MessageID string // the bot's challenge message, revoked once the challenge is resolved
+	ExpiresAt time.Time
+}
+
+// CaptchaStore manages per-group captcha settings and pending challenges.
+// Both are checked for every group message, so they are kept in memory.
+type CaptchaStore struct {
+	db             *sql.DB
+	defaultTimeout time.Duration
+
+	mu       sync.RWMutex
+	enabled  map[string]bool
+	timeouts map[string]time.Duration    // only groups that set their own timeout
+	pending  map[string]CaptchaChallenge // keyed by captchaKey
+}
+
+// NewCaptchaStore creates a new store, ensures the tables exist and loads settings and pending challenges.
+func NewCaptchaStore(db *sql.DB, defaultTimeout time.Duration) *CaptchaStore {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS captcha_settings (
+			group_jid TEXT PRIMARY KEY,
+			enabled INTEGER NOT NULL DEFAULT 0,
+			timeout_sec INTEGER
+		);
+		CREATE TABLE IF NOT EXISTS captcha_challenges (
+			group_jid TEXT NOT NULL,
+			jid TEXT NOT NULL,
+			answer TEXT NOT NULL,
+			message_id TEXT NOT NULL DEFAULT '',
+			expires_at INTEGER NOT NULL,
+			PRIMARY KEY (group_jid, jid)
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create captcha tables", "error", err)
+		os.Exit(1)
+	}
+
+	store := &CaptchaStore{
+		db:             db,
+		defaultTimeout: defaultTimeout,
+		enabled:        make(map[string]bool),
+		timeouts:       make(map[string]time.Duration),
+		pending:        make(map[string]CaptchaChallenge),
+	}
+	store.load()
+	return store
+}
+
+// load reads settings and pending challenges into memory.
+func (s *CaptchaStore) load() {
+	rows, err := s.db.Query(`SELECT group_jid, enabled, timeout_sec FROM captcha_settings`)
+	if err != nil {
+		slog.Error("Failed to load captcha settings", "error", err)
+		os.Exit(1)
+	}
+	for rows.Next() {
+		var groupJID string
+		var enabled bool
+		var timeout sql.NullInt64
+		if err := rows.Scan(&groupJID, &enabled, &timeout); err != nil {
+			continue
+		}
+		if enabled {
+			s.enabled[groupJID] = true
+		}
+		if timeout.Valid {
+			s.timeouts[groupJID] = time.Duration(timeout.Int64) * time.Second
+		}
+	}
+	rows.Close()
+
+	rows, err = s.db.Query(`SELECT group_jid, jid, answer, message_id, expires_at FROM captcha_challenges`)
+	if err != nil {
+		slog.Error("Failed to load captcha challenges", "error", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c CaptchaChallenge
+		var expiresAt int64
+		if err := rows.Scan(&c.GroupJID, &c.JID, &c.Answer, &c.MessageID, &expiresAt); err != nil {
+			continue
+		}
+		c.ExpiresAt = time.Unix(expiresAt, 0)
+		s.pending[captchaKey(c.GroupJID, c.JID)] = c
+	}
+}
+
+// Settings returns a group's settings. Groups without their own timeout get the default.
+func (s *CaptchaStore) Settings(groupJID string) CaptchaSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	settings := CaptchaSettings{Enabled: s.enabled[groupJID], Timeout: s.defaultTimeout}
+	if timeout, ok := s.timeouts[groupJID]; ok {
+		settings.Timeout = timeout
+	}
+	return settings
+}
+
+// SetEnabled turns new-member verification on or off in a group.
+func (s *CaptchaStore) SetEnabled(groupJID string, enabled bool) bool {
+	_, err := s.db.Exec(`INSERT INTO captcha_settings (group_jid, enabled) VALUES (?, ?)
+		ON CONFLICT(group_jid) DO UPDATE SET enabled = excluded.enabled`, groupJID, enabled)
+	if err != nil {
+		slog.Error("failed to set captcha", "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	if enabled {
+		s.enabled[groupJID] = true
+	} else {
+		delete(s.enabled, groupJID)
+	}
+	s.mu.Unlock()
+	return true
+}
+
+// SetTimeout sets how long new members in a group have to answer. A zero timeout restores the default.
+func (s *CaptchaStore) SetTimeout(groupJID string, timeout time.Duration) bool {
+	value := sql.NullInt64{Int64: int64(timeout / time.Second), Valid: timeout > 0}
+	_, err := s.db.Exec(`INSERT INTO captcha_settings (group_jid, timeout_sec) VALUES (?, ?)
+		ON CONFLICT(group_jid) DO UPDATE SET timeout_sec = excluded.timeout_sec`, groupJID, value)
+	if err != nil {
+		slog.Error("failed to set captcha timeout", "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	if timeout > 0 {
+		s.timeouts[groupJID] = timeout
+	} else {
+		delete(s.timeouts, groupJID)
+	}
+	s.mu.Unlock()
+	return true
+}
+
+// Pending returns the challenge a member still has to answer in a group, if any.
+func (s *CaptchaStore) Pending(groupJID, jid string) (CaptchaChallenge, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.pending[captchaKey(groupJID, jid)]
+	return c, ok
+}
+
+// Add stores a challenge, replacing any earlier one for the same member.
+func (s *CaptchaStore) Add(c CaptchaChallenge) bool {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO captcha_challenges (group_jid, jid, answer, message_id, expires_at) VALUES (?, ?, ?, ?, ?)`,
+		c.GroupJID, c.JID, c.Answer, c.MessageID, c.ExpiresAt.Unix())
+	if err != nil {
+		slog.Error("failed to add captcha challenge", "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	s.pending[captchaKey(c.GroupJID, c.JID)] = c
+	s.mu.Unlock()
+	return true
+}
+
+// Remove deletes a member's challenge. Returns false if there was none,
+// so concurrent callers (answer vs. expiry) resolve a challenge only once.
+func (s *CaptchaStore) Remove(groupJID, jid string) bool {
+	key := captchaKey(groupJID, jid)
+
+	s.mu.Lock()
+	_, ok := s.pending[key]
+	delete(s.pending, key)
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	if _, err := s.db.Exec(`DELETE FROM captcha_challenges WHERE group_jid = ? AND jid = ?`, groupJID, jid); err != nil {
+		slog.Error("failed to remove captcha challenge", "error", err)
+	}
+	return true
+}
+
+// StartExpirySweeper runs a background goroutine that periodically removes unanswered challenges
+// and calls onExpire for each of them, e.g. to kick the member.
+func (s *CaptchaStore) StartExpirySweeper(ctx context.Context, interval time.Duration, onExpire func(CaptchaChallenge)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			for _, c := range s.sweepExpired(time.Now()) {
+				onExpire(c)
+			}
+		}
+	}()
+}
+
+// sweepExpired removes challenges that expired at or before now and returns them.
+func (s *CaptchaStore) sweepExpired(now time.Time) []CaptchaChallenge {
+	s.mu.RLock()
+	var candidates []CaptchaChallenge
+	for _, c := range s.pending {
+		if !c.ExpiresAt.After(now) {
+			candidates = append(candidates, c)
+		}
+	}
+	s.mu.RUnlock()
+
+	var expired []CaptchaChallenge
+	for _, c := range candidates {
+		if s.Remove(c.GroupJID, c.JID) {
+			expired = append(expired, c)
+		}
+	}
+	return expired
+}
+
+// captchaKey identifies a member within a group.
+func captchaKey(groupJID, jid string) string {
+	return groupJID + "|" + jid
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCaptchaStore_Settings(t *testing.T) {
+	db := newTestDB(t)
+	store := NewCaptchaStore(db, 5*time.Minute)
+
+	if got := store.Settings("g1@g.us"); got.Enabled || got.Timeout != 5*time.Minute {
+		t.Errorf("unset group = %+v, want disabled with the default timeout", got)
+	}
+
+	store.SetEnabled("g1@g.us", true)
+	store.SetTimeout("g1@g.us", 2*time.Minute)
+
+	reloaded := NewCaptchaStore(db, 5*time.Minute)
+	if got := reloaded.Settings("g1@g.us"); !got.Enabled || got.Timeout != 2*time.Minute {
+		t.Errorf("reloaded Settings() = %+v, want enabled with a 2m timeout", got)
+	}
+
+	reloaded.SetTimeout("g1@g.us", 0)
+	if got := reloaded.Settings("g1@g.us"); got.Timeout != 5*time.Minute {
+		t.Errorf("timeout 0 should restore the default, got %v", got.Timeout)
+	}
+}
+
+func TestCaptchaStore_Challenges(t *testing.T) {
+	db := newTestDB(t)
+	store := NewCaptchaStore(db, 5*time.Minute)
+	now := time.Now()
+
+	store.Add(CaptchaChallenge{GroupJID: "g1@g.us", JID: "u1@s.whatsapp.net", Answer: "12", MessageID: "m1", ExpiresAt: now.Add(-time.Second)})
+	store.Add(CaptchaChallenge{GroupJID: "g1@g.us", JID: "u2@s.whatsapp.net", Answer: "7", ExpiresAt: now.Add(time.Hour)})
+
+	// Pending challenges survive a restart.
+	reloaded := NewCaptchaStore(db, 5*time.Minute)
+	c, ok := reloaded.Pending("g1@g.us", "u1@s.whatsapp.net")
+	if !ok || c.Answer != "12" || c.MessageID != "m1" {
+		t.Fatalf("Pending() = %+v, %v; want the stored challenge", c, ok)
+	}
+	if _, ok := reloaded.Pending("g2@g.us", "u1@s.whatsapp.net"); ok {
+		t.Error("challenge should only apply in its group")
+	}
+
+	expired := reloaded.sweepExpired(now)
+	if len(expired) != 1 || expired[0].JID != "u1@s.whatsapp.net" {
+		t.Fatalf("sweepExpired() = %+v, want only u1", expired)
+	}
+	if _, ok := reloaded.Pending("g1@g.us", "u1@s.whatsapp.net"); ok {
+		t.Error("expired challenge should be removed")
+	}
+
+	if !reloaded.Remove("g1@g.us", "u2@s.whatsapp.net") {
+		t.Error("Remove should report the pending challenge")
+	}
+	if reloaded.Remove("g1@g.us", "u2@s.whatsapp.net") {
+		t.Error("a challenge should only be resolved once")
+	}
+	if again := NewCaptchaStore(db, 5*time.Minute); len(again.pending) != 0 {
+		t.Errorf("resolved challenges should be deleted from the database, %d left", len(again.pending))
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// FloodSettings holds a group's flood detection thresholds.
+type FloodSettings struct {
+	MaxMessages  int           // messages allowed within Window, 0 disables detection
+	Window       time.Duration // sliding window length
+	MuteDuration time.Duration // length of the temporary chat ban for flooders
+}
+
+// FloodStore manages persistent per-group flood thresholds.
+// Settings are read for every group message, so they are cached in memory.
+type FloodStore struct {
+	db       *sql.DB
+	defaults FloodSettings
+
+	mu    sync.RWMutex
+	cache map[string]FloodSettings
+}
+
+// NewFloodStore creates a new store and ensures the table exists.
+// defaults are used for groups without their own thresholds.
+func NewFloodStore(db *sql.DB, defaults FloodSettings) *FloodStore {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS flood_settings (
+			group_jid TEXT PRIMARY KEY,
+			max_messages INTEGER NOT NULL,
+			window_sec INTEGER NOT NULL,
+			mute_sec INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create flood_settings table", "error", err)
+		os.Exit(1)
+	}
+
+	return &FloodStore{db: db, defaults: defaults, cache: make(map[string]FloodSettings)}
+}
+
+// Defaults returns the thresholds used by groups without their own.
+func (s *FloodStore) Defaults() FloodSettings {
+	return s.defaults
+}
+
+// Settings returns the thresholds of a group, falling back to the defaults.
+func (s *FloodStore) Settings(groupJID string) FloodSettings {
+	s.mu.RLock()
+	settings, ok := s.cache[groupJID]
+	s.mu.RUnlock()
+	if ok {
+		return settings
+	}
+
+	var maxMessages, windowSec, muteSec int
+	err := s.db.QueryRow(`SELECT max_messages, window_sec, mute_sec FROM flood_settings WHERE group_jid = ?`, groupJID).
+		Scan(&maxMessages, &windowSec, &muteSec)
+	switch {
+	case err == nil:
+		settings = FloodSettings{
+			MaxMessages:  maxMessages,
+			Window:       time.Duration(windowSec) * time.Second,
+			MuteDuration: time.Duration(muteSec) * time.Second,
+		}
+	case err == sql.ErrNoRows:
+		settings = s.defaults
+	default:
+		// Do not cache on errors so the next message retries.
+		slog.Error("failed to load flood settings", "error", err)
+		return s.defaults
+	}
+
+	s.mu.Lock()
+	s.cache[groupJID] = settings
+	s.mu.Unlock()
+	return settings
+}
+
+// SetSettings stores custom thresholds for a group.
+func (s *FloodStore) SetSettings(groupJID string, settings FloodSettings) bool {
+	_, err := s.db.Exec(`
+		INSERT INTO flood_settings (group_jid, max_messages, window_sec, mute_sec) VALUES (?, ?, ?, ?)
+		ON CONFLICT (group_jid) DO UPDATE SET
+			max_messages = excluded.max_messages,
+			window_sec = excluded.window_sec,
+			mute_sec = excluded.mute_sec
+	`, groupJID, settings.MaxMessages, int(settings.Window/time.Second), int(settings.MuteDuration/time.Second))
+	if err != nil {
+		slog.Error("failed to set flood settings", "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	s.cache[groupJID] = settings
+	s.mu.Unlock()
+	return true
+}
+
+// Reset removes a group's custom thresholds so the defaults apply again.
+func (s *FloodStore) Reset(groupJID string) bool {
+	if _, err := s.db.Exec(`DELETE FROM flood_settings WHERE group_jid = ?`, groupJID); err != nil {
+		slog.Error("failed to reset flood settings", "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	delete(s.cache, groupJID)
+	s.mu.Unlock()
+	return true
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFloodStore_Settings(t *testing.T) {
+	db := newTestDB(t)
+	defaults := FloodSettings{MaxMessages: 8, Window: 10 * time.Second, MuteDuration: 10 * time.Minute}
+	store := NewFloodStore(db, defaults)
+
+	if got := store.Settings("g1@g.us"); got != defaults {
+		t.Errorf("Settings() = %+v, want defaults", got)
+	}
+
+	custom := FloodSettings{MaxMessages: 5, Window: 5 * time.Second, MuteDuration: time.Hour}
+	if !store.SetSettings("g1@g.us", custom) {
+		t.Fatal("SetSettings failed")
+	}
+	if got := store.Settings("g1@g.us"); got != custom {
+		t.Errorf("Settings() = %+v, want %+v", got, custom)
+	}
+
+	// A fresh store reads the persisted settings.
+	if got := NewFloodStore(db, defaults).Settings("g1@g.us"); got != custom {
+		t.Errorf("persisted Settings() = %+v, want %+v", got, custom)
+	}
+
+	if !store.Reset("g1@g.us") {
+		t.Fatal("Reset failed")
+	}
+	if got := store.Settings("g1@g.us"); got != defaults {
+		t.Errorf("Settings() after Reset = %+v, want defaults", got)
+	}
+}
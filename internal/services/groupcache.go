@@ -0,0 +1,172 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// GroupFetcher loads a group's metadata from WhatsApp.
+type GroupFetcher func(jid types.JID) (*types.GroupInfo, error)
+
+type cachedGroup struct {
+	info    *types.GroupInfo
+	fetched time.Time
+}
+
+// inflightFetch lets concurrent lookups of the same group share one network request.
+type inflightFetch struct {
+	done chan struct{}
+	info *types.GroupInfo
+	err  error
+}
+
+// GroupCache keeps group metadata in memory for a limited time and patches it from group change events.
+// Cached GroupInfo values are never modified in place: patches replace them with a copy,
+// so callers may keep reading a value they got earlier.
+type GroupCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	groups   map[types.JID]cachedGroup
+	inflight map[types.JID]*inflightFetch
+}
+
+// NewGroupCache creates a cache whose entries are refetched after ttl.
+func NewGroupCache(ttl time.Duration) *GroupCache {
+	return &GroupCache{
+		ttl:      ttl,
+		now:      time.Now,
+		groups:   make(map[types.JID]cachedGroup),
+		inflight: make(map[types.JID]*inflightFetch),
+	}
+}
+
+// Get returns the cached metadata of a group, calling fetch if it is missing or expired.
+func (c *GroupCache) Get(jid types.JID, fetch GroupFetcher) (*types.GroupInfo, error) {
+	c.mu.Lock()
+	if cached, ok := c.groups[jid]; ok && c.now().Sub(cached.fetched) < c.ttl {
+		c.mu.Unlock()
+		return cached.info, nil
+	}
+	if f, ok := c.inflight[jid]; ok {
+		c.mu.Unlock()
+		<-f.done
+		return f.info, f.err
+	}
+	f := &inflightFetch{done: make(chan struct{})}
+	c.inflight[jid] = f
+	c.mu.Unlock()
+
+	f.info, f.err = fetch(jid)
+
+	c.mu.Lock()
+	delete(c.inflight, jid)
+	if f.err == nil {
+		c.groups[jid] = cachedGroup{info: f.info, fetched: c.now()}
+	}
+	c.mu.Unlock()
+	close(f.done)
+
+	return f.info, f.err
+}
+
+// Set stores fresh metadata, e.g. from a JoinedGroup event.
+func (c *GroupCache) Set(info *types.GroupInfo) {
+	c.mu.Lock()
+	c.groups[info.JID] = cachedGroup{info: info, fetched: c.now()}
+	c.mu.Unlock()
+}
+
+// Invalidate drops a group so the next lookup fetches it again.
+func (c *GroupCache) Invalidate(jid types.JID) {
+	c.mu.Lock()
+	delete(c.groups, jid)
+	c.mu.Unlock()
+}
+
+// Apply patches a cached group with the changes in a group event.
+// Changes the cache cannot reproduce, such as the group being deleted, drop the entry instead.
+func (c *GroupCache) Apply(evt *events.GroupInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.groups[evt.JID]
+	if !ok {
+		return
+	}
+	if evt.Delete != nil || evt.Suspended || evt.Unsuspended || len(evt.UnknownChanges) > 0 {
+		delete(c.groups, evt.JID)
+		return
+	}
+
+	info := *cached.info
+	info.Participants = append([]types.GroupParticipant(nil), cached.info.Participants...)
+
+	if evt.Name != nil {
+		info.GroupName = *evt.Name
+	}
+	if evt.Topic != nil {
+		info.GroupTopic = *evt.Topic
+	}
+	if evt.Locked != nil {
+		info.GroupLocked = *evt.Locked
+	}
+	if evt.Announce != nil {
+		info.GroupAnnounce = *evt.Announce
+	}
+	if evt.Ephemeral != nil {
+		info.GroupEphemeral = *evt.Ephemeral
+	}
+	if evt.MembershipApprovalMode != nil {
+		info.GroupMembershipApprovalMode = *evt.MembershipApprovalMode
+	}
+
+	for _, jid := range evt.Leave {
+		if i := participantIndex(info.Participants, jid); i >= 0 {
+			info.Participants = append(info.Participants[:i], info.Participants[i+1:]...)
+		}
+	}
+	for _, jid := range evt.Join {
+		if participantIndex(info.Participants, jid) >= 0 {
+			continue
+		}
+		p := types.GroupParticipant{JID: jid}
+		if jid.Server == types.HiddenUserServer {
+			p.LID = jid
+		} else {
+			p.PhoneNumber = jid
+		}
+		info.Participants = append(info.Participants, p)
+	}
+	for _, jid := range evt.Promote {
+		if i := participantIndex(info.Participants, jid); i >= 0 {
+			info.Participants[i].IsAdmin = true
+		}
+	}
+	for _, jid := range evt.Demote {
+		if i := participantIndex(info.Participants, jid); i >= 0 {
+			info.Participants[i].IsAdmin = false
+			info.Participants[i].IsSuperAdmin = false
+		}
+	}
+	info.ParticipantCount = len(info.Participants)
+	if evt.ParticipantVersionID != "" {
+		info.ParticipantVersionID = evt.ParticipantVersionID
+	}
+
+	c.groups[evt.JID] = cachedGroup{info: &info, fetched: cached.fetched}
+}
+
+// participantIndex finds a participant by phone number or LID.
+func participantIndex(participants []types.GroupParticipant, jid types.JID) int {
+	for i, p := range participants {
+		if IsParticipant(p, jid) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+func testGroup() *types.GroupInfo {
+	return &types.GroupInfo{
+		JID:       types.NewJID("123", types.GroupServer),
+		GroupName: types.GroupName{Name: "Old"},
+		Participants: []types.GroupParticipant{
+			{JID: types.NewJID("111", types.DefaultUserServer), IsAdmin: true},
+			{JID: types.NewJID("222", types.HiddenUserServer), PhoneNumber: types.NewJID("628222", types.DefaultUserServer)},
+		},
+		ParticipantCount: 2,
+	}
+}
+
+func TestGroupCache_TTL(t *testing.T) {
+	cache := NewGroupCache(time.Minute)
+	now := time.Unix(1_700_000_000, 0)
+	cache.now = func() time.Time { return now }
+
+	var fetches int
+	fetch := func(jid types.JID) (*types.GroupInfo, error) {
+		fetches++
+		return testGroup(), nil
+	}
+	jid := testGroup().JID
+
+	cache.Get(jid, fetch)
+	cache.Get(jid, fetch)
+	if fetches != 1 {
+		t.Errorf("second lookup within TTL fetched again (%d fetches)", fetches)
+	}
+
+	now = now.Add(time.Minute)
+	cache.Get(jid, fetch)
+	if fetches != 2 {
+		t.Errorf("lookup after TTL should refetch (%d fetches)", fetches)
+	}
+
+	cache.Invalidate(jid)
+	cache.Get(jid, fetch)
+	if fetches != 3 {
+		t.Errorf("lookup after Invalidate should refetch (%d fetches)", fetches)
+	}
+
+	failing := func(types.JID) (*types.GroupInfo, error) { return nil, errors.New("offline") }
+	cache.Invalidate(jid)
+	if _, err := cache.Get(jid, failing); err == nil {
+		t.Error("fetch errors should be returned")
+	}
+	cache.Get(jid, fetch)
+	if fetches != 4 {
+		t.Errorf("failed fetches must not be cached (%d fetches)", fetches)
+	}
+}
+
+func TestGroupCache_ConcurrentMissFetchesOnce(t *testing.T) {
+	cache := NewGroupCache(time.Minute)
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	fetch := func(types.JID) (*types.GroupInfo, error) {
+		fetches.Add(1)
+		<-release
+		return testGroup(), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if info, err := cache.Get(testGroup().JID, fetch); err != nil || info == nil {
+				t.Errorf("Get() = %v, %v", info, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("concurrent misses made %d fetches, want 1", n)
+	}
+}
+
+func TestGroupCache_Apply(t *testing.T) {
+	cache := NewGroupCache(time.Minute)
+	original := testGroup()
+	cache.Set(original)
+
+	cache.Apply(&events.GroupInfo{
+		JID:      original.JID,
+		Name:     &types.GroupName{Name: "New"},
+		Announce: &types.GroupAnnounce{IsAnnounce: true},
+		Join:     []types.JID{types.NewJID("333", types.DefaultUserServer)},
+		Leave:    []types.JID{types.NewJID("111", types.DefaultUserServer)},
+		Promote:  []types.JID{types.NewJID("628222", types.DefaultUserServer)}, // matched through the phone number
+	})
+
+	got, _ := cache.Get(original.JID, func(types.JID) (*types.GroupInfo, error) {
+		t.Fatal("patched entry should not be refetched")
+		return nil, nil
+	})
+	if got.Name != "New" || !got.IsAnnounce {
+		t.Errorf("metadata not patched: name %q, announce %v", got.Name, got.IsAnnounce)
+	}
+	if len(got.Participants) != 2 || got.ParticipantCount != 2 {
+		t.Fatalf("participants = %+v, want 222 and 333", got.Participants)
+	}
+	if got.Participants[0].JID.User != "222" || !got.Participants[0].IsAdmin {
+		t.Errorf("222 should be promoted: %+v", got.Participants[0])
+	}
+	if got.Participants[1].JID.User != "333" || got.Participants[1].IsAdmin {
+		t.Errorf("333 should have joined as a member: %+v", got.Participants[1])
+	}
+
+	// Readers holding the old value are not affected by patches.
+	if original.Name != "Old" || len(original.Participants) != 2 || original.Participants[0].JID.User != "111" {
+		t.Errorf("original value was modified: %+v", original)
+	}
+
+	cache.Apply(&events.GroupInfo{JID: original.JID, Delete: &types.GroupDelete{Deleted: true}})
+	fetched := false
+	cache.Get(original.JID, func(types.JID) (*types.GroupInfo, error) {
+		fetched = true
+		return testGroup(), nil
+	})
+	if !fetched {
+		t.Error("deleted group should be dropped from the cache")
+	}
+}
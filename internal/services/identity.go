@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// LIDMapper translates between phone numbers and LIDs. whatsmeow's LID store implements it.
+type LIDMapper interface {
+	GetPNForLID(ctx context.Context, lid types.JID) (types.JID, error)
+	GetLIDForPN(ctx context.Context, pn types.JID) (types.JID, error)
+}
+
+// Identity resolves the phone number (PN) and LID forms of a WhatsApp user.
+// Stored keys use the PN form whenever the mapping is known, because that is how rows were
+// stored before groups moved to LIDs; LIDs without a known phone number are kept as they are.
+type Identity struct {
+	lids LIDMapper
+}
+
+// NewIdentity creates an Identity backed by lids. A nil mapper treats every JID as its own only form.
+func NewIdentity(lids LIDMapper) *Identity {
+	return &Identity{lids: lids}
+}
+
+// Canonical returns the PN form of jid if it is a LID with a known phone number, without the device part.
+func (i *Identity) Canonical(jid types.JID) types.JID {
+	jid = jid.ToNonAD()
+	if jid.Server != types.HiddenUserServer {
+		return jid
+	}
+	if pn := i.lookup(jid); !pn.IsEmpty() {
+		return pn
+	}
+	return jid
+}
+
+// Key returns the string key a user is stored under.
+func (i *Identity) Key(jid types.JID) string {
+	return i.Canonical(jid).String()
+}
+
+// Sender returns the canonical JID of a message's sender. LID-addressed messages carry the
+// sender's phone number as SenderAlt, which saves a lookup.
+func (i *Identity) Sender(info types.MessageSource) types.JID {
+	if info.Sender.Server == types.HiddenUserServer && info.SenderAlt.Server == types.DefaultUserServer {
+		return info.SenderAlt.ToNonAD()
+	}
+	return i.Canonical(info.Sender)
+}
+
+// Forms returns every known form of jid, canonical form first.
+func (i *Identity) Forms(jid types.JID) []types.JID {
+	jid = jid.ToNonAD()
+	alt := i.lookup(jid)
+	switch {
+	case alt.IsEmpty():
+		return []types.JID{jid}
+	case jid.Server == types.HiddenUserServer:
+		return []types.JID{alt, jid}
+	default:
+		return []types.JID{jid, alt}
+	}
+}
+
+// Same reports whether a and b are known forms of the same user.
+func (i *Identity) Same(a, b types.JID) bool {
+	for _, fa := range i.Forms(a) {
+		for _, fb := range i.Forms(b) {
+			if fa == fb {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Keys returns the string keys of every known form of jid, canonical form first.
+func (i *Identity) Keys(jid types.JID) []string {
+	forms := i.Forms(jid)
+	keys := make([]string, len(forms))
+	for n, form := range forms {
+		keys[n] = form.String()
+	}
+	return keys
+}
+
+// lookup returns the other form of a PN or LID, or an empty JID if it is unknown.
+func (i *Identity) lookup(jid types.JID) types.JID {
+	if i.lids == nil {
+		return types.EmptyJID
+	}
+
+	var alt types.JID
+	var err error
+	switch jid.Server {
+	case types.HiddenUserServer:
+		alt, err = i.lids.GetPNForLID(context.Background(), jid)
+	case types.DefaultUserServer:
+		alt, err = i.lids.GetLIDForPN(context.Background(), jid)
+	default:
+		return types.EmptyJID
+	}
+	if err != nil {
+		slog.Warn("failed to resolve LID mapping", "jid", jid.String(), "error", err)
+		return types.EmptyJID
+	}
+	if alt.IsEmpty() {
+		return types.EmptyJID
+	}
+	return alt.ToNonAD()
+}
+
+// IsParticipant reports whether a group participant is jid, comparing the phone number and LID
+// forms within their own namespace.
+func IsParticipant(p types.GroupParticipant, jid types.JID) bool {
+	jid = jid.ToNonAD()
+	if jid.IsEmpty() {
+		return false
+	}
+	return p.JID.ToNonAD() == jid || p.PhoneNumber.ToNonAD() == jid || p.LID.ToNonAD() == jid
+}
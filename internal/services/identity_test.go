@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// fakeLIDs is an in-memory LIDMapper keyed by user part.
+type fakeLIDs map[string]string // LID user -> PN user
+
+func (f fakeLIDs) GetPNForLID(_ context.Context, lid types.JID) (types.JID, error) {
+	if pn, ok := f[lid.User]; ok {
+		return types.NewJID(pn, types.DefaultUserServer), nil
+	}
+	return types.EmptyJID, nil
+}
+
+func (f fakeLIDs) GetLIDForPN(_ context.Context, pn types.JID) (types.JID, error) {
+	for lid, user := range f {
+		if user == pn.User {
+			return types.NewJID(lid, types.HiddenUserServer), nil
+		}
+	}
+	return types.EmptyJID, nil
+}
+
+func TestIdentity(t *testing.T) {
+	identity := NewIdentity(fakeLIDs{"111": "628111"})
+	pn := types.NewJID("628111", types.DefaultUserServer)
+	lid := types.NewJID("111", types.HiddenUserServer)
+	unknown := types.NewJID("999", types.HiddenUserServer)
+
+	tests := []struct {
+		name      string
+		in        types.JID
+		canonical types.JID
+		forms     []types.JID
+	}{
+		{"known LID", lid, pn, []types.JID{pn, lid}},
+		{"LID with device", types.JID{User: "111", Device: 3, Server: types.HiddenUserServer}, pn, []types.JID{pn, lid}},
+		{"known PN", pn, pn, []types.JID{pn, lid}},
+		{"unknown LID", unknown, unknown, []types.JID{unknown}},
+	}
+	for _, tt := range tests {
+		if got := identity.Canonical(tt.in); got != tt.canonical {
+			t.Errorf("%s: Canonical() = %s, want %s", tt.name, got, tt.canonical)
+		}
+		if got := identity.Forms(tt.in); !reflect.DeepEqual(got, tt.forms) {
+			t.Errorf("%s: Forms() = %v, want %v", tt.name, got, tt.forms)
+		}
+	}
+
+	if !identity.Same(lid, pn) || !identity.Same(pn, types.JID{User: "111", Device: 3, Server: types.HiddenUserServer}) {
+		t.Error("Same() should match the PN and LID forms of one user")
+	}
+	if identity.Same(lid, unknown) || identity.Same(types.NewJID("111", types.DefaultUserServer), lid) {
+		t.Error("Same() should not match different users with the same user part")
+	}
+
+	// SenderAlt is used without a lookup.
+	src := types.MessageSource{Sender: unknown, SenderAlt: types.NewJID("62999", types.DefaultUserServer)}
+	if got := identity.Sender(src); got.String() != "62999@s.whatsapp.net" {
+		t.Errorf("Sender() = %s, want the SenderAlt phone number", got)
+	}
+}
+
+func TestIsParticipant(t *testing.T) {
+	p := types.GroupParticipant{
+		JID:         types.NewJID("111", types.HiddenUserServer),
+		PhoneNumber: types.NewJID("628111", types.DefaultUserServer),
+		LID:         types.NewJID("111", types.HiddenUserServer),
+	}
+	if !IsParticipant(p, types.NewJID("628111", types.DefaultUserServer)) {
+		t.Error("participant should match their phone number")
+	}
+	if !IsParticipant(p, types.NewJID("111", types.HiddenUserServer)) {
+		t.Error("participant should match their LID")
+	}
+	// The same digits in the other namespace are a different user.
+	if IsParticipant(p, types.NewJID("111", types.DefaultUserServer)) {
+		t.Error("phone number 111 must not match LID 111")
+	}
+	if IsParticipant(types.GroupParticipant{JID: types.NewJID("222", types.HiddenUserServer)}, types.EmptyJID) {
+		t.Error("an empty JID must not match a participant without a phone number")
+	}
+}
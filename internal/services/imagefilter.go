@@ -0,0 +1,121 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// ImageFilterSettings is the perceptual image blocklist configuration of a group.
+type ImageFilterSettings struct {
+	Enabled     bool
+	MaxDistance int // highest Hamming distance that still counts as the same image
+}
+
+// ImageFilterStore manages per-group image filter settings.
+// Settings are read for every image in a group, so they are kept in memory.
+type ImageFilterStore struct {
+	db              *sql.DB
+	defaultDistance int
+
+	mu       sync.RWMutex
+	enabled  map[string]bool
+	distance map[string]int // only groups that set their own distance
+}
+
+// NewImageFilterStore creates a new store, ensures the table exists and loads the settings.
+// defaultDistance applies to groups that have not set their own distance.
+func NewImageFilterStore(db *sql.DB, defaultDistance int) *ImageFilterStore {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS image_filter_settings (
+			group_jid TEXT PRIMARY KEY,
+			enabled INTEGER NOT NULL DEFAULT 0,
+			max_distance INTEGER
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create image_filter_settings table", "error", err)
+		os.Exit(1)
+	}
+
+	store := &ImageFilterStore{
+		db:              db,
+		defaultDistance: defaultDistance,
+		enabled:         make(map[string]bool),
+		distance:        make(map[string]int),
+	}
+
+	rows, err := db.Query(`SELECT group_jid, enabled, max_distance FROM image_filter_settings`)
+	if err != nil {
+		slog.Error("Failed to load image filter settings", "error", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var groupJID string
+		var enabled bool
+		var distance sql.NullInt64
+		if err := rows.Scan(&groupJID, &enabled, &distance); err != nil {
+			continue
+		}
+		if enabled {
+			store.enabled[groupJID] = true
+		}
+		if distance.Valid {
+			store.distance[groupJID] = int(distance.Int64)
+		}
+	}
+	return store
+}
+
+// Settings returns a group's settings. Groups without their own distance get the default.
+func (s *ImageFilterStore) Settings(groupJID string) ImageFilterSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	settings := ImageFilterSettings{Enabled: s.enabled[groupJID], MaxDistance: s.defaultDistance}
+	if distance, ok := s.distance[groupJID]; ok {
+		settings.MaxDistance = distance
+	}
+	return settings
+}
+
+// SetEnabled turns the image filter on or off in a group.
+func (s *ImageFilterStore) SetEnabled(groupJID string, enabled bool) bool {
+	_, err := s.db.Exec(`INSERT INTO image_filter_settings (group_jid, enabled) VALUES (?, ?)
+		ON CONFLICT(group_jid) DO UPDATE SET enabled = excluded.enabled`, groupJID, enabled)
+	if err != nil {
+		slog.Error("failed to set image filter", "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	if enabled {
+		s.enabled[groupJID] = true
+	} else {
+		delete(s.enabled, groupJID)
+	}
+	s.mu.Unlock()
+	return true
+}
+
+// SetMaxDistance sets a group's matching distance. A negative distance restores the default.
+func (s *ImageFilterStore) SetMaxDistance(groupJID string, distance int) bool {
+	value := sql.NullInt64{Int64: int64(distance), Valid: distance >= 0}
+	_, err := s.db.Exec(`INSERT INTO image_filter_settings (group_jid, max_distance) VALUES (?, ?)
+		ON CONFLICT(group_jid) DO UPDATE SET max_distance = excluded.max_distance`, groupJID, value)
+	if err != nil {
+		slog.Error("failed to set image filter distance", "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	if distance >= 0 {
+		s.distance[groupJID] = distance
+	} else {
+		delete(s.distance, groupJID)
+	}
+	s.mu.Unlock()
+	return true
+}
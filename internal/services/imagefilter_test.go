@@ -0,0 +1,35 @@
+package services
+
+import "testing"
+
+func TestImageFilterStore(t *testing.T) {
+	db := newTestDB(t)
+	store := NewImageFilterStore(db, 10)
+
+	if got := store.Settings("g1@g.us"); got.Enabled || got.MaxDistance != 10 {
+		t.Errorf("unset group = %+v, want disabled with the default distance", got)
+	}
+
+	store.SetMaxDistance("g1@g.us", 4)
+	store.SetEnabled("g1@g.us", true)
+	if got := store.Settings("g1@g.us"); !got.Enabled || got.MaxDistance != 4 {
+		t.Errorf("Settings() = %+v, want enabled with distance 4", got)
+	}
+
+	// Settings survive a restart.
+	reloaded := NewImageFilterStore(db, 10)
+	if got := reloaded.Settings("g1@g.us"); !got.Enabled || got.MaxDistance != 4 {
+		t.Errorf("reloaded Settings() = %+v, want enabled with distance 4", got)
+	}
+
+	reloaded.SetMaxDistance("g1@g.us", 0)
+	if got := reloaded.Settings("g1@g.us"); got.MaxDistance != 0 {
+		t.Errorf("distance 0 should mean exact matches only, got %d", got.MaxDistance)
+	}
+
+	reloaded.SetMaxDistance("g1@g.us", -1)
+	reloaded.SetEnabled("g1@g.us", false)
+	if got := reloaded.Settings("g1@g.us"); got.Enabled || got.MaxDistance != 10 {
+		t.Errorf("Settings() = %+v, want disabled with the default distance", got)
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CachedMessage is a copy of a received message kept so it can be restored after a revoke.
+type CachedMessage struct {
+	Chat      string
+	ID        string
+	Sender    string
+	Text      string // text or caption
+	MediaType string // "image", "video", "audio", "sticker", "document" or empty for text
+	Media     []byte // downloaded media, nil if the file was too large or failed to download
+	Mimetype  string
+	FileName  string
+	Animated  bool // animated sticker
+	PTT       bool // voice note
+	At        time.Time
+}
+
+// size is the memory accounted for a cached message.
+func (m *CachedMessage) size() int64 {
+	return int64(len(m.Media) + len(m.Text))
+}
+
+// MessageCache is an in-memory cache of recent messages bounded by entry count,
+// total size and age. When full, the oldest messages are evicted first.
+type MessageCache struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element // chat|id -> element holding *CachedMessage
+	order      *list.List               // oldest at the front
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	ttl        time.Duration
+}
+
+// NewMessageCache creates a cache holding at most maxEntries messages and maxBytes of content,
+// each for at most ttl.
+func NewMessageCache(maxEntries int, maxBytes int64, ttl time.Duration) *MessageCache {
+	return &MessageCache{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ttl:        ttl,
+	}
+}
+
+// Put stores a message, evicting expired and then the oldest messages to stay within bounds.
+// A zero At is set to now. Messages larger than the whole cache are not stored.
+func (c *MessageCache) Put(msg CachedMessage) {
+	if msg.At.IsZero() {
+		msg.At = time.Now()
+	}
+	if c.maxEntries < 1 || msg.size() > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := msg.Chat + "|" + msg.ID
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+
+	c.evictExpired(time.Now())
+	for c.order.Len() >= c.maxEntries || c.bytes+msg.size() > c.maxBytes {
+		c.remove(c.order.Front())
+	}
+
+	c.entries[key] = c.order.PushBack(&msg)
+	c.bytes += msg.size()
+}
+
+// Take removes and returns a cached message. Returns false if it is unknown or expired.
+func (c *MessageCache) Take(chat, id string) (CachedMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[chat+"|"+id]
+	if !ok {
+		return CachedMessage{}, false
+	}
+	msg := *el.Value.(*CachedMessage)
+	c.remove(el)
+
+	if time.Since(msg.At) > c.ttl {
+		return CachedMessage{}, false
+	}
+	return msg, true
+}
+
+// Len returns the number of cached messages.
+func (c *MessageCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *MessageCache) evictExpired(now time.Time) {
+	for el := c.order.Front(); el != nil; el = c.order.Front() {
+		if now.Sub(el.Value.(*CachedMessage).At) <= c.ttl {
+			return
+		}
+		c.remove(el)
+	}
+}
+
+func (c *MessageCache) remove(el *list.Element) {
+	msg := c.order.Remove(el).(*CachedMessage)
+	delete(c.entries, msg.Chat+"|"+msg.ID)
+	c.bytes -= msg.size()
+}
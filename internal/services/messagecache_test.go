@@ -0,0 +1,74 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMessageCache_PutTake(t *testing.T) {
+	cache := NewMessageCache(10, 1024, time.Hour)
+
+	cache.Put(CachedMessage{Chat: "g1@g.us", ID: "m1", Text: "halo"})
+
+	if _, ok := cache.Take("g2@g.us", "m1"); ok {
+		t.Error("messages should be keyed by chat")
+	}
+	msg, ok := cache.Take("g1@g.us", "m1")
+	if !ok || msg.Text != "halo" {
+		t.Fatalf("Take() = %+v, %v, want cached message", msg, ok)
+	}
+	if _, ok := cache.Take("g1@g.us", "m1"); ok {
+		t.Error("Take should remove the message")
+	}
+}
+
+func TestMessageCache_EvictsOldest(t *testing.T) {
+	cache := NewMessageCache(3, 1024, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		cache.Put(CachedMessage{Chat: "g1@g.us", ID: fmt.Sprintf("m%d", i)})
+	}
+	if cache.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", cache.Len())
+	}
+	if _, ok := cache.Take("g1@g.us", "m1"); ok {
+		t.Error("oldest messages should be evicted")
+	}
+	if _, ok := cache.Take("g1@g.us", "m4"); !ok {
+		t.Error("newest message should be kept")
+	}
+}
+
+func TestMessageCache_SizeLimit(t *testing.T) {
+	cache := NewMessageCache(10, 100, time.Hour)
+
+	cache.Put(CachedMessage{Chat: "g1@g.us", ID: "big", Media: make([]byte, 101)})
+	if cache.Len() != 0 {
+		t.Error("messages larger than the cache should not be stored")
+	}
+
+	cache.Put(CachedMessage{Chat: "g1@g.us", ID: "a", Media: make([]byte, 60)})
+	cache.Put(CachedMessage{Chat: "g1@g.us", ID: "b", Media: make([]byte, 60)})
+	if _, ok := cache.Take("g1@g.us", "a"); ok {
+		t.Error("older media should be evicted to stay within the size limit")
+	}
+	if _, ok := cache.Take("g1@g.us", "b"); !ok {
+		t.Error("newer media should be kept")
+	}
+}
+
+func TestMessageCache_TTL(t *testing.T) {
+	cache := NewMessageCache(10, 1024, time.Minute)
+
+	cache.Put(CachedMessage{Chat: "g1@g.us", ID: "old", At: time.Now().Add(-2 * time.Minute)})
+	if _, ok := cache.Take("g1@g.us", "old"); ok {
+		t.Error("expired messages should not be returned")
+	}
+
+	cache.Put(CachedMessage{Chat: "g1@g.us", ID: "old", At: time.Now().Add(-2 * time.Minute)})
+	cache.Put(CachedMessage{Chat: "g1@g.us", ID: "new"})
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want expired message evicted", cache.Len())
+	}
+}
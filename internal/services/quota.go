@@ -0,0 +1,240 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// QuotaMetric identifies a resource that is counted against daily quotas.
+type QuotaMetric string
+
+const (
+	QuotaDownloads     QuotaMetric = "downloads"      // number of .dl/.mp3 downloads
+	QuotaDownloadBytes QuotaMetric = "download_bytes" // bytes delivered by .dl/.mp3
+	QuotaMedia         QuotaMetric = "media"          // number of sticker/image conversions
+)
+
+// QuotaLimits holds the daily limits for a metric. A limit of 0 means unlimited.
+type QuotaLimits struct {
+	PerUser  int64
+	PerGroup int64
+}
+
+// QuotaUsage describes today's usage and limits for a metric.
+type QuotaUsage struct {
+	Metric    QuotaMetric
+	Limits    QuotaLimits
+	UserUsed  int64
+	GroupUsed int64
+}
+
+// QuotaStore manages persistent per-user and per-group daily quotas.
+// Usage rows are keyed by subject (user or group JID), metric and local calendar day.
+type QuotaStore struct {
+	db       *sql.DB
+	defaults map[QuotaMetric]QuotaLimits
+
+	mu        sync.Mutex
+	prunedDay string
+
+	reserveMu sync.Mutex // makes Reserve's check and record atomic
+}
+
+// NewQuotaStore creates a new store and ensures the tables exist.
+// defaults are used for groups without their own limits.
+func NewQuotaStore(db *sql.DB, defaults map[QuotaMetric]QuotaLimits) *QuotaStore {
+	store := &QuotaStore{db: db, defaults: defaults}
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS quota_usage (
+			subject TEXT NOT NULL,
+			metric TEXT NOT NULL,
+			day TEXT NOT NULL,
+			used INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (subject, metric, day)
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create quota_usage table", "error", err)
+		os.Exit(1)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS group_quotas (
+			group_jid TEXT NOT NULL,
+			metric TEXT NOT NULL,
+			user_limit INTEGER NOT NULL,
+			group_limit INTEGER NOT NULL,
+			PRIMARY KEY (group_jid, metric)
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create group_quotas table", "error", err)
+		os.Exit(1)
+	}
+
+	store.pruneOldUsage()
+	return store
+}
+
+// Limits returns the daily limits for a metric in a group, falling back to the defaults.
+// An empty groupJID (private chat) always uses the defaults.
+func (s *QuotaStore) Limits(groupJID string, metric QuotaMetric) QuotaLimits {
+	limits := s.defaults[metric]
+	if groupJID == "" {
+		return limits
+	}
+
+	var userLimit, groupLimit int64
+	err := s.db.QueryRow(`SELECT user_limit, group_limit FROM group_quotas WHERE group_jid = ? AND metric = ?`,
+		groupJID, string(metric)).Scan(&userLimit, &groupLimit)
+	if err == nil {
+		limits = QuotaLimits{PerUser: userLimit, PerGroup: groupLimit}
+	} else if err != sql.ErrNoRows {
+		slog.Error("Error reading group quota", "error", err)
+	}
+	return limits
+}
+
+// SetLimits overrides the daily limits for a metric in a group. Returns true on success.
+func (s *QuotaStore) SetLimits(groupJID string, metric QuotaMetric, limits QuotaLimits) bool {
+	_, err := s.db.Exec(`INSERT INTO group_quotas (group_jid, metric, user_limit, group_limit) VALUES (?, ?, ?, ?)
+		ON CONFLICT(group_jid, metric) DO UPDATE SET user_limit = excluded.user_limit, group_limit = excluded.group_limit`,
+		groupJID, string(metric), limits.PerUser, limits.PerGroup)
+	if err != nil {
+		slog.Error("Error setting group quota", "error", err)
+		return false
+	}
+	return true
+}
+
+// ResetLimits removes all quota overrides of a group so the defaults apply again.
+func (s *QuotaStore) ResetLimits(groupJID string) bool {
+	if _, err := s.db.Exec(`DELETE FROM group_quotas WHERE group_jid = ?`, groupJID); err != nil {
+		slog.Error("Error resetting group quotas", "error", err)
+		return false
+	}
+	return true
+}
+
+// Usage returns today's usage of a metric for the user and group.
+func (s *QuotaStore) Usage(userJID, groupJID string, metric QuotaMetric) QuotaUsage {
+	return s.usageOn(userJID, groupJID, metric, today())
+}
+
+func (s *QuotaStore) usageOn(userJID, groupJID string, metric QuotaMetric, day string) QuotaUsage {
+	usage := QuotaUsage{
+		Metric:   metric,
+		Limits:   s.Limits(groupJID, metric),
+		UserUsed: s.used(userJID, metric, day),
+	}
+	if groupJID != "" {
+		usage.GroupUsed = s.used(groupJID, metric, day)
+	}
+	return usage
+}
+
+// Allow checks whether the user (and the group, if any) still has quota left for a metric today.
+func (s *QuotaStore) Allow(userJID, groupJID string, metric QuotaMetric) bool {
+	return s.Usage(userJID, groupJID, metric).fits(1, groupJID != "")
+}
+
+// Reserve records amount units of a metric for the user and group if they fit in today's
+// quotas, atomically with respect to other reservations. Returns the day the units were
+// recorded on, or false and records nothing if they do not fit. Work that fails after a
+// reservation should give it back with Refund on the returned day.
+func (s *QuotaStore) Reserve(userJID, groupJID string, metric QuotaMetric, amount int64) (string, bool) {
+	s.reserveMu.Lock()
+	defer s.reserveMu.Unlock()
+
+	day := today()
+	if !s.usageOn(userJID, groupJID, metric, day).fits(amount, groupJID != "") {
+		return "", false
+	}
+	s.consumeOn(userJID, groupJID, metric, amount, day)
+	return day, true
+}
+
+// Refund gives back amount units of a metric reserved for the user and group on day.
+// A reservation made before midnight is refunded against that day, not today's quota.
+func (s *QuotaStore) Refund(userJID, groupJID string, metric QuotaMetric, amount int64, day string) {
+	subjects := []string{userJID}
+	if groupJID != "" {
+		subjects = append(subjects, groupJID)
+	}
+	for _, subject := range subjects {
+		_, err := s.db.Exec(`UPDATE quota_usage SET used = MAX(used - ?, 0) WHERE subject = ? AND metric = ? AND day = ?`,
+			amount, subject, string(metric), day)
+		if err != nil {
+			slog.Error("Error refunding quota usage", "error", err)
+		}
+	}
+}
+
+// fits reports whether amount more units stay within the limits.
+// The group limit only applies to usage in a group.
+func (u QuotaUsage) fits(amount int64, inGroup bool) bool {
+	if u.Limits.PerUser > 0 && u.UserUsed+amount > u.Limits.PerUser {
+		return false
+	}
+	if inGroup && u.Limits.PerGroup > 0 && u.GroupUsed+amount > u.Limits.PerGroup {
+		return false
+	}
+	return true
+}
+
+// Consume records amount units of a metric for the user and group for today.
+func (s *QuotaStore) Consume(userJID, groupJID string, metric QuotaMetric, amount int64) {
+	s.consumeOn(userJID, groupJID, metric, amount, today())
+}
+
+func (s *QuotaStore) consumeOn(userJID, groupJID string, metric QuotaMetric, amount int64, day string) {
+	s.pruneOldUsage()
+
+	subjects := []string{userJID}
+	if groupJID != "" {
+		subjects = append(subjects, groupJID)
+	}
+	for _, subject := range subjects {
+		_, err := s.db.Exec(`INSERT INTO quota_usage (subject, metric, day, used) VALUES (?, ?, ?, ?)
+			ON CONFLICT(subject, metric, day) DO UPDATE SET used = used + excluded.used`,
+			subject, string(metric), day, amount)
+		if err != nil {
+			slog.Error("Error recording quota usage", "error", err)
+		}
+	}
+}
+
+func (s *QuotaStore) used(subject string, metric QuotaMetric, day string) int64 {
+	var used int64
+	err := s.db.QueryRow(`SELECT used FROM quota_usage WHERE subject = ? AND metric = ? AND day = ?`,
+		subject, string(metric), day).Scan(&used)
+	if err != nil && err != sql.ErrNoRows {
+		slog.Error("Error reading quota usage", "error", err)
+	}
+	return used
+}
+
+// pruneOldUsage deletes usage rows from previous days, at most once per day.
+func (s *QuotaStore) pruneOldUsage() {
+	day := today()
+
+	s.mu.Lock()
+	if s.prunedDay == day {
+		s.mu.Unlock()
+		return
+	}
+	s.prunedDay = day
+	s.mu.Unlock()
+
+	if _, err := s.db.Exec(`DELETE FROM quota_usage WHERE day < ?`, day); err != nil {
+		slog.Error("Error pruning old quota usage", "error", err)
+	}
+}
+
+func today() string {
+	return time.Now().Format("2006-01-02")
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test DB: %v", err)
+	}
+	// Each connection to :memory: is a separate database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestQuotaStore_UserLimit(t *testing.T) {
+	store := NewQuotaStore(newTestDB(t), map[QuotaMetric]QuotaLimits{
+		QuotaDownloads: {PerUser: 2},
+	})
+
+	for i := 0; i < 2; i++ {
+		if !store.Allow("user1", "group1", QuotaDownloads) {
+			t.Fatalf("download %d should be allowed", i)
+		}
+		store.Consume("user1", "group1", QuotaDownloads, 1)
+	}
+
+	if store.Allow("user1", "group1", QuotaDownloads) {
+		t.Error("user1 should have exhausted their quota")
+	}
+	if !store.Allow("user2", "group1", QuotaDownloads) {
+		t.Error("user2 should be unaffected by user1's usage")
+	}
+}
+
+func TestQuotaStore_GroupLimit(t *testing.T) {
+	store := NewQuotaStore(newTestDB(t), map[QuotaMetric]QuotaLimits{
+		QuotaDownloadBytes: {PerGroup: 100},
+	})
+
+	store.Consume("user1", "group1", QuotaDownloadBytes, 60)
+	store.Consume("user2", "group1", QuotaDownloadBytes, 60)
+
+	if store.Allow("user3", "group1", QuotaDownloadBytes) {
+		t.Error("group1 should have exhausted its quota")
+	}
+	if !store.Allow("user3", "group2", QuotaDownloadBytes) {
+		t.Error("group2 should be unaffected by group1's usage")
+	}
+	if !store.Allow("user1", "", QuotaDownloadBytes) {
+		t.Error("private chats should not be bound by group quotas")
+	}
+}
+
+func TestQuotaStore_GroupOverride(t *testing.T) {
+	store := NewQuotaStore(newTestDB(t), map[QuotaMetric]QuotaLimits{
+		QuotaMedia: {PerUser: 1},
+	})
+
+	store.Consume("user1", "group1", QuotaMedia, 1)
+	if store.Allow("user1", "group1", QuotaMedia) {
+		t.Fatal("default limit should apply before override")
+	}
+
+	if !store.SetLimits("group1", QuotaMedia, QuotaLimits{PerUser: 5}) {
+		t.Fatal("SetLimits failed")
+	}
+	if !store.Allow("user1", "group1", QuotaMedia) {
+		t.Error("raised group limit should allow more usage")
+	}
+
+	if !store.ResetLimits("group1") {
+		t.Fatal("ResetLimits failed")
+	}
+	if got := store.Limits("group1", QuotaMedia); got.PerUser != 1 {
+		t.Errorf("Limits after reset = %+v, want default PerUser 1", got)
+	}
+}
+
+func TestQuotaStore_ReserveIsAtomic(t *testing.T) {
+	store := NewQuotaStore(newTestDB(t), map[QuotaMetric]QuotaLimits{
+		QuotaDownloads: {PerGroup: 3},
+	})
+
+	var wg sync.WaitGroup
+	var reserved atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, ok := store.Reserve(fmt.Sprintf("user%d", i), "group1", QuotaDownloads, 1); ok {
+				reserved.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := reserved.Load(); got != 3 {
+		t.Errorf("%d concurrent reservations succeeded, want 3", got)
+	}
+}
+
+func TestQuotaStore_ReserveAndRefund(t *testing.T) {
+	store := NewQuotaStore(newTestDB(t), map[QuotaMetric]QuotaLimits{
+		QuotaDownloadBytes: {PerUser: 100, PerGroup: 150},
+	})
+
+	// A single amount larger than what is left does not fit, and records nothing.
+	if _, ok := store.Reserve("user1", "group1", QuotaDownloadBytes, 120); ok {
+		t.Fatal("120 bytes should not fit in a 100 byte user quota")
+	}
+	day, ok := store.Reserve("user1", "group1", QuotaDownloadBytes, 80)
+	if !ok {
+		t.Fatal("80 bytes should fit")
+	}
+	if _, ok := store.Reserve("user2", "group1", QuotaDownloadBytes, 80); ok {
+		t.Fatal("80 more bytes should not fit in the 150 byte group quota")
+	}
+
+	store.Refund("user1", "group1", QuotaDownloadBytes, 80, day)
+	if usage := store.Usage("user1", "group1", QuotaDownloadBytes); usage.UserUsed != 0 || usage.GroupUsed != 0 {
+		t.Errorf("usage after refund = %d/%d, want 0/0", usage.UserUsed, usage.GroupUsed)
+	}
+	if _, ok := store.Reserve("user2", "group1", QuotaDownloadBytes, 80); !ok {
+		t.Error("refunded bytes should be available again")
+	}
+
+	// Private chats are not bound by the group quota.
+	if _, ok := store.Reserve("user3", "", QuotaDownloadBytes, 100); !ok {
+		t.Error("private chat reservation within the user quota should fit")
+	}
+}
+
+func TestQuotaStore_RefundUsesReservationDay(t *testing.T) {
+	store := NewQuotaStore(newTestDB(t), map[QuotaMetric]QuotaLimits{
+		QuotaMedia: {PerUser: 5},
+	})
+
+	store.Consume("user1", "group1", QuotaMedia, 2)
+
+	// A reservation made before midnight must not be given back from today's usage.
+	store.Refund("user1", "group1", QuotaMedia, 1, "2000-01-01")
+	if usage := store.Usage("user1", "group1", QuotaMedia); usage.UserUsed != 2 || usage.GroupUsed != 2 {
+		t.Errorf("usage after refunding another day = %d/%d, want 2/2", usage.UserUsed, usage.GroupUsed)
+	}
+
+	day, ok := store.Reserve("user1", "group1", QuotaMedia, 1)
+	if !ok {
+		t.Fatal("reservation within the user quota should fit")
+	}
+	store.Refund("user1", "group1", QuotaMedia, 1, day)
+	if usage := store.Usage("user1", "group1", QuotaMedia); usage.UserUsed != 2 {
+		t.Errorf("usage after refunding the reservation = %d, want 2", usage.UserUsed)
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// ScheduleAction is a transition of a group's night mode.
+type ScheduleAction string
+
+const (
+	ScheduleClose ScheduleAction = "close" // only admins can send messages
+	ScheduleOpen  ScheduleAction = "open"  // everyone can send messages
+)
+
+// GroupSchedule is a recurring daily close/open schedule for a group.
+type GroupSchedule struct {
+	GroupJID string
+	CloseAt  int // minutes after local midnight
+	OpenAt   int
+	TimeZone string // IANA name, e.g. Asia/Jakarta
+	Announce bool   // post a message at each transition
+}
+
+// Transition returns the schedule's last transition in (from, to], if any.
+// When both happen in the window, the later one wins.
+func (s GroupSchedule) Transition(from, to time.Time) (ScheduleAction, bool) {
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return "", false
+	}
+
+	var action ScheduleAction
+	var latest time.Time
+	for _, t := range []struct {
+		action ScheduleAction
+		minute int
+	}{{ScheduleClose, s.CloseAt}, {ScheduleOpen, s.OpenAt}} {
+		at, ok := lastOccurrence(t.minute, to.In(loc))
+		if ok && at.After(from) && at.After(latest) {
+			action, latest = t.action, at
+		}
+	}
+	return action, action != ""
+}
+
+// lastOccurrence returns the most recent time at or before now that the local clock showed minute.
+func lastOccurrence(minute int, now time.Time) (time.Time, bool) {
+	y, m, d := now.Date()
+	for back := 0; back <= 1; back++ {
+		at := time.Date(y, m, d-back, minute/60, minute%60, 0, 0, now.Location())
+		if !at.After(now) {
+			return at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// FormatClock formats minutes after midnight as HH:MM.
+func FormatClock(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// ParseClock parses HH:MM (or H.MM) into minutes after midnight.
+func ParseClock(text string) (int, bool) {
+	var h, m int
+	if n, _ := fmt.Sscanf(text, "%d:%d", &h, &m); n != 2 {
+		if n, _ := fmt.Sscanf(text, "%d.%d", &h, &m); n != 2 {
+			return 0, false
+		}
+	}
+	if h < 0 || h > 23 || m < 0 || m > 59 || len(text) > 5 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+// ScheduleStore manages per-group night mode schedules.
+type ScheduleStore struct {
+	db *sql.DB
+}
+
+// NewScheduleStore creates a new store and ensures the table exists.
+func NewScheduleStore(db *sql.DB) *ScheduleStore {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS group_schedules (
+			group_jid TEXT PRIMARY KEY,
+			close_at INTEGER NOT NULL,
+			open_at INTEGER NOT NULL,
+			timezone TEXT NOT NULL,
+			announce INTEGER NOT NULL DEFAULT 1
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create group_schedules table", "error", err)
+		os.Exit(1)
+	}
+	return &ScheduleStore{db: db}
+}
+
+// Get returns a group's schedule, if it has one.
+func (s *ScheduleStore) Get(groupJID string) (GroupSchedule, bool) {
+	sched := GroupSchedule{GroupJID: groupJID}
+	err := s.db.QueryRow(`SELECT close_at, open_at, timezone, announce FROM group_schedules WHERE group_jid = ?`, groupJID).
+		Scan(&sched.CloseAt, &sched.OpenAt, &sched.TimeZone, &sched.Announce)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			slog.Error("failed to read group schedule", "error", err)
+		}
+		return GroupSchedule{}, false
+	}
+	return sched, true
+}
+
+// Set creates or replaces a group's schedule.
+func (s *ScheduleStore) Set(sched GroupSchedule) bool {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO group_schedules (group_jid, close_at, open_at, timezone, announce) VALUES (?, ?, ?, ?, ?)`,
+		sched.GroupJID, sched.CloseAt, sched.OpenAt, sched.TimeZone, sched.Announce)
+	if err != nil {
+		slog.Error("failed to save group schedule", "error", err)
+		return false
+	}
+	return true
+}
+
+// Remove deletes a group's schedule. Returns false if it had none.
+func (s *ScheduleStore) Remove(groupJID string) bool {
+	result, err := s.db.Exec(`DELETE FROM group_schedules WHERE group_jid = ?`, groupJID)
+	if err != nil {
+		slog.Error("failed to remove group schedule", "error", err)
+		return false
+	}
+	n, _ := result.RowsAffected()
+	return n > 0
+}
+
+// List returns all schedules.
+func (s *ScheduleStore) List() []GroupSchedule {
+	rows, err := s.db.Query(`SELECT group_jid, close_at, open_at, timezone, announce FROM group_schedules`)
+	if err != nil {
+		slog.Error("failed to list group schedules", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var list []GroupSchedule
+	for rows.Next() {
+		var sched GroupSchedule
+		if err := rows.Scan(&sched.GroupJID, &sched.CloseAt, &sched.OpenAt, &sched.TimeZone, &sched.Announce); err != nil {
+			slog.Error("failed to scan group schedule", "error", err)
+			continue
+		}
+		list = append(list, sched)
+	}
+	return list
+}
+
+// StartScheduler runs a background goroutine that checks the schedules every interval
+// and calls onTransition for each close or open that became due since the previous check.
+// Transitions missed while the bot was offline are not replayed.
+func (s *ScheduleStore) StartScheduler(ctx context.Context, interval time.Duration, onTransition func(GroupSchedule, ScheduleAction)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				for _, sched := range s.List() {
+					if action, ok := sched.Transition(last, now); ok {
+						onTransition(sched, action)
+					}
+				}
+				last = now
+			}
+		}
+	}()
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupSchedule_Transition(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip("time zone data not available:", err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, jakarta)
+	}
+	night := GroupSchedule{CloseAt: 22 * 60, OpenAt: 6 * 60, TimeZone: "Asia/Jakarta"}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     ScheduleAction
+		wantOK   bool
+	}{
+		{"close is due", at(1, 21, 59), at(1, 22, 0), ScheduleClose, true},
+		{"open after midnight", at(2, 5, 59), at(2, 6, 0), ScheduleOpen, true},
+		{"nothing in between", at(1, 22, 0), at(1, 22, 1), "", false},
+		{"window start is exclusive", at(1, 22, 0), at(1, 23, 0), "", false},
+		{"later transition wins", at(1, 21, 0), at(2, 7, 0), ScheduleOpen, true},
+		{"checked from another zone", at(1, 21, 59).UTC(), at(1, 22, 0).UTC(), ScheduleClose, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := night.Transition(tt.from, tt.to)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Transition() = %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int
+		wantOK bool
+	}{
+		{"22:00", 22 * 60, true},
+		{"6:30", 6*60 + 30, true},
+		{"06.05", 6*60 + 5, true},
+		{"24:00", 0, false},
+		{"12:60", 0, false},
+		{"noon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseClock(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseClock(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+	if got := FormatClock(6*60 + 5); got != "06:05" {
+		t.Errorf("FormatClock() = %q, want 06:05", got)
+	}
+}
+
+func TestScheduleStore(t *testing.T) {
+	store := NewScheduleStore(newTestDB(t))
+
+	sched := GroupSchedule{GroupJID: "g1@g.us", CloseAt: 22 * 60, OpenAt: 6 * 60, TimeZone: "Asia/Jakarta", Announce: true}
+	if !store.Set(sched) {
+		t.Fatal("Set failed")
+	}
+	if got, ok := store.Get("g1@g.us"); !ok || got != sched {
+		t.Errorf("Get() = %+v, %v; want %+v", got, ok, sched)
+	}
+
+	sched.Announce = false
+	store.Set(sched)
+	if got := store.List(); len(got) != 1 || got[0].Announce {
+		t.Errorf("List() = %+v, want the replaced schedule only", got)
+	}
+
+	if !store.Remove("g1@g.us") || store.Remove("g1@g.us") {
+		t.Error("Remove should report the schedule exactly once")
+	}
+	if _, ok := store.Get("g1@g.us"); ok {
+		t.Error("removed schedule should be gone")
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// SlowModeStore manages the persistent per-group slow mode interval.
+// The interval is read for every group message, so it is cached in memory.
+type SlowModeStore struct {
+	db *sql.DB
+
+	mu    sync.RWMutex
+	cache map[string]time.Duration
+}
+
+// NewSlowModeStore creates a new store and ensures the table exists.
+func NewSlowModeStore(db *sql.DB) *SlowModeStore {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS slowmode_settings (
+			group_jid TEXT PRIMARY KEY,
+			interval_sec INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create slowmode_settings table", "error", err)
+		os.Exit(1)
+	}
+
+	return &SlowModeStore{db: db, cache: make(map[string]time.Duration)}
+}
+
+// Interval returns how long members of a group must wait between messages, 0 if slow mode is off.
+func (s *SlowModeStore) Interval(groupJID string) time.Duration {
+	s.mu.RLock()
+	interval, ok := s.cache[groupJID]
+	s.mu.RUnlock()
+	if ok {
+		return interval
+	}
+
+	var seconds int
+	err := s.db.QueryRow(`SELECT interval_sec FROM slowmode_settings WHERE group_jid = ?`, groupJID).Scan(&seconds)
+	switch {
+	case err == nil:
+		interval = time.Duration(seconds) * time.Second
+	case err == sql.ErrNoRows:
+	default:
+		// Do not cache on errors so the next message retries.
+		slog.Error("failed to load slow mode interval", "error", err)
+		return 0
+	}
+
+	s.mu.Lock()
+	s.cache[groupJID] = interval
+	s.mu.Unlock()
+	return interval
+}
+
+// SetInterval turns slow mode on with the given interval, or off when it is 0.
+func (s *SlowModeStore) SetInterval(groupJID string, interval time.Duration) bool {
+	var err error
+	if interval <= 0 {
+		interval = 0
+		_, err = s.db.Exec(`DELETE FROM slowmode_settings WHERE group_jid = ?`, groupJID)
+	} else {
+		_, err = s.db.Exec(`
+			INSERT INTO slowmode_settings (group_jid, interval_sec) VALUES (?, ?)
+			ON CONFLICT (group_jid) DO UPDATE SET interval_sec = excluded.interval_sec
+		`, groupJID, int(interval/time.Second))
+	}
+	if err != nil {
+		slog.Error("failed to set slow mode interval", "error", err)
+		return false
+	}
+
+	s.mu.Lock()
+	s.cache[groupJID] = interval
+	s.mu.Unlock()
+	return true
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlowModeStore_Interval(t *testing.T) {
+	db := newTestDB(t)
+	store := NewSlowModeStore(db)
+
+	if got := store.Interval("g1@g.us"); got != 0 {
+		t.Errorf("default interval = %v, want 0", got)
+	}
+
+	if !store.SetInterval("g1@g.us", 30*time.Second) {
+		t.Fatal("SetInterval failed")
+	}
+	// A fresh store reads the interval back from the database.
+	if got := NewSlowModeStore(db).Interval("g1@g.us"); got != 30*time.Second {
+		t.Errorf("Interval() = %v, want 30s", got)
+	}
+
+	if !store.SetInterval("g1@g.us", 0) {
+		t.Fatal("SetInterval(0) failed")
+	}
+	if got := NewSlowModeStore(db).Interval("g1@g.us"); got != 0 {
+		t.Errorf("Interval() after off = %v, want 0", got)
+	}
+}
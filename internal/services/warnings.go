@@ -0,0 +1,121 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Warning is a strike recorded against a group member.
+type Warning struct {
+	GroupJID  string
+	JID       string
+	Issuer    string // JID of the admin, or the bot for automatic moderators
+	Reason    string
+	CreatedAt time.Time
+}
+
+// WarningStore manages persistent per-group warnings.
+type WarningStore struct {
+	db *sql.DB
+}
+
+// NewWarningStore creates a new store and ensures the table exists.
+func NewWarningStore(db *sql.DB) *WarningStore {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS warnings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			group_jid TEXT NOT NULL,
+			jid TEXT NOT NULL,
+			issuer TEXT NOT NULL DEFAULT '',
+			reason TEXT NOT NULL DEFAULT '',
+			created_at INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create warnings table", "error", err)
+		os.Exit(1)
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_warnings_member ON warnings (group_jid, jid)`)
+	if err != nil {
+		slog.Error("Failed to create warnings index", "error", err)
+		os.Exit(1)
+	}
+
+	return &WarningStore{db: db}
+}
+
+// MigrateLIDKeys moves warnings stored under a LID to the member's phone number once the mapping is known.
+// Failures are logged and retried on the next start.
+func (s *WarningStore) MigrateLIDKeys(identity *Identity) {
+	migrated, err := migrateLIDKeys(s.db, "warnings", identity)
+	if err != nil {
+		slog.Error("Failed to migrate LID warning keys", "error", err)
+		return
+	}
+	if migrated > 0 {
+		slog.Info("Migrated LID warning keys to phone numbers", "users", migrated)
+	}
+}
+
+// Add records a warning. A zero CreatedAt is set to now.
+// Returns the member's warning count in the group including the new one, or 0 on error.
+func (s *WarningStore) Add(w Warning) int {
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now()
+	}
+
+	_, err := s.db.Exec(`INSERT INTO warnings (group_jid, jid, issuer, reason, created_at) VALUES (?, ?, ?, ?, ?)`,
+		w.GroupJID, w.JID, w.Issuer, w.Reason, w.CreatedAt.Unix())
+	if err != nil {
+		slog.Error("failed to add warning", "error", err)
+		return 0
+	}
+	return s.Count(w.GroupJID, w.JID)
+}
+
+// Count returns how many warnings a member has in a group.
+func (s *WarningStore) Count(groupJID, jid string) int {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM warnings WHERE group_jid = ? AND jid = ?`, groupJID, jid).Scan(&count); err != nil {
+		slog.Error("failed to count warnings", "error", err)
+		return 0
+	}
+	return count
+}
+
+// List returns a member's warnings in a group, oldest first.
+func (s *WarningStore) List(groupJID, jid string) []Warning {
+	rows, err := s.db.Query(`SELECT issuer, reason, created_at FROM warnings WHERE group_jid = ? AND jid = ? ORDER BY id`, groupJID, jid)
+	if err != nil {
+		slog.Error("failed to list warnings", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var list []Warning
+	for rows.Next() {
+		w := Warning{GroupJID: groupJID, JID: jid}
+		var createdAt int64
+		if err := rows.Scan(&w.Issuer, &w.Reason, &createdAt); err != nil {
+			slog.Error("failed to scan warning", "error", err)
+			continue
+		}
+		w.CreatedAt = time.Unix(createdAt, 0)
+		list = append(list, w)
+	}
+	return list
+}
+
+// Reset removes all of a member's warnings in a group and returns how many were removed.
+func (s *WarningStore) Reset(groupJID, jid string) int {
+	result, err := s.db.Exec(`DELETE FROM warnings WHERE group_jid = ? AND jid = ?`, groupJID, jid)
+	if err != nil {
+		slog.Error("failed to reset warnings", "error", err)
+		return 0
+	}
+	n, _ := result.RowsAffected()
+	return int(n)
+}
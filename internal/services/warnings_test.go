@@ -0,0 +1,30 @@
+package services
+
+import "testing"
+
+func TestWarningStore(t *testing.T) {
+	store := NewWarningStore(newTestDB(t))
+
+	if got := store.Add(Warning{GroupJID: "g1@g.us", JID: "user1@s.whatsapp.net", Issuer: "admin@s.whatsapp.net", Reason: "spam"}); got != 1 {
+		t.Errorf("first Add() = %d, want 1", got)
+	}
+	if got := store.Add(Warning{GroupJID: "g1@g.us", JID: "user1@s.whatsapp.net", Reason: "link"}); got != 2 {
+		t.Errorf("second Add() = %d, want 2", got)
+	}
+	store.Add(Warning{GroupJID: "g2@g.us", JID: "user1@s.whatsapp.net"})
+
+	list := store.List("g1@g.us", "user1@s.whatsapp.net")
+	if len(list) != 2 || list[0].Reason != "spam" || list[0].Issuer != "admin@s.whatsapp.net" || list[1].Reason != "link" {
+		t.Errorf("List() = %+v, want spam then link", list)
+	}
+
+	if got := store.Reset("g1@g.us", "user1@s.whatsapp.net"); got != 2 {
+		t.Errorf("Reset() = %d, want 2", got)
+	}
+	if got := store.Count("g1@g.us", "user1@s.whatsapp.net"); got != 0 {
+		t.Errorf("Count() after Reset = %d, want 0", got)
+	}
+	if got := store.Count("g2@g.us", "user1@s.whatsapp.net"); got != 1 {
+		t.Errorf("warnings in other groups should be kept, got %d", got)
+	}
+}
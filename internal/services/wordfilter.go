@@ -0,0 +1,111 @@
+package services
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+
+	"chisa_bot/pkg/wordfilter"
+)
+
+// WordFilterStore manages the persistent per-group word filter rules.
+type WordFilterStore struct {
+	db *sql.DB
+}
+
+// NewWordFilterStore creates a new store and ensures the tables exist.
+func NewWordFilterStore(db *sql.DB) *WordFilterStore {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS word_filters (
+			group_jid TEXT NOT NULL,
+			pattern TEXT NOT NULL,
+			is_regex INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (group_jid, pattern, is_regex)
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create word_filters table", "error", err)
+		os.Exit(1)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS word_filter_settings (
+			group_jid TEXT PRIMARY KEY,
+			warn INTEGER NOT NULL DEFAULT 0
+		)
+	`)
+	if err != nil {
+		slog.Error("Failed to create word_filter_settings table", "error", err)
+		os.Exit(1)
+	}
+
+	return &WordFilterStore{db: db}
+}
+
+// Rules returns a group's filter rules in the order they were added.
+func (s *WordFilterStore) Rules(groupJID string) []wordfilter.Rule {
+	rows, err := s.db.Query(`SELECT pattern, is_regex FROM word_filters WHERE group_jid = ? ORDER BY rowid`, groupJID)
+	if err != nil {
+		slog.Error("failed to load word filters", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var rules []wordfilter.Rule
+	for rows.Next() {
+		var rule wordfilter.Rule
+		if err := rows.Scan(&rule.Pattern, &rule.Regex); err != nil {
+			slog.Error("failed to scan word filter", "error", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// Add stores a rule for a group. Returns true if newly added, false if it already existed.
+func (s *WordFilterStore) Add(groupJID string, rule wordfilter.Rule) bool {
+	result, err := s.db.Exec(`INSERT OR IGNORE INTO word_filters (group_jid, pattern, is_regex) VALUES (?, ?, ?)`,
+		groupJID, rule.Pattern, rule.Regex)
+	if err != nil {
+		slog.Error("failed to add word filter", "error", err)
+		return false
+	}
+	n, _ := result.RowsAffected()
+	return n > 0
+}
+
+// Remove deletes a rule from a group. Returns false if it was not in the list.
+func (s *WordFilterStore) Remove(groupJID string, rule wordfilter.Rule) bool {
+	result, err := s.db.Exec(`DELETE FROM word_filters WHERE group_jid = ? AND pattern = ? AND is_regex = ?`,
+		groupJID, rule.Pattern, rule.Regex)
+	if err != nil {
+		slog.Error("failed to remove word filter", "error", err)
+		return false
+	}
+	n, _ := result.RowsAffected()
+	return n > 0
+}
+
+// Warn reports whether filtered messages also earn the sender a warning in a group.
+func (s *WordFilterStore) Warn(groupJID string) bool {
+	var warn bool
+	err := s.db.QueryRow(`SELECT warn FROM word_filter_settings WHERE group_jid = ?`, groupJID).Scan(&warn)
+	if err != nil && err != sql.ErrNoRows {
+		slog.Error("failed to load word filter settings", "error", err)
+	}
+	return warn
+}
+
+// SetWarn changes whether filtered messages earn the sender a warning in a group.
+func (s *WordFilterStore) SetWarn(groupJID string, warn bool) bool {
+	_, err := s.db.Exec(`
+		INSERT INTO word_filter_settings (group_jid, warn) VALUES (?, ?)
+		ON CONFLICT (group_jid) DO UPDATE SET warn = excluded.warn
+	`, groupJID, warn)
+	if err != nil {
+		slog.Error("failed to set word filter warnings", "error", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"chisa_bot/pkg/wordfilter"
+)
+
+func TestWordFilterStore(t *testing.T) {
+	store := NewWordFilterStore(newTestDB(t))
+
+	phrase := wordfilter.Rule{Pattern: "slot gacor"}
+	regex := wordfilter.Rule{Pattern: `bit\.ly/\w+`, Regex: true}
+
+	if !store.Add("g1@g.us", phrase) || !store.Add("g1@g.us", regex) {
+		t.Fatal("Add should report new rules")
+	}
+	if store.Add("g1@g.us", phrase) {
+		t.Error("Add should report an existing rule")
+	}
+
+	if got := store.Rules("g1@g.us"); !reflect.DeepEqual(got, []wordfilter.Rule{phrase, regex}) {
+		t.Errorf("Rules() = %+v, want phrase then regex", got)
+	}
+	if got := store.Rules("g2@g.us"); len(got) != 0 {
+		t.Errorf("other group Rules() = %+v, want none", got)
+	}
+
+	if store.Remove("g1@g.us", wordfilter.Rule{Pattern: "slot gacor", Regex: true}) {
+		t.Error("Remove should distinguish phrases from regexes")
+	}
+	if !store.Remove("g1@g.us", phrase) {
+		t.Error("Remove should delete the phrase")
+	}
+
+	if store.Warn("g1@g.us") {
+		t.Error("warnings should be off by default")
+	}
+	store.SetWarn("g1@g.us", true)
+	if !store.Warn("g1@g.us") {
+		t.Error("SetWarn(true) should enable warnings")
+	}
+}
@@ -0,0 +1,94 @@
+// Package imagehash computes perceptual image hashes that survive re-encoding and resizing.
+package imagehash
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for image.Decode
+	_ "image/jpeg"
+	_ "image/png"
+	"math/bits"
+	"strconv"
+)
+
+// DHash computes the 64-bit difference hash of img.
+// The image is reduced to a 9x8 grayscale grid by averaging, and each bit records
+// whether a cell is brighter than its right neighbour.
+func DHash(img image.Image) uint64 {
+	const w, h = 9, 8
+	var grid [h][w]float64
+
+	b := img.Bounds()
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := b.Min.Y + (y+1)*b.Dy()/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := b.Min.X + (x+1)*b.Dx()/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			grid[y][x] = averageLuma(img, x0, y0, x1, y1)
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w-1; x++ {
+			hash <<= 1
+			if grid[y][x] > grid[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// averageLuma returns the mean luminance of the pixels in [x0,x1) x [y0,y1).
+// Transparent pixels count as white so stickers hash like they look on a light background.
+func averageLuma(img image.Image, x0, y0, x1, y1 int) float64 {
+	var sum float64
+	var n int
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			// Composite over white: color + (1 - alpha) * white.
+			white := float64(0xffff - a)
+			luma := 0.299*(float64(r)+white) + 0.587*(float64(g)+white) + 0.114*(float64(b)+white)
+			sum += luma
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// DHashBytes decodes a JPEG, PNG or GIF image and returns its difference hash.
+func DHashBytes(data []byte) (uint64, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return DHash(img), nil
+}
+
+// Distance returns the Hamming distance between two hashes: 0 for identical images, up to 64.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Format encodes a hash as 16 hex digits.
+func Format(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// Parse decodes a hash produced by Format.
+func Parse(s string) (uint64, error) {
+	return strconv.ParseUint(s, 16, 64)
+}
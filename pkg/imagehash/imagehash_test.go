@@ -0,0 +1,91 @@
+package imagehash
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// gradient draws a test image: a horizontal gradient with a dark square whose position is set by offset.
+// A reversed gradient darkens towards the right instead of the left.
+func gradient(w, h, offset int, reversed bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(x * 255 / w)
+			if reversed {
+				v = 255 - v
+			}
+			if x > offset && x < offset+w/4 && y > h/4 && y < h/2 {
+				v = 20
+			}
+			img.Set(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+	return img
+}
+
+func TestDHash_SurvivesReencodingAndResizing(t *testing.T) {
+	original := DHash(gradient(320, 240, 40, false))
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, gradient(160, 120, 20, false), &jpeg.Options{Quality: 30}); err != nil {
+		t.Fatal(err)
+	}
+	reencoded, err := DHashBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := Distance(original, reencoded); d > 6 {
+		t.Errorf("distance between resized low-quality copy and original = %d, want <= 6", d)
+	}
+
+	different := DHash(gradient(320, 240, 200, true))
+	if d := Distance(original, different); d < 20 {
+		t.Errorf("distance between different images = %d, want >= 20", d)
+	}
+}
+
+func TestDHash_TransparentAsWhite(t *testing.T) {
+	transparent := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	white := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			white.Set(x, y, color.White)
+			if x < 16 {
+				transparent.Set(x, y, color.Black)
+				white.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, transparent); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := DHashBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := Distance(hash, DHash(white)); d != 0 {
+		t.Errorf("transparent sticker should hash like white background, distance = %d", d)
+	}
+}
+
+func TestFormatParse(t *testing.T) {
+	const hash = uint64(0x0123456789abcdef)
+	s := Format(hash)
+	if s != "0123456789abcdef" {
+		t.Errorf("Format() = %q", s)
+	}
+	if got, err := Parse(s); err != nil || got != hash {
+		t.Errorf("Parse(%q) = %x, %v", s, got, err)
+	}
+	if _, err := DHashBytes([]byte("not an image")); err == nil {
+		t.Error("DHashBytes should reject invalid data")
+	}
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// floodMessage is a message recorded by the flood detector.
+type floodMessage struct {
+	id string
+	at time.Time
+}
+
+// floodWindow holds a user's recent messages in one chat.
+type floodWindow struct {
+	messages []floodMessage
+	window   time.Duration
+}
+
+// FloodDetector tracks messages per user and chat in a sliding window.
+// Unlike Limiter it counts every message, not only commands, and remembers
+// message IDs so a detected flood can be revoked as a whole.
+type FloodDetector struct {
+	mu      sync.Mutex
+	windows map[string]*floodWindow // "chat|user" -> recent messages
+
+	lastCleanup time.Time
+}
+
+// NewFloodDetector creates a new FloodDetector.
+func NewFloodDetector() *FloodDetector {
+	return &FloodDetector{
+		windows:     make(map[string]*floodWindow),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Record adds a message from userJID in chatJID and checks it against the threshold.
+// When the user has sent more than limit messages within window, Record returns the IDs of
+// every message in the window (oldest first) and true, and forgets them so each flood
+// is reported once. A limit below 1 disables detection.
+func (d *FloodDetector) Record(userJID, chatJID, messageID string, limit int, window time.Duration) ([]string, bool) {
+	if limit < 1 || window <= 0 {
+		return nil, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+
+	// Periodic cleanup every 5 minutes to free memory.
+	if now.Sub(d.lastCleanup) > 5*time.Minute {
+		d.cleanup(now)
+		d.lastCleanup = now
+	}
+
+	key := chatJID + "|" + userJID
+	w, ok := d.windows[key]
+	if !ok {
+		w = &floodWindow{}
+		d.windows[key] = w
+	}
+	w.window = window
+
+	// Remove messages outside the window.
+	cutoff := now.Add(-window)
+	start := 0
+	for start < len(w.messages) && w.messages[start].at.Before(cutoff) {
+		start++
+	}
+	w.messages = append(w.messages[start:], floodMessage{id: messageID, at: now})
+
+	if len(w.messages) <= limit {
+		return nil, false
+	}
+
+	ids := make([]string, len(w.messages))
+	for i, m := range w.messages {
+		ids[i] = m.id
+	}
+	delete(d.windows, key)
+	return ids, true
+}
+
+// cleanup removes windows without recent messages to prevent memory leaks.
+func (d *FloodDetector) cleanup(now time.Time) {
+	for k, w := range d.windows {
+		if len(w.messages) == 0 || w.messages[len(w.messages)-1].at.Before(now.Add(-w.window)) {
+			delete(d.windows, k)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFloodDetector_TriggersAboveLimit(t *testing.T) {
+	d := NewFloodDetector()
+
+	for i := 0; i < 3; i++ {
+		if _, flood := d.Record("user1", "chat1", fmt.Sprintf("m%d", i), 3, time.Minute); flood {
+			t.Fatalf("message %d within limit should not be a flood", i)
+		}
+	}
+
+	ids, flood := d.Record("user1", "chat1", "m3", 3, time.Minute)
+	if !flood {
+		t.Fatal("message above limit should be a flood")
+	}
+	if want := []string{"m0", "m1", "m2", "m3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("flood ids = %v, want %v", ids, want)
+	}
+
+	// The flood is reported once; counting starts over.
+	if _, flood := d.Record("user1", "chat1", "m4", 3, time.Minute); flood {
+		t.Error("message after a reported flood should start a new window")
+	}
+}
+
+func TestFloodDetector_SeparatesUsersAndChats(t *testing.T) {
+	d := NewFloodDetector()
+
+	d.Record("user1", "chat1", "a", 1, time.Minute)
+	if _, flood := d.Record("user2", "chat1", "b", 1, time.Minute); flood {
+		t.Error("other users should have their own window")
+	}
+	if _, flood := d.Record("user1", "chat2", "c", 1, time.Minute); flood {
+		t.Error("other chats should have their own window")
+	}
+	if _, flood := d.Record("user1", "chat1", "d", 1, time.Minute); !flood {
+		t.Error("second message in the same chat should exceed a limit of 1")
+	}
+}
+
+func TestFloodDetector_WindowSlides(t *testing.T) {
+	d := NewFloodDetector()
+
+	d.Record("user1", "chat1", "a", 1, 50*time.Millisecond)
+	time.Sleep(80 * time.Millisecond)
+
+	if _, flood := d.Record("user1", "chat1", "b", 1, 50*time.Millisecond); flood {
+		t.Error("messages outside the window should not count")
+	}
+}
+
+func TestFloodDetector_Disabled(t *testing.T) {
+	d := NewFloodDetector()
+
+	for i := 0; i < 10; i++ {
+		if _, flood := d.Record("user1", "chat1", "m", 0, time.Minute); flood {
+			t.Fatal("limit 0 should disable detection")
+		}
+	}
+}
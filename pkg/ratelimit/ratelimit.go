@@ -1,53 +1,160 @@
 package ratelimit
 
 import (
+	"sort"
 	"sync"
 	"time"
 )
 
-// Limiter provides per-user and per-chat rate limiting.
+// Role identifies the privilege level of a command sender.
+// Each role can be given its own cost multiplier.
+type Role int
+
+const (
+	RoleMember     Role = iota // regular group member or private chat user
+	RoleGroupAdmin             // admin of the group the command was sent in
+	RoleException              // listed in AdminExceptions
+	RoleOwner                  // listed in OwnerJIDs
+)
+
+// bucket is a per-user token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// violationWindow counts rejected commands within a window.
+type violationWindow struct {
+	count int
+	start time.Time
+}
+
+// Penalty describes a user currently in the penalty box.
+type Penalty struct {
+	UserJID string
+	Until   time.Time
+}
+
+// Limiter provides per-user token-bucket and per-chat sliding-window rate limiting.
 type Limiter struct {
 	mu sync.Mutex
 
-	// Per-user: tracks last command time per user JID.
-	userLast map[string]time.Time
-	userCooldown time.Duration
+	// Per-user: token buckets keyed by user JID.
+	buckets     map[string]*bucket
+	capacity    float64
+	refillEvery time.Duration // time needed to regain a single token
+
+	// Per-role cost multipliers. A multiplier <= 0 exempts the role entirely.
+	multipliers map[Role]float64
 
 	// Per-chat: tracks command timestamps within the sliding window.
 	chatCmds   map[string][]time.Time
 	chatLimit  int
 	chatWindow time.Duration
 
+	// Escalation: repeated violations within penaltyWindow put a user in the penalty box.
+	userViolations   map[string]*violationWindow
+	chatViolations   map[string]*violationWindow
+	penalties        map[string]time.Time // user JID -> release time
+	penaltyThreshold int                  // 0 disables the penalty box
+	penaltyWindow    time.Duration
+	penaltyDuration  time.Duration
+
 	// Cleanup interval.
 	lastCleanup time.Time
 }
 
 // New creates a new Limiter.
-// userCooldown: minimum time between commands for a single user.
+// capacity: max tokens a user can hold (burst size).
+// refillEvery: time needed to regain one token; 0 disables per-user limiting.
 // chatLimit: max commands per chatWindow per chat.
-func New(userCooldown time.Duration, chatLimit int, chatWindow time.Duration) *Limiter {
+func New(capacity int, refillEvery time.Duration, chatLimit int, chatWindow time.Duration) *Limiter {
+	if capacity < 1 {
+		capacity = 1
+	}
 	return &Limiter{
-		userLast:     make(map[string]time.Time),
-		userCooldown: userCooldown,
-		chatCmds:     make(map[string][]time.Time),
-		chatLimit:    chatLimit,
-		chatWindow:   chatWindow,
-		lastCleanup:  time.Now(),
+		buckets:     make(map[string]*bucket),
+		capacity:    float64(capacity),
+		refillEvery: refillEvery,
+		multipliers: make(map[Role]float64),
+		chatCmds:    make(map[string][]time.Time),
+		chatLimit:   chatLimit,
+		chatWindow:  chatWindow,
+
+		userViolations: make(map[string]*violationWindow),
+		chatViolations: make(map[string]*violationWindow),
+		penalties:      make(map[string]time.Time),
+		penaltyWindow:  time.Minute,
+
+		lastCleanup: time.Now(),
 	}
 }
 
+// SetPenalty configures escalation for repeat offenders.
+// A user rejected threshold times within window is blocked from all commands for duration.
+// A threshold of 0 disables the penalty box.
+func (l *Limiter) SetPenalty(threshold int, window, duration time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.penaltyThreshold = threshold
+	l.penaltyWindow = window
+	l.penaltyDuration = duration
+}
+
+// Penalties returns the users currently in the penalty box, soonest release first.
+func (l *Limiter) Penalties() []Penalty {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	var list []Penalty
+	for user, until := range l.penalties {
+		if now.Before(until) {
+			list = append(list, Penalty{UserJID: user, Until: until})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Until.Before(list[j].Until) })
+	return list
+}
+
+// ClearPenalty releases a user from the penalty box and forgets their violations.
+// Returns true if the user was penalized.
+func (l *Limiter) ClearPenalty(userJID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until, ok := l.penalties[userJID]
+	delete(l.penalties, userJID)
+	delete(l.userViolations, userJID)
+	return ok && time.Now().Before(until)
+}
+
+// SetMultiplier sets the cost multiplier for a role.
+// Use 0 to exempt the role from rate limiting entirely.
+func (l *Limiter) SetMultiplier(role Role, multiplier float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.multipliers[role] = multiplier
+}
+
 // Result describes why a request was denied.
 type Result int
 
 const (
 	Allowed       Result = iota
-	UserCooldown         // user is sending too fast
-	ChatRateLimit        // chat has too many commands this minute
+	UserCooldown         // user does not have enough tokens left; first violation, warn once
+	ChatRateLimit        // chat has too many commands this window; first violation, warn once
+	Ignored              // repeated violation within the window; drop silently
+	PenaltyStart         // user just entered the penalty box; announce once
+	Penalized            // user is in the penalty box; drop silently
 )
 
-// Check tests whether a command from userJID in chatJID is allowed.
-// Returns Allowed if OK, or the reason it was denied.
-func (l *Limiter) Check(userJID, chatJID string) Result {
+// Check tests whether a command costing cost tokens from userJID in chatJID is allowed.
+// Returns Allowed if OK, or the reason it was denied together with how long
+// the caller should wait before the same command would be allowed.
+// Only UserCooldown, ChatRateLimit and PenaltyStart warrant a reply; the other
+// denials are meant to be ignored so a spammer cannot make the bot spam back.
+func (l *Limiter) Check(userJID, chatJID string, cost float64, role Role) (Result, time.Duration) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -59,11 +166,44 @@ func (l *Limiter) Check(userJID, chatJID string) Result {
 		l.lastCleanup = now
 	}
 
-	// 1. Per-user cooldown check.
-	if last, ok := l.userLast[userJID]; ok {
-		if now.Sub(last) < l.userCooldown {
-			return UserCooldown
+	multiplier, ok := l.multipliers[role]
+	if !ok {
+		multiplier = 1
+	}
+	if multiplier <= 0 {
+		return Allowed, 0
+	}
+
+	// 0. Penalty box check.
+	if until, ok := l.penalties[userJID]; ok {
+		if now.Before(until) {
+			return Penalized, until.Sub(now)
 		}
+		delete(l.penalties, userJID)
+	}
+
+	cost *= multiplier
+	if cost > l.capacity {
+		// A command must always be affordable with a full bucket.
+		cost = l.capacity
+	}
+
+	// 1. Per-user token bucket check.
+	b := l.refill(userJID, now)
+	if b != nil && b.tokens < cost {
+		missing := cost - b.tokens
+		retryAfter := time.Duration(missing * float64(l.refillEvery))
+
+		count := l.recordViolation(l.userViolations, userJID, now)
+		if l.penaltyThreshold > 0 && count >= l.penaltyThreshold {
+			l.penalties[userJID] = now.Add(l.penaltyDuration)
+			delete(l.userViolations, userJID)
+			return PenaltyStart, l.penaltyDuration
+		}
+		if count > 1 {
+			return Ignored, retryAfter
+		}
+		return UserCooldown, retryAfter
 	}
 
 	// 2. Per-chat rate limit check (sliding window).
@@ -80,22 +220,119 @@ func (l *Limiter) Check(userJID, chatJID string) Result {
 
 	if len(cmds) >= l.chatLimit {
 		l.chatCmds[chatKey] = cmds
-		return ChatRateLimit
+		retryAfter := cmds[0].Add(l.chatWindow).Sub(now)
+		if l.recordViolation(l.chatViolations, chatKey, now) > 1 {
+			return Ignored, retryAfter
+		}
+		return ChatRateLimit, retryAfter
 	}
 
-	// Allowed — record this command.
-	l.userLast[userJID] = now
+	// Allowed — charge the user and record this command.
+	if b != nil {
+		b.tokens -= cost
+	}
 	l.chatCmds[chatKey] = append(cmds, now)
 
-	return Allowed
+	return Allowed, 0
+}
+
+// Allows reports whether Check would allow the command, without charging tokens or
+// recording anything. Callers can use it to skip resolving an expensive role (e.g. group
+// admin, which needs the group's metadata) for commands that are allowed anyway.
+func (l *Limiter) Allows(userJID, chatJID string, cost float64, role Role) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	multiplier, ok := l.multipliers[role]
+	if !ok {
+		multiplier = 1
+	}
+	if multiplier <= 0 {
+		return true
+	}
+	if until, ok := l.penalties[userJID]; ok && now.Before(until) {
+		return false
+	}
+
+	cost *= multiplier
+	if cost > l.capacity {
+		cost = l.capacity
+	}
+	if l.refillEvery > 0 {
+		if b, ok := l.buckets[userJID]; ok {
+			tokens := b.tokens + float64(now.Sub(b.last))/float64(l.refillEvery)
+			if tokens < cost {
+				return false
+			}
+		}
+	}
+
+	cutoff := now.Add(-l.chatWindow)
+	recent := 0
+	for _, at := range l.chatCmds[chatJID] {
+		if !at.Before(cutoff) {
+			recent++
+		}
+	}
+	return recent < l.chatLimit
+}
+
+// recordViolation counts a rejected command for key within the penalty window.
+// Returns the number of violations in the current window.
+func (l *Limiter) recordViolation(violations map[string]*violationWindow, key string, now time.Time) int {
+	v, ok := violations[key]
+	if !ok || now.Sub(v.start) > l.penaltyWindow {
+		v = &violationWindow{start: now}
+		violations[key] = v
+	}
+	v.count++
+	return v.count
+}
+
+// refill tops up the user's bucket for the time elapsed since its last update.
+// Returns nil when per-user limiting is disabled.
+func (l *Limiter) refill(userJID string, now time.Time) *bucket {
+	if l.refillEvery <= 0 {
+		return nil
+	}
+
+	b, ok := l.buckets[userJID]
+	if !ok {
+		b = &bucket{tokens: l.capacity, last: now}
+		l.buckets[userJID] = b
+		return b
+	}
+
+	b.tokens += float64(now.Sub(b.last)) / float64(l.refillEvery)
+	if b.tokens > l.capacity {
+		b.tokens = l.capacity
+	}
+	b.last = now
+	return b
 }
 
 // cleanup removes stale entries to prevent memory leaks.
 func (l *Limiter) cleanup(now time.Time) {
-	// Clean user entries older than 1 minute.
-	for k, v := range l.userLast {
-		if now.Sub(v) > time.Minute {
-			delete(l.userLast, k)
+	// Clean user buckets that have fully refilled; they are equivalent to new ones.
+	for k, b := range l.buckets {
+		if b.tokens+float64(now.Sub(b.last))/float64(l.refillEvery) >= l.capacity {
+			delete(l.buckets, k)
+		}
+	}
+
+	// Clean expired penalties and violation windows.
+	for k, until := range l.penalties {
+		if !now.Before(until) {
+			delete(l.penalties, k)
+		}
+	}
+	for _, violations := range []map[string]*violationWindow{l.userViolations, l.chatViolations} {
+		for k, v := range violations {
+			if now.Sub(v.start) > l.penaltyWindow {
+				delete(violations, k)
+			}
 		}
 	}
 
@@ -7,77 +7,168 @@ import (
 )
 
 func TestLimiter_AllowsFirstRequest(t *testing.T) {
-	l := New(1*time.Second, 10, time.Minute)
-	result := l.Check("user1", "chat1")
+	l := New(1, 1*time.Second, 10, time.Minute)
+	result, _ := l.Check("user1", "chat1", 1, RoleMember)
 	if result != Allowed {
 		t.Errorf("First request should be Allowed, got %v", result)
 	}
 }
 
 func TestLimiter_UserCooldown(t *testing.T) {
-	l := New(100*time.Millisecond, 100, time.Minute)
+	l := New(1, 100*time.Millisecond, 100, time.Minute)
 
 	// First request: allowed
-	if r := l.Check("user1", "chat1"); r != Allowed {
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != Allowed {
 		t.Fatalf("First request should be Allowed, got %v", r)
 	}
 
 	// Immediate second request: should be rate limited
-	if r := l.Check("user1", "chat1"); r != UserCooldown {
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != UserCooldown {
 		t.Errorf("Immediate second request should be UserCooldown, got %v", r)
 	}
 
-	// Wait for cooldown to expire
+	// Wait for a token to refill
 	time.Sleep(150 * time.Millisecond)
 
 	// Should be allowed again
-	if r := l.Check("user1", "chat1"); r != Allowed {
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != Allowed {
 		t.Errorf("Request after cooldown should be Allowed, got %v", r)
 	}
 }
 
+func TestLimiter_BurstCapacity(t *testing.T) {
+	l := New(3, time.Minute, 100, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != Allowed {
+			t.Fatalf("Request %d within burst should be Allowed, got %v", i, r)
+		}
+	}
+
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != UserCooldown {
+		t.Errorf("Request exceeding burst should be UserCooldown, got %v", r)
+	}
+}
+
+func TestLimiter_CommandCost(t *testing.T) {
+	l := New(5, time.Minute, 100, time.Minute)
+
+	// A heavy command drains most of the bucket.
+	if r, _ := l.Check("user1", "chat1", 4, RoleMember); r != Allowed {
+		t.Fatalf("Heavy command should be Allowed, got %v", r)
+	}
+
+	// A second heavy command cannot be afforded...
+	if r, _ := l.Check("user1", "chat1", 4, RoleMember); r != UserCooldown {
+		t.Errorf("Second heavy command should be UserCooldown, got %v", r)
+	}
+
+	// ...but a cheap one still can.
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != Allowed {
+		t.Errorf("Cheap command should be Allowed, got %v", r)
+	}
+}
+
+func TestLimiter_CostClampedToCapacity(t *testing.T) {
+	l := New(2, time.Minute, 100, time.Minute)
+
+	// A cost larger than the bucket must still be allowed on a full bucket.
+	if r, _ := l.Check("user1", "chat1", 10, RoleMember); r != Allowed {
+		t.Errorf("Oversized cost on full bucket should be Allowed, got %v", r)
+	}
+}
+
+func TestLimiter_RetryAfter(t *testing.T) {
+	l := New(1, 10*time.Second, 100, time.Minute)
+
+	l.Check("user1", "chat1", 1, RoleMember)
+
+	r, retry := l.Check("user1", "chat1", 1, RoleMember)
+	if r != UserCooldown {
+		t.Fatalf("Should be UserCooldown, got %v", r)
+	}
+	if retry <= 9*time.Second || retry > 10*time.Second {
+		t.Errorf("RetryAfter = %v, want about 10s", retry)
+	}
+}
+
+func TestLimiter_RoleMultiplier(t *testing.T) {
+	l := New(2, time.Minute, 100, time.Minute)
+	l.SetMultiplier(RoleGroupAdmin, 0.5)
+
+	// Admins pay half, so they get twice as many commands.
+	for i := 0; i < 4; i++ {
+		if r, _ := l.Check("admin", "chat1", 1, RoleGroupAdmin); r != Allowed {
+			t.Fatalf("Admin request %d should be Allowed, got %v", i, r)
+		}
+	}
+	if r, _ := l.Check("admin", "chat1", 1, RoleGroupAdmin); r != UserCooldown {
+		t.Errorf("Admin request beyond budget should be UserCooldown, got %v", r)
+	}
+}
+
+func TestLimiter_RoleExemption(t *testing.T) {
+	l := New(1, time.Minute, 1, time.Minute)
+	l.SetMultiplier(RoleOwner, 0)
+
+	for i := 0; i < 5; i++ {
+		if r, _ := l.Check("owner", "chat1", 1, RoleOwner); r != Allowed {
+			t.Fatalf("Exempt owner request %d should be Allowed, got %v", i, r)
+		}
+	}
+
+	// Exempt commands are not counted against the chat either.
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != Allowed {
+		t.Errorf("Member after exempt owner should be Allowed, got %v", r)
+	}
+}
+
 func TestLimiter_DifferentUsersIndependent(t *testing.T) {
-	l := New(1*time.Second, 100, time.Minute)
+	l := New(1, 1*time.Second, 100, time.Minute)
 
-	if r := l.Check("user1", "chat1"); r != Allowed {
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != Allowed {
 		t.Fatalf("user1 first request should be Allowed, got %v", r)
 	}
 
 	// Different user should not be affected
-	if r := l.Check("user2", "chat1"); r != Allowed {
+	if r, _ := l.Check("user2", "chat1", 1, RoleMember); r != Allowed {
 		t.Errorf("user2 should be Allowed independently, got %v", r)
 	}
 }
 
 func TestLimiter_ChatRateLimit(t *testing.T) {
 	limit := 3
-	l := New(0, limit, time.Minute) // No per-user cooldown
+	l := New(1, 0, limit, time.Minute) // No per-user limiting
 
 	// Fill up the chat limit
 	for i := 0; i < limit; i++ {
 		user := fmt.Sprintf("user%d", i)
-		if r := l.Check(user, "chat1"); r != Allowed {
+		if r, _ := l.Check(user, "chat1", 1, RoleMember); r != Allowed {
 			t.Fatalf("Request %d should be Allowed, got %v", i, r)
 		}
 	}
 
 	// Next request should hit chat rate limit
-	if r := l.Check("userX", "chat1"); r != ChatRateLimit {
+	r, retry := l.Check("userX", "chat1", 1, RoleMember)
+	if r != ChatRateLimit {
 		t.Errorf("Request exceeding chat limit should be ChatRateLimit, got %v", r)
 	}
+	if retry <= 0 || retry > time.Minute {
+		t.Errorf("RetryAfter = %v, want within the chat window", retry)
+	}
 }
 
 func TestLimiter_DifferentChatsIndependent(t *testing.T) {
 	limit := 2
-	l := New(0, limit, time.Minute)
+	l := New(1, 0, limit, time.Minute)
 
 	// Fill chat1
 	for i := 0; i < limit; i++ {
-		l.Check(fmt.Sprintf("user%d", i), "chat1")
+		l.Check(fmt.Sprintf("user%d", i), "chat1", 1, RoleMember)
 	}
 
 	// chat2 should still be fine
-	if r := l.Check("user1", "chat2"); r != Allowed {
+	if r, _ := l.Check("user1", "chat2", 1, RoleMember); r != Allowed {
 		t.Errorf("Different chat should be Allowed, got %v", r)
 	}
 }
@@ -85,14 +176,14 @@ func TestLimiter_DifferentChatsIndependent(t *testing.T) {
 func TestLimiter_SlidingWindowExpiry(t *testing.T) {
 	limit := 2
 	window := 100 * time.Millisecond
-	l := New(0, limit, window)
+	l := New(1, 0, limit, window)
 
 	// Fill up
-	l.Check("user1", "chat1")
-	l.Check("user2", "chat1")
+	l.Check("user1", "chat1", 1, RoleMember)
+	l.Check("user2", "chat1", 1, RoleMember)
 
 	// Should be rate limited
-	if r := l.Check("user3", "chat1"); r != ChatRateLimit {
+	if r, _ := l.Check("user3", "chat1", 1, RoleMember); r != ChatRateLimit {
 		t.Errorf("Should be ChatRateLimit, got %v", r)
 	}
 
@@ -100,19 +191,19 @@ func TestLimiter_SlidingWindowExpiry(t *testing.T) {
 	time.Sleep(150 * time.Millisecond)
 
 	// Should be allowed again
-	if r := l.Check("user3", "chat1"); r != Allowed {
+	if r, _ := l.Check("user3", "chat1", 1, RoleMember); r != Allowed {
 		t.Errorf("After window expiry should be Allowed, got %v", r)
 	}
 }
 
 func TestLimiter_Cleanup(t *testing.T) {
-	l := New(1*time.Millisecond, 100, 1*time.Millisecond)
+	l := New(1, 1*time.Millisecond, 100, 1*time.Millisecond)
 
 	// Add some entries
-	l.Check("user1", "chat1")
-	l.Check("user2", "chat2")
+	l.Check("user1", "chat1", 1, RoleMember)
+	l.Check("user2", "chat2", 1, RoleMember)
 
-	// Wait for entries to become stale
+	// Wait for buckets to refill and entries to become stale
 	time.Sleep(10 * time.Millisecond)
 
 	// Force cleanup by setting lastCleanup far in the past
@@ -121,13 +212,130 @@ func TestLimiter_Cleanup(t *testing.T) {
 	l.mu.Unlock()
 
 	// Next check triggers cleanup
-	l.Check("user3", "chat3")
+	l.Check("user3", "chat3", 1, RoleMember)
 
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	// Stale user entries should be cleaned
-	if _, exists := l.userLast["user1"]; exists {
+	// Refilled user buckets should be cleaned
+	if _, exists := l.buckets["user1"]; exists {
 		t.Error("user1 should have been cleaned up")
 	}
+	if _, exists := l.chatCmds["chat1"]; exists {
+		t.Error("chat1 should have been cleaned up")
+	}
+}
+
+func TestLimiter_WarnOncePerWindow(t *testing.T) {
+	l := New(1, time.Minute, 100, time.Minute)
+
+	l.Check("user1", "chat1", 1, RoleMember)
+
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != UserCooldown {
+		t.Fatalf("First violation should be UserCooldown, got %v", r)
+	}
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != Ignored {
+		t.Errorf("Repeated violation should be Ignored, got %v", r)
+	}
+}
+
+func TestLimiter_ChatWarnOncePerWindow(t *testing.T) {
+	l := New(1, 0, 1, time.Minute)
+
+	l.Check("user1", "chat1", 1, RoleMember)
+
+	if r, _ := l.Check("user2", "chat1", 1, RoleMember); r != ChatRateLimit {
+		t.Fatalf("First chat violation should be ChatRateLimit, got %v", r)
+	}
+	if r, _ := l.Check("user3", "chat1", 1, RoleMember); r != Ignored {
+		t.Errorf("Repeated chat violation should be Ignored, got %v", r)
+	}
+}
+
+func TestLimiter_PenaltyBox(t *testing.T) {
+	l := New(1, 50*time.Millisecond, 100, time.Minute)
+	l.SetPenalty(3, time.Minute, time.Minute)
+
+	l.Check("user1", "chat1", 1, RoleMember)
+	l.Check("user1", "chat1", 1, RoleMember) // violation 1: warn
+	l.Check("user1", "chat1", 1, RoleMember) // violation 2: ignored
+
+	r, retry := l.Check("user1", "chat1", 1, RoleMember)
+	if r != PenaltyStart {
+		t.Fatalf("Third violation should be PenaltyStart, got %v", r)
+	}
+	if retry != time.Minute {
+		t.Errorf("Penalty duration = %v, want 1m", retry)
+	}
+
+	// Even with a refilled bucket, the user stays blocked.
+	time.Sleep(100 * time.Millisecond)
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != Penalized {
+		t.Errorf("Penalized user should be Penalized, got %v", r)
+	}
+
+	if p := l.Penalties(); len(p) != 1 || p[0].UserJID != "user1" {
+		t.Errorf("Penalties() = %+v, want user1", p)
+	}
+
+	if !l.ClearPenalty("user1") {
+		t.Fatal("ClearPenalty should report the user was penalized")
+	}
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != Allowed {
+		t.Errorf("Cleared user should be Allowed, got %v", r)
+	}
+}
+
+func TestLimiter_PenaltyExpiry(t *testing.T) {
+	l := New(1, time.Minute, 100, time.Minute)
+	l.SetPenalty(1, time.Minute, 50*time.Millisecond)
+
+	l.Check("user1", "chat1", 1, RoleMember)
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != PenaltyStart {
+		t.Fatalf("Violation at threshold 1 should be PenaltyStart, got %v", r)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Penalty expired; the user is back to normal limiting.
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r == Penalized {
+		t.Errorf("Expired penalty should not block, got %v", r)
+	}
+}
+
+func TestLimiter_AllowsDoesNotCharge(t *testing.T) {
+	l := New(1, time.Minute, 100, time.Minute)
+	l.SetMultiplier(RoleGroupAdmin, 0.5)
+
+	for i := 0; i < 3; i++ {
+		if !l.Allows("user1", "chat1", 1, RoleMember) {
+			t.Fatalf("Allows #%d should be true for a full bucket", i)
+		}
+	}
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != Allowed {
+		t.Fatalf("Check after Allows should be Allowed, got %v", r)
+	}
+
+	if l.Allows("user1", "chat1", 1, RoleMember) {
+		t.Error("Allows should be false for an empty bucket")
+	}
+	if l.Allows("user1", "chat1", 1, RoleGroupAdmin) {
+		t.Error("Allows should be false for an admin with an empty bucket")
+	}
+	if r, _ := l.Check("user1", "chat1", 1, RoleMember); r != UserCooldown {
+		t.Errorf("Allows must not record violations; Check = %v, want UserCooldown", r)
+	}
+}
+
+func TestLimiter_AllowsChatLimit(t *testing.T) {
+	l := New(10, time.Second, 2, time.Minute)
+	l.Check("user1", "chat1", 1, RoleMember)
+	l.Check("user2", "chat1", 1, RoleMember)
+
+	if l.Allows("user3", "chat1", 1, RoleMember) {
+		t.Error("Allows should be false when the chat limit is reached")
+	}
+	if !l.Allows("user3", "chat2", 1, RoleMember) {
+		t.Error("Allows should be true in another chat")
+	}
 }
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// slowModeEntry is a user's last allowed message in one chat.
+type slowModeEntry struct {
+	last     time.Time
+	interval time.Duration
+	notified bool // a violation in the current window was already reported
+}
+
+// SlowMode allows each user one message per interval in a chat.
+// It is a sliding window of size one, so only the time of the last allowed message
+// is kept per user and chat.
+type SlowMode struct {
+	mu      sync.Mutex
+	entries map[string]*slowModeEntry // "chat|user" -> last allowed message
+
+	lastCleanup time.Time
+}
+
+// NewSlowMode creates a new SlowMode.
+func NewSlowMode() *SlowMode {
+	return &SlowMode{
+		entries:     make(map[string]*slowModeEntry),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Check records a message from userJID in chatJID. It returns Allowed when interval has
+// passed since the user's last allowed message, UserCooldown for the first message within
+// the interval and Ignored for any further ones, together with the time left until the
+// user may post again. Denied messages do not restart the interval. An interval of 0 allows everything.
+func (s *SlowMode) Check(userJID, chatJID string, interval time.Duration) (Result, time.Duration) {
+	if interval <= 0 {
+		return Allowed, 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	// Periodic cleanup every 5 minutes to free memory.
+	if now.Sub(s.lastCleanup) > 5*time.Minute {
+		s.cleanup(now)
+		s.lastCleanup = now
+	}
+
+	key := chatJID + "|" + userJID
+	e, ok := s.entries[key]
+	if !ok || now.Sub(e.last) >= interval {
+		s.entries[key] = &slowModeEntry{last: now, interval: interval}
+		return Allowed, 0
+	}
+
+	e.interval = interval
+	wait := e.last.Add(interval).Sub(now)
+	if e.notified {
+		return Ignored, wait
+	}
+	e.notified = true
+	return UserCooldown, wait
+}
+
+// cleanup removes entries whose interval has passed to prevent memory leaks.
+func (s *SlowMode) cleanup(now time.Time) {
+	for k, e := range s.entries {
+		if now.Sub(e.last) >= e.interval {
+			delete(s.entries, k)
+		}
+	}
+}
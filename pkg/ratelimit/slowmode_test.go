@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlowMode_OneMessagePerInterval(t *testing.T) {
+	s := NewSlowMode()
+	interval := 100 * time.Millisecond
+
+	if r, _ := s.Check("user1", "chat1", interval); r != Allowed {
+		t.Fatalf("first message should be Allowed, got %v", r)
+	}
+
+	r, wait := s.Check("user1", "chat1", interval)
+	if r != UserCooldown {
+		t.Errorf("second message should be UserCooldown, got %v", r)
+	}
+	if wait <= 0 || wait > interval {
+		t.Errorf("wait = %v, want within (0, %v]", wait, interval)
+	}
+
+	// Only the first violation in a window is reported.
+	if r, _ := s.Check("user1", "chat1", interval); r != Ignored {
+		t.Errorf("third message should be Ignored, got %v", r)
+	}
+
+	// Other users and chats are independent.
+	if r, _ := s.Check("user2", "chat1", interval); r != Allowed {
+		t.Errorf("other user should be Allowed, got %v", r)
+	}
+	if r, _ := s.Check("user1", "chat2", interval); r != Allowed {
+		t.Errorf("other chat should be Allowed, got %v", r)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	if r, _ := s.Check("user1", "chat1", interval); r != Allowed {
+		t.Errorf("message after the interval should be Allowed, got %v", r)
+	}
+	if r, _ := s.Check("user1", "chat1", interval); r != UserCooldown {
+		t.Errorf("violation in a new window should be reported again, got %v", r)
+	}
+}
+
+func TestSlowMode_Disabled(t *testing.T) {
+	s := NewSlowMode()
+	for i := 0; i < 3; i++ {
+		if r, _ := s.Check("user1", "chat1", 0); r != Allowed {
+			t.Fatalf("message %d without interval should be Allowed, got %v", i, r)
+		}
+	}
+}
+
+func TestSlowMode_Cleanup(t *testing.T) {
+	s := NewSlowMode()
+	s.Check("user1", "chat1", time.Millisecond)
+	s.Check("user2", "chat1", time.Hour)
+
+	s.cleanup(time.Now().Add(time.Second))
+
+	if _, ok := s.entries["chat1|user1"]; ok {
+		t.Error("expired entry should be removed")
+	}
+	if _, ok := s.entries["chat1|user2"]; !ok {
+		t.Error("entry within its interval should be kept")
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationUnits maps the suffixes accepted by ParseDuration to their length.
+var durationUnits = map[byte]time.Duration{
+	's': time.Second,
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// ParseDuration parses short user-facing durations such as "30m", "2h", "1d" or "1w".
+// Returns false if the text is not a positive duration in that form.
+func ParseDuration(text string) (time.Duration, bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if len(text) < 2 {
+		return 0, false
+	}
+
+	unit, ok := durationUnits[text[len(text)-1]]
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(text[:len(text)-1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(n) * unit, true
+}
+
+// FormatDuration renders a duration in Indonesian using its largest whole unit, e.g. "2 jam".
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d >= 7*24*time.Hour && d%(7*24*time.Hour) == 0:
+		return fmt.Sprintf("%d minggu", d/(7*24*time.Hour))
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%d hari", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%d jam", d/time.Hour)
+	case d >= time.Minute:
+		return fmt.Sprintf("%d menit", d/time.Minute)
+	default:
+		return fmt.Sprintf("%d detik", d/time.Second)
+	}
+}
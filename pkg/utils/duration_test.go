@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	valid := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"30s", 30 * time.Second},
+		{"30m", 30 * time.Minute},
+		{"2h", 2 * time.Hour},
+		{"1d", 24 * time.Hour},
+		{"1w", 7 * 24 * time.Hour},
+		{"2H", 2 * time.Hour},
+	}
+	for _, tt := range valid {
+		got, ok := ParseDuration(tt.input)
+		if !ok || got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v, true", tt.input, got, ok, tt.want)
+		}
+	}
+
+	invalid := []string{"", "h", "0h", "-1h", "2x", "abc", "@628123", "1.5h"}
+	for _, input := range invalid {
+		if _, ok := ParseDuration(input); ok {
+			t.Errorf("ParseDuration(%q) should fail", input)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		input time.Duration
+		want  string
+	}{
+		{45 * time.Second, "45 detik"},
+		{30 * time.Minute, "30 menit"},
+		{90 * time.Minute, "90 menit"},
+		{2 * time.Hour, "2 jam"},
+		{24 * time.Hour, "1 hari"},
+		{14 * 24 * time.Hour, "2 minggu"},
+	}
+	for _, tt := range tests {
+		if got := FormatDuration(tt.input); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
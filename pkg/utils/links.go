@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// linkPattern matches URLs with a scheme, www-prefixed hosts and bare domains (example.com/path).
+var linkPattern = regexp.MustCompile(`(?i)(?:https?://)?(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,24}(?::\d+)?(?:/[^\s]*)?`)
+
+// bareLinkTLDs are the top-level domains accepted for links without a scheme or www prefix.
+// This keeps file names and abbreviations such as "file.txt" or "a.m" from counting as links.
+var bareLinkTLDs = map[string]bool{
+	"com": true, "net": true, "org": true, "id": true, "io": true, "co": true, "me": true,
+	"ly": true, "gg": true, "xyz": true, "link": true, "site": true, "online": true, "app": true,
+	"dev": true, "info": true, "biz": true, "top": true, "club": true, "shop": true, "store": true,
+	"live": true, "tv": true, "us": true, "uk": true, "my": true, "sg": true, "to": true,
+	"cc": true, "ru": true, "vip": true, "win": true, "bet": true, "tk": true, "ml": true,
+}
+
+// WhatsAppInviteHost is the host of WhatsApp group invite links.
+const WhatsAppInviteHost = "chat.whatsapp.com"
+
+// ExtractLinkHosts returns the lowercased host of every link found in text, in order of appearance.
+func ExtractLinkHosts(text string) []string {
+	var hosts []string
+	for _, match := range linkPattern.FindAllString(text, -1) {
+		lower := strings.ToLower(match)
+		hasScheme := strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+
+		host := lower
+		if i := strings.Index(host, "://"); i >= 0 {
+			host = host[i+3:]
+		}
+		if i := strings.IndexAny(host, "/:"); i >= 0 {
+			host = host[:i]
+		}
+
+		if !hasScheme && !strings.HasPrefix(host, "www.") {
+			tld := host[strings.LastIndex(host, ".")+1:]
+			if !bareLinkTLDs[tld] {
+				continue
+			}
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// HostMatchesDomain reports whether host is domain or one of its subdomains.
+func HostMatchesDomain(host, domain string) bool {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	domain = strings.TrimPrefix(strings.ToLower(domain), "www.")
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// IsWhatsAppInviteHost reports whether host serves WhatsApp group invite links.
+func IsWhatsAppInviteHost(host string) bool {
+	return HostMatchesDomain(host, WhatsAppInviteHost)
+}
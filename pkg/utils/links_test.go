@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractLinkHosts(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"plain text", "halo semua, apa kabar?", nil},
+		{"https url", "cek https://Example.com/path?q=1", []string{"example.com"}},
+		{"http url with port", "http://10.0.0.1:8080/x", nil},
+		{"www prefix", "kunjungi www.situs.web sekarang", []string{"www.situs.web"}},
+		{"bare domain", "promo di slotgacor.xyz/daftar", []string{"slotgacor.xyz"}},
+		{"invite link", "join chat.whatsapp.com/AbCdEf123", []string{"chat.whatsapp.com"}},
+		{"short link", "bit.ly/abc dan wa.me/628123", []string{"bit.ly", "wa.me"}},
+		{"file name ignored", "kirim laporan.pdf dan foto.jpg", nil},
+		{"multiple", "https://a.com dan https://b.org", []string{"a.com", "b.org"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractLinkHosts(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractLinkHosts(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostMatchesDomain(t *testing.T) {
+	tests := []struct {
+		host, domain string
+		want         bool
+	}{
+		{"youtube.com", "youtube.com", true},
+		{"www.youtube.com", "youtube.com", true},
+		{"m.youtube.com", "youtube.com", true},
+		{"notyoutube.com", "youtube.com", false},
+		{"youtube.com.evil.xyz", "youtube.com", false},
+		{"chat.whatsapp.com", "whatsapp.com", true},
+	}
+	for _, tt := range tests {
+		if got := HostMatchesDomain(tt.host, tt.domain); got != tt.want {
+			t.Errorf("HostMatchesDomain(%q, %q) = %v, want %v", tt.host, tt.domain, got, tt.want)
+		}
+	}
+
+	if !IsWhatsAppInviteHost("chat.whatsapp.com") || IsWhatsAppInviteHost("whatsapp.com") {
+		t.Error("IsWhatsAppInviteHost should only match chat.whatsapp.com")
+	}
+}
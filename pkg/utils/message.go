@@ -280,6 +280,38 @@ func GetQuotedMessage(evt *events.Message) *waProto.Message {
 	return nil
 }
 
+// GetContextInfo returns the ContextInfo of whichever message type msg carries, or nil.
+// View Once and document-with-caption wrappers are unwrapped first.
+func GetContextInfo(msg *waProto.Message) *waProto.ContextInfo {
+	msg = UnwrapViewOnce(msg)
+	if wrapped := msg.GetDocumentWithCaptionMessage().GetMessage(); wrapped != nil {
+		msg = wrapped
+	}
+
+	type withContextInfo interface {
+		GetContextInfo() *waProto.ContextInfo
+	}
+	for _, m := range []withContextInfo{
+		msg.GetExtendedTextMessage(),
+		msg.GetImageMessage(),
+		msg.GetVideoMessage(),
+		msg.GetStickerMessage(),
+		msg.GetDocumentMessage(),
+		msg.GetAudioMessage(),
+		msg.GetContactMessage(),
+		msg.GetContactsArrayMessage(),
+		msg.GetLocationMessage(),
+		msg.GetLiveLocationMessage(),
+		msg.GetPollCreationMessage(),
+		msg.GetPollCreationMessageV3(),
+	} {
+		if ctxInfo := m.GetContextInfo(); ctxInfo != nil {
+			return ctxInfo
+		}
+	}
+	return nil
+}
+
 // IsMediaMessage checks if the message contains any media.
 func IsMediaMessage(msg *waProto.Message) bool {
 	// Unwrap all View Once variants
@@ -295,6 +327,16 @@ func IsMediaMessage(msg *waProto.Message) bool {
 // GetTargetJID tries to extract a target JID from message mentions or quotes.
 // Checks all message types that can carry ContextInfo (text, image, video, sticker, document).
 func GetTargetJID(evt *events.Message) (types.JID, bool) {
+	targets := GetTargetJIDs(evt)
+	if len(targets) == 0 {
+		return types.JID{}, false
+	}
+	return targets[0], true
+}
+
+// GetTargetJIDs extracts every mentioned JID, or the quoted message's sender if nothing is mentioned.
+// Duplicate mentions are returned once.
+func GetTargetJIDs(evt *events.Message) []types.JID {
 	// Collect all possible ContextInfo sources.
 	var ctxInfos []*waProto.ContextInfo
 
@@ -320,15 +362,24 @@ func GetTargetJID(evt *events.Message) (types.JID, bool) {
 		}
 		// 1. Check mention list first.
 		if mentionList := ctxInfo.GetMentionedJID(); len(mentionList) > 0 {
-			targetJID, _ := types.ParseJID(mentionList[0])
-			return targetJID, true
+			var targets []types.JID
+			seen := make(map[string]bool)
+			for _, mention := range mentionList {
+				targetJID, err := types.ParseJID(mention)
+				if err != nil || seen[targetJID.User] {
+					continue
+				}
+				seen[targetJID.User] = true
+				targets = append(targets, targetJID)
+			}
+			return targets
 		}
 		// 2. Fallback to quoted message participant.
 		if ctxInfo.Participant != nil {
 			targetJID, _ := types.ParseJID(*ctxInfo.Participant)
-			return targetJID, true
+			return []types.JID{targetJID}
 		}
 	}
 
-	return types.JID{}, false
+	return nil
 }
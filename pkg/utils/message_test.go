@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"testing"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetTargetJIDs(t *testing.T) {
+	textWith := func(ctx *waProto.ContextInfo) *events.Message {
+		return &events.Message{Message: &waProto.Message{
+			ExtendedTextMessage: &waProto.ExtendedTextMessage{Text: proto.String(".promote"), ContextInfo: ctx},
+		}}
+	}
+
+	tests := []struct {
+		name string
+		evt  *events.Message
+		want []string
+	}{
+		{"mentions deduplicated", textWith(&waProto.ContextInfo{
+			MentionedJID: []string{"111@s.whatsapp.net", "222@s.whatsapp.net", "111@s.whatsapp.net"},
+			Participant:  proto.String("333@s.whatsapp.net"),
+		}), []string{"111", "222"}},
+		{"quoted sender", textWith(&waProto.ContextInfo{Participant: proto.String("333@s.whatsapp.net")}), []string{"333"}},
+		{"no target", textWith(nil), nil},
+		{"plain text", &events.Message{Message: &waProto.Message{Conversation: proto.String("hi")}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetTargetJIDs(tt.evt)
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetTargetJIDs() = %v, want users %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].User != tt.want[i] {
+					t.Errorf("target %d = %s, want %s", i, got[i].User, tt.want[i])
+				}
+			}
+			if first, ok := GetTargetJID(tt.evt); ok != (len(tt.want) > 0) || (ok && first.User != tt.want[0]) {
+				t.Errorf("GetTargetJID() = %v, %v; want the first target", first, ok)
+			}
+		})
+	}
+}
+
+func TestGetContextInfo(t *testing.T) {
+	ctx := &waProto.ContextInfo{IsForwarded: proto.Bool(true)}
+
+	tests := []struct {
+		name string
+		msg  *waProto.Message
+		want *waProto.ContextInfo
+	}{
+		{"extended text", &waProto.Message{ExtendedTextMessage: &waProto.ExtendedTextMessage{ContextInfo: ctx}}, ctx},
+		{"audio", &waProto.Message{AudioMessage: &waProto.AudioMessage{ContextInfo: ctx}}, ctx},
+		{"view once image", &waProto.Message{ViewOnceMessageV2: &waProto.FutureProofMessage{
+			Message: &waProto.Message{ImageMessage: &waProto.ImageMessage{ContextInfo: ctx}},
+		}}, ctx},
+		{"document with caption", &waProto.Message{DocumentWithCaptionMessage: &waProto.FutureProofMessage{
+			Message: &waProto.Message{DocumentMessage: &waProto.DocumentMessage{ContextInfo: ctx}},
+		}}, ctx},
+		{"plain text", &waProto.Message{Conversation: proto.String("hi")}, nil},
+	}
+	for _, tt := range tests {
+		if got := GetContextInfo(tt.msg); got != tt.want {
+			t.Errorf("%s: GetContextInfo() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
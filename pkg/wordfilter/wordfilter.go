@@ -0,0 +1,177 @@
+// Package wordfilter matches messages against blocked words, phrases and regular expressions.
+package wordfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// leetspeak maps look-alike characters to the letters they stand for.
+var leetspeak = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b', '9': 'g',
+	'@': 'a', '$': 's', '+': 't',
+}
+
+// leetspeakInner maps characters that are only read as letters between two letters,
+// since they usually end a word as punctuation ("gacor!") and would glue onto it.
+var leetspeakInner = map[rune]rune{'!': 'i', '|': 'i'}
+
+// minCollapseRun is the shortest run of a repeated letter that is collapsed to one letter.
+// Doubled letters are kept, so real words such as "good" stay apart from "god".
+const minCollapseRun = 3
+
+// Normalize lowercases text, undoes leetspeak, collapses letters repeated at least
+// minCollapseRun times and turns everything that is not a letter into single spaces.
+// "SL0TTT  g@c0r!!" becomes "slot gacor".
+func Normalize(text string) string {
+	runes := []rune(strings.ToLower(text))
+	mapped := make([]rune, len(runes))
+	for i, r := range runes {
+		if m, ok := leetspeak[r]; ok {
+			r = m
+		}
+		mapped[i] = r
+	}
+	for i, r := range runes {
+		if m, ok := leetspeakInner[r]; ok && i > 0 && i < len(runes)-1 &&
+			unicode.IsLetter(mapped[i-1]) && unicode.IsLetter(mapped[i+1]) {
+			mapped[i] = m
+		}
+	}
+
+	var sb strings.Builder
+	space := true // suppress leading spaces
+	for i := 0; i < len(mapped); {
+		r := mapped[i]
+		if !unicode.IsLetter(r) {
+			if !space {
+				sb.WriteRune(' ')
+				space = true
+			}
+			i++
+			continue
+		}
+
+		run := 1
+		for i+run < len(mapped) && mapped[i+run] == r {
+			run++
+		}
+		keep := run
+		if run >= minCollapseRun {
+			keep = 1
+		}
+		for j := 0; j < keep; j++ {
+			sb.WriteRune(r)
+		}
+		i += run
+		space = false
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// Rule is a single filter entry: a plain word/phrase or a regular expression.
+type Rule struct {
+	Pattern string
+	Regex   bool
+}
+
+// String returns the rule as users write it; regular expressions are wrapped in slashes.
+func (r Rule) String() string {
+	if r.Regex {
+		return "/" + r.Pattern + "/"
+	}
+	return r.Pattern
+}
+
+// ParseRule parses user input: "/expr/" is a regular expression, anything else a plain phrase.
+func ParseRule(input string) (Rule, error) {
+	input = strings.TrimSpace(input)
+	if len(input) > 2 && strings.HasPrefix(input, "/") && strings.HasSuffix(input, "/") {
+		rule := Rule{Pattern: input[1 : len(input)-1], Regex: true}
+		if _, err := compile(rule.Pattern); err != nil {
+			return Rule{}, err
+		}
+		return rule, nil
+	}
+
+	if Normalize(input) == "" {
+		return Rule{}, fmt.Errorf("phrase %q contains no letters", input)
+	}
+	return Rule{Pattern: strings.ToLower(input)}, nil
+}
+
+// Matcher tests messages against a set of rules.
+type Matcher struct {
+	phrases []phrase
+	regexes []compiledRegex
+}
+
+type phrase struct {
+	rule       Rule
+	normalized string // padded with spaces so matches stay on word boundaries
+}
+
+type compiledRegex struct {
+	rule Rule
+	re   *regexp.Regexp
+}
+
+// New builds a matcher for rules. Invalid regular expressions are skipped and reported.
+func New(rules []Rule) (*Matcher, error) {
+	m := &Matcher{}
+	var errs []string
+	for _, rule := range rules {
+		if rule.Regex {
+			re, err := compile(rule.Pattern)
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			m.regexes = append(m.regexes, compiledRegex{rule: rule, re: re})
+			continue
+		}
+		if normalized := Normalize(rule.Pattern); normalized != "" {
+			m.phrases = append(m.phrases, phrase{rule: rule, normalized: " " + normalized + " "})
+		}
+	}
+	if len(errs) > 0 {
+		return m, fmt.Errorf("invalid filter rules: %s", strings.Join(errs, "; "))
+	}
+	return m, nil
+}
+
+// Empty reports whether the matcher has no rules.
+func (m *Matcher) Empty() bool {
+	return m == nil || (len(m.phrases) == 0 && len(m.regexes) == 0)
+}
+
+// Match returns the first rule that text violates.
+// Plain phrases are matched on whole words of the normalized text; regular expressions
+// are matched case-insensitively against the original text.
+func (m *Matcher) Match(text string) (Rule, bool) {
+	if m.Empty() || text == "" {
+		return Rule{}, false
+	}
+
+	if len(m.phrases) > 0 {
+		normalized := " " + Normalize(text) + " "
+		for _, p := range m.phrases {
+			if strings.Contains(normalized, p.normalized) {
+				return p.rule, true
+			}
+		}
+	}
+
+	for _, r := range m.regexes {
+		if r.re.MatchString(text) {
+			return r.rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
@@ -0,0 +1,93 @@
+package wordfilter
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Slot Gacor", "slot gacor"},
+		{"SL0TTT  g@c0r!!", "slot gacor"},
+		{"slot gacor!", "slot gacor"},
+		{"sl|t w!n", "slit win"},
+		{"|slot!", "slot"},
+		{"good food", "good food"},
+		{"gooood", "god"},
+		{"s.l.o.t", "s l o t"},
+		{"  haaaaloooo  ", "halo"},
+		{"j4ckp0t", "jackpot"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	if rule, err := ParseRule("/sl[o0]t\\s*gacor/"); err != nil || !rule.Regex || rule.Pattern != "sl[o0]t\\s*gacor" {
+		t.Errorf("ParseRule(regex) = %+v, %v", rule, err)
+	}
+	if _, err := ParseRule("/sl[ot/"); err == nil {
+		t.Error("ParseRule should reject invalid regular expressions")
+	}
+	if rule, err := ParseRule("Slot Gacor"); err != nil || rule.Regex || rule.Pattern != "slot gacor" {
+		t.Errorf("ParseRule(phrase) = %+v, %v", rule, err)
+	}
+	if _, err := ParseRule("22-66 ??"); err == nil {
+		t.Error("ParseRule should reject phrases without letters")
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	m, err := New([]Rule{
+		{Pattern: "slot gacor"},
+		{Pattern: "judi"},
+		{Pattern: `bit\.ly/\w+`, Regex: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"ayo main SLOT GACOR hari ini", true},
+		{"ayo main sl0ttt g@cor hari ini", true},
+		{"slot gacor!", true},
+		{"SL0TTT g@c0r!!", true},
+		{"JUUUDI online", true},
+		{"j.u.d.i", false},
+		{"slot kosong, gacor nanti", false},
+		{"kata judicial bukan judi-an", true},
+		{"kata judicial saja", false},
+		{"klik BIT.LY/abc", true},
+		{"pesan biasa", false},
+	}
+	for _, tt := range tests {
+		if _, got := m.Match(tt.text); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+
+	// Doubled letters are kept, so a rule does not match a different real word.
+	god, err := New([]Rule{{Pattern: "god"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, got := god.Match("good morning"); got {
+		t.Error(`rule "god" should not match "good morning"`)
+	}
+	if _, got := god.Match("oh gooood"); !got {
+		t.Error(`rule "god" should match "oh gooood"`)
+	}
+
+	if _, err := New([]Rule{{Pattern: "(", Regex: true}, {Pattern: "judi"}}); err == nil {
+		t.Error("New should report invalid regular expressions")
+	}
+	if !(*Matcher)(nil).Empty() {
+		t.Error("nil matcher should be empty")
+	}
+}